@@ -2,17 +2,36 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log-guardian/internal/adapters/ai/gemini"
+	"log-guardian/internal/adapters/ai/heuristic"
+	"log-guardian/internal/adapters/ai/ollama"
+	"log-guardian/internal/adapters/ai/openai"
+	"log-guardian/internal/adapters/enrichment/host"
 	"log-guardian/internal/adapters/infra"
 	"log-guardian/internal/adapters/input/file"
 	"log-guardian/internal/adapters/input/stdin"
 	"log-guardian/internal/adapters/input/unix"
+	"log-guardian/internal/adapters/notify/discord"
+	"log-guardian/internal/adapters/notify/slack"
+	"log-guardian/internal/adapters/output"
 	"log-guardian/internal/core/application"
 	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/alerting"
+	"log-guardian/internal/core/services/analysis"
+	"log-guardian/internal/core/services/incident"
+	"log-guardian/internal/core/services/pipeline"
 	"os"
+	"path/filepath"
+	"slices"
 	"time"
 )
 
+// version is replaced at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 var config *domain.RuntimeConfig
 
 func init() {
@@ -26,13 +45,53 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "invalidate-analysis":
+			invalidateAnalysis(os.Args[2:])
+			return
+		case "ack-incident":
+			acknowledgeIncident(os.Args[2:])
+			return
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	stdinIngest, unixIngest, fileIngest := createIngestions()
 
-	orchestrator := application.NewOrchestrator(ctx, config, stdinIngest, fileIngest, unixIngest)
+	analyzer := createAnalyzer()
+	cache := createAnalysisCache(ctx)
+
+	opts := append(createEnrichers(), createPipelines(analyzer, cache)...)
+	if config.Priority.Enabled {
+		dispatcher := pipeline.NewPriorityDispatcher(config.Priority, infra.NewSystemClock())
+		opts = append(opts, application.WithDispatcher(dispatcher))
+	}
+
+	if len(config.Alerting.Rules) > 0 {
+		engine, err := alerting.NewEngine(config.Alerting, infra.NewSystemClock())
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, application.WithAlerter(engine))
+	}
+
+	if config.Incidents.Enabled {
+		opts = append(opts, createIncidents())
+		if config.Incidents.Analyze {
+			opts = append(opts, application.WithIncidentAnalyzer(analyzer))
+		}
+	}
+
+	opts = append(opts, createNotifiers()...)
+
+	orchestrator := application.NewOrchestrator(ctx, config, stdinIngest, fileIngest, unixIngest, opts...)
 	orchestrator.Execute()
+
+	closeAnalysisCache(cache)
+	printAIUsage(analyzer)
 }
 
 func createIngestions() (*stdin.StdinIngestion, *unix.UnixIngestion, *file.LogFileIngestion) {
@@ -62,3 +121,242 @@ func createIngestions() (*stdin.StdinIngestion, *unix.UnixIngestion, *file.LogFi
 
 	return stdinIngest, unixIngest, fileIngest
 }
+
+func createEnrichers() []application.Option {
+	var (
+		system = host.OSSystem{}
+		opts   []application.Option
+	)
+
+	if enrich := config.Ingests.Stdin.Enrich; enrich.Host {
+		enricher := host.NewHostEnricher(system, version, false, nil)
+		opts = append(opts, application.WithEnricher(domain.SOURCE_STDIN, enricher))
+	}
+
+	if enrich := config.Ingests.File.Enrich; enrich.Host {
+		// the path is compared with the links of /proc/<pid>/fd, which are absolute
+		path, err := filepath.Abs(config.Ingests.File.Folders[0].FolderPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		resolver := host.FileWriterResolver(system, path, time.Minute)
+		enricher := host.NewHostEnricher(system, version, enrich.ContainerID, resolver)
+		opts = append(opts, application.WithEnricher(domain.SOURCE_FILE, enricher))
+	}
+
+	if enrich := config.Ingests.Unix.Enrich; enrich.Host {
+		enricher := host.NewHostEnricher(system, version, enrich.ContainerID, nil)
+		opts = append(opts, application.WithEnricher(domain.SOURCE_UNIX, enricher))
+	}
+
+	return opts
+}
+
+func createPipelines(analyzer ports.AIAnalyzer, cache ports.AnalysisCache) []application.Option {
+	if len(config.Pipelines) == 0 {
+		return nil
+	}
+
+	clock := infra.NewSystemClock()
+	idGen := infra.NewUUIDGenerator()
+	pipelines := make([]ports.Stage, 0, len(config.Pipelines))
+
+	var opts []application.Option
+	for _, pipelineConfig := range config.Pipelines {
+		p, err := pipeline.Build(pipelineConfig, clock, idGen, analyzer, cache)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		pipelines = append(pipelines, p)
+
+		for _, outputConfig := range pipelineConfig.Outputs {
+			out, err := output.NewFileOutput(outputConfig.Path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			opts = append(opts, application.WithOutput(pipelineConfig.Name, out))
+		}
+	}
+
+	router, err := pipeline.NewRouter(config.Routing, pipelines...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return append(opts, application.WithPipeline(router))
+}
+
+// createAnalyzer returns the analyzer falling back through the AI providers in order, and to the
+// heuristic provider last when it isn't configured
+func createAnalyzer() *analysis.Fallback {
+	aiConfig := config.AI
+	if !slices.ContainsFunc(aiConfig.Providers, func(p domain.AIProviderConfig) bool { return p.Type == domain.AI_PROVIDER_HEURISTIC }) {
+		aiConfig.Providers = append(slices.Clone(aiConfig.Providers), domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC})
+	}
+
+	analyzers := make([]ports.AIAnalyzer, 0, len(aiConfig.Providers))
+	for _, provider := range aiConfig.Providers {
+		analyzer, err := newAnalyzer(provider)
+		if err != nil {
+			log.Fatal(err)
+		}
+		analyzers = append(analyzers, analyzer)
+	}
+
+	fallback, err := analysis.NewFallback(aiConfig, infra.NewSystemClock(), analyzers...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return fallback
+}
+
+func newAnalyzer(provider domain.AIProviderConfig) (ports.AIAnalyzer, error) {
+	clock := infra.NewSystemClock()
+
+	switch provider.Type {
+	case domain.AI_PROVIDER_OLLAMA:
+		return ollama.NewAnalyzer(provider, clock)
+	case domain.AI_PROVIDER_GEMINI:
+		return gemini.NewAnalyzer(provider, clock)
+	case domain.AI_PROVIDER_HEURISTIC:
+		return heuristic.NewAnalyzer(provider, clock)
+	}
+
+	return openai.NewAnalyzer(provider, clock)
+}
+
+// printAIUsage prints the tokens and the estimated cost of the analyses of each pipeline
+func printAIUsage(analyzer *analysis.Fallback) {
+	for _, usage := range analyzer.Usage() {
+		fmt.Printf("ai %s %s: %d requests, %d prompt tokens, %d completion tokens, cost %.4f\n",
+			usage.Pipeline, usage.Provider, usage.Requests, usage.Tokens.Prompt, usage.Tokens.Completion, usage.Cost)
+	}
+}
+
+// createAnalysisCache returns the cache shared by the analyze stages, saved in the background until
+// the context is done, none when disabled
+func createAnalysisCache(ctx context.Context) ports.AnalysisCache {
+	if !config.AI.Cache.Enabled {
+		return nil
+	}
+
+	cache, err := newAnalysisCache()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cache.Run(ctx)
+
+	return cache
+}
+
+// closeAnalysisCache saves the analyses cached since the last background save
+func closeAnalysisCache(cache ports.AnalysisCache) {
+	if cache, ok := cache.(*analysis.Cache); ok {
+		if err := cache.Flush(); err != nil {
+			log.Printf("saving the analysis cache: %v", err)
+		}
+	}
+}
+
+func newAnalysisCache() (*analysis.Cache, error) {
+	var store ports.AnalysisStore
+	if config.AI.Cache.Path != "" {
+		store = infra.NewFileAnalysisStore(config.AI.Cache.Path)
+	}
+
+	return analysis.NewCache(config.AI.Cache, infra.NewSystemClock(), store)
+}
+
+// invalidateAnalysis removes the cached analyses of a fingerprint or a key, or all of them, so the
+// next occurrence of the event is analyzed again. A running process drops them on its next save
+func invalidateAnalysis(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("usage: %s invalidate-analysis <fingerprint|key|%s>", os.Args[0], analysis.InvalidateAll)
+	}
+
+	if config.AI.Cache.Path == "" {
+		log.Fatal("the analysis cache isn't persisted, set ai.cache.path")
+	}
+
+	cache, err := newAnalysisCache()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	removed, err := cache.Invalidate(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("invalidated %d cached analyses\n", removed)
+}
+
+func createNotifiers() []application.Option {
+	opts := make([]application.Option, 0, len(config.Notify.Notifiers))
+
+	for _, notifierConfig := range config.Notify.Notifiers {
+		notifier, err := newNotifier(notifierConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		opts = append(opts, application.WithNotifier(notifier))
+	}
+
+	return opts
+}
+
+func newNotifier(notifier domain.NotifierConfig) (ports.Notifier, error) {
+	switch notifier.Type {
+	case domain.NOTIFIER_DISCORD:
+		return discord.NewNotifier(notifier)
+	}
+
+	return slack.NewNotifier(notifier)
+}
+
+func createIncidents() application.Option {
+	manager, err := newIncidentManager()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return application.WithIncidents(manager)
+}
+
+func newIncidentManager() (*incident.Manager, error) {
+	return incident.NewManager(
+		config.Incidents,
+		infra.NewSystemClock(),
+		infra.NewUUIDGenerator(),
+		infra.NewFileIncidentStore(config.Incidents.StatePath),
+	)
+}
+
+// acknowledgeIncident marks the incident as handled by someone. A running process picks up the
+// acknowledgement on its next tick
+func acknowledgeIncident(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("usage: %s ack-incident <id>", os.Args[0])
+	}
+
+	manager, err := newIncidentManager()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := manager.Acknowledge(args[0]); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := manager.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("acknowledged incident %s\n", args[0])
+}
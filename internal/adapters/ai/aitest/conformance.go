@@ -0,0 +1,140 @@
+// Package aitest holds the conformance tests every AI provider adapter passes against a fake server
+package aitest
+
+import (
+	"context"
+	"io"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Answer is a valid analysis as answered by a model
+const Answer = `{"summary":"payments cannot reach the database","probable_cause":"postgres refuses connections",` +
+	`"suggested_fix":"check the postgres pod","confidence":0.75}`
+
+// Provider creates the analyzer of a provider pointed at the fake server, without retries, and writes
+// the successful answers in the format of the provider
+type Provider struct {
+	New     func(baseURL string, clock domain.Clock) (ports.AIAnalyzer, error)
+	Respond func(w http.ResponseWriter, content string, usage domain.TokenUsage)
+}
+
+// FixedClock always tells the same time
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+var request = domain.AnalysisRequest{
+	Event: domain.LogEvent{
+		Source:   domain.SOURCE_FILE,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Message:  "dial tcp 10.0.0.5:5432: connect: connection refused",
+	},
+	Context: []domain.LogEvent{
+		{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_WARNING, Message: "retrying database connection"},
+	},
+}
+
+// Run checks the provider parses the answers, reports the usage and classifies the failures
+func Run(t *testing.T, provider Provider) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	start := func(t *testing.T, handler http.HandlerFunc) ports.AIAnalyzer {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		analyzer, err := provider.New(server.URL, FixedClock(now))
+		require.NoError(t, err)
+
+		return analyzer
+	}
+
+	t.Run("ShouldReturnAnalysis", func(t *testing.T) {
+		analyzer := start(t, func(w http.ResponseWriter, r *http.Request) {
+			provider.Respond(w, Answer, domain.TokenUsage{Prompt: 100, Completion: 20})
+		})
+
+		analysis, err := analyzer.Analyze(context.Background(), request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "payments cannot reach the database", analysis.Summary)
+		assert.Equal(t, "postgres refuses connections", analysis.ProbableCause)
+		assert.Equal(t, "check the postgres pod", analysis.SuggestedFix)
+		assert.Equal(t, 0.75, analysis.Confidence)
+		assert.Equal(t, analyzer.Name(), analysis.Provider)
+		assert.NotEmpty(t, analysis.Model)
+		assert.Equal(t, domain.TokenUsage{Prompt: 100, Completion: 20}, analysis.Usage)
+		assert.Equal(t, now, analysis.CreatedAt)
+	})
+
+	t.Run("ShouldSendTheEventAndItsContext", func(t *testing.T) {
+		var body string
+		analyzer := start(t, func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			provider.Respond(w, Answer, domain.TokenUsage{})
+		})
+
+		_, err := analyzer.Analyze(context.Background(), request)
+
+		require.NoError(t, err)
+		assert.Contains(t, body, "connection refused")
+		assert.Contains(t, body, "retrying database connection")
+	})
+
+	t.Run("ShouldRejectInvalidAnswer", func(t *testing.T) {
+		analyzer := start(t, func(w http.ResponseWriter, r *http.Request) {
+			provider.Respond(w, "I could not find the cause", domain.TokenUsage{})
+		})
+
+		_, err := analyzer.Analyze(context.Background(), request)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAnalysis)
+	})
+
+	statuses := []struct {
+		name          string
+		status        int
+		expectedError error
+	}{
+		{name: "ShouldReportRateLimit", status: http.StatusTooManyRequests, expectedError: domain.ErrRateLimited},
+		{name: "ShouldReportUnavailable", status: http.StatusServiceUnavailable, expectedError: domain.ErrProviderUnavailable},
+		{name: "ShouldReportRejectedRequest", status: http.StatusBadRequest, expectedError: domain.ErrAnalysisFailed},
+	}
+
+	for _, tt := range statuses {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := start(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"error": {"message": "failed"}}`))
+			})
+
+			_, err := analyzer.Analyze(context.Background(), request)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+
+	t.Run("ShouldStopOnCancelledContext", func(t *testing.T) {
+		analyzer := start(t, func(w http.ResponseWriter, r *http.Request) {
+			provider.Respond(w, Answer, domain.TokenUsage{})
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := analyzer.Analyze(ctx, request)
+
+		assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
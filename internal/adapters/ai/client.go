@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	defaultBackoff = 500 * time.Millisecond
+)
+
+// Client posts JSON to the providers. The transient failures, network errors, timeouts, 429 and
+// 5xx responses, are retried with an exponential backoff honouring the Retry-After header
+type Client struct {
+	http    *http.Client
+	retries int
+	backoff time.Duration
+}
+
+func NewClient(timeout time.Duration, retries int, backoff time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	return &Client{
+		http:    &http.Client{Timeout: timeout},
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+// Post sends the body and returns the successful response, the caller closes its body
+func (c *Client) Post(ctx context.Context, url string, headers map[string]string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrAnalysisFailed, err)
+	}
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		response, retryAfter, err := c.send(ctx, url, headers, payload)
+		if err == nil {
+			return response, nil
+		}
+
+		transient := errors.Is(err, domain.ErrProviderUnavailable) || errors.Is(err, domain.ErrRateLimited)
+		if !transient || attempt >= c.retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", domain.ErrProviderUnavailable, ctx.Err())
+		case <-time.After(max(wait, retryAfter)):
+		}
+		wait *= 2
+	}
+}
+
+// PostJSON sends the body and decodes the successful response into out
+func (c *Client) PostJSON(ctx context.Context, url string, headers map[string]string, body, out any) error {
+	response, err := c.Post(ctx, url, headers, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidAnalysis, err)
+	}
+
+	return nil
+}
+
+// send makes one attempt, returning the delay asked by the provider before the next one
+func (c *Client) send(ctx context.Context, url string, headers map[string]string, payload []byte) (*http.Response, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", domain.ErrAnalysisFailed, err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", domain.ErrProviderUnavailable, err)
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response, 0, nil
+	}
+	defer response.Body.Close()
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	status := fmt.Sprintf("status %d: %s", response.StatusCode, truncate(strings.TrimSpace(string(message))))
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return nil, RetryAfter(response.Header), fmt.Errorf("%w: %s", domain.ErrRateLimited, status)
+	case response.StatusCode == http.StatusRequestTimeout || response.StatusCode >= 500:
+		return nil, RetryAfter(response.Header), fmt.Errorf("%w: %s", domain.ErrProviderUnavailable, status)
+	}
+
+	return nil, 0, fmt.Errorf("%w: %s", domain.ErrAnalysisFailed, status)
+}
+
+// RetryAfter reads the Retry-After header, given in seconds or as a date
+func RetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,120 @@
+package ai_test
+
+import (
+	"context"
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PostJSON(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		retries          int
+		expectedError    error
+		expectedAttempts int32
+	}{
+		{
+			name:             "ShouldDecodeSuccessfulResponse",
+			statuses:         []int{http.StatusOK},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "ShouldRetryServerErrors",
+			statuses:         []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			retries:          2,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "ShouldRetryRateLimits",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retries:          1,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "ShouldGiveUpAfterRetries",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			retries:          1,
+			expectedError:    domain.ErrRateLimited,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "ShouldNotRetryClientErrors",
+			statuses:         []int{http.StatusUnauthorized, http.StatusOK},
+			retries:          3,
+			expectedError:    domain.ErrAnalysisFailed,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempts.Add(1)-1]
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer server.Close()
+
+			client := ai.NewClient(time.Second, tt.retries, time.Millisecond)
+
+			var out struct {
+				OK bool `json:"ok"`
+			}
+			err := client.PostJSON(context.Background(), server.URL, nil, map[string]string{}, &out)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedError == nil, out.OK)
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := ai.NewClient(20*time.Millisecond, 0, time.Millisecond)
+
+	_, err := client.Post(context.Background(), server.URL, nil, map[string]string{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+}
+
+func TestClient_ShouldSendHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := ai.NewClient(time.Second, 0, time.Millisecond)
+
+	var out map[string]any
+	require.NoError(t, client.PostJSON(context.Background(), server.URL, map[string]string{"X-Api-Key": "secret"}, struct{}{}, &out))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, ai.RetryAfter(http.Header{"Retry-After": {"2"}}))
+	assert.Equal(t, 1500*time.Millisecond, ai.RetryAfter(http.Header{"Retry-After": {"1.5"}}))
+	assert.Zero(t, ai.RetryAfter(http.Header{"Retry-After": {"soon"}}))
+	assert.Zero(t, ai.RetryAfter(http.Header{}))
+}
@@ -0,0 +1,227 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"net/url"
+	"strings"
+)
+
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// BLOCK_STAGE_* tell whether the prompt or the answer was blocked
+const (
+	BLOCK_STAGE_PROMPT   = "prompt"
+	BLOCK_STAGE_RESPONSE = "response"
+)
+
+// BlockedError is returned when Gemini refuses the prompt or stops the answer for safety, Categories
+// lists the harm categories rated high enough to block
+type BlockedError struct {
+	Stage      string
+	Reason     string
+	Categories []string
+}
+
+func (e *BlockedError) Error() string {
+	message := fmt.Sprintf("%s: %s blocked: %s", domain.ErrContentBlocked, e.Stage, e.Reason)
+	if len(e.Categories) > 0 {
+		message += " (" + strings.Join(e.Categories, ", ") + ")"
+	}
+
+	return message
+}
+
+func (e *BlockedError) Unwrap() error {
+	return domain.ErrContentBlocked
+}
+
+// responseSchema constrains the JSON answer to the fields of domain.Analysis
+var responseSchema = map[string]any{
+	"type": "OBJECT",
+	"properties": map[string]any{
+		"summary":        map[string]string{"type": "STRING"},
+		"probable_cause": map[string]string{"type": "STRING"},
+		"suggested_fix":  map[string]string{"type": "STRING"},
+		"confidence":     map[string]string{"type": "NUMBER"},
+	},
+	"required": []string{"summary", "probable_cause", "suggested_fix", "confidence"},
+}
+
+// Analyzer calls the generateContent API of Gemini in JSON mode, with the answer constrained by a schema
+type Analyzer struct {
+	config  domain.AIProviderConfig
+	baseURL string
+	key     string
+	client  *ai.Client
+	clock   domain.Clock
+}
+
+type request struct {
+	SystemInstruction content          `json:"systemInstruction"`
+	Contents          []content        `json:"contents"`
+	GenerationConfig  generationConfig `json:"generationConfig"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type generationConfig struct {
+	Temperature      float64        `json:"temperature"`
+	MaxOutputTokens  int            `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string         `json:"responseMimeType"`
+	ResponseSchema   map[string]any `json:"responseSchema"`
+}
+
+type safetyRating struct {
+	Category string `json:"category"`
+	Blocked  bool   `json:"blocked"`
+}
+
+type response struct {
+	Candidates []struct {
+		Content       content        `json:"content"`
+		FinishReason  string         `json:"finishReason"`
+		SafetyRatings []safetyRating `json:"safetyRatings"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason   string         `json:"blockReason"`
+		SafetyRatings []safetyRating `json:"safetyRatings"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// blockReasons are the finish reasons of an answer stopped by the filters of Gemini
+var blockReasons = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"IMAGE_SAFETY":       true,
+}
+
+func NewAnalyzer(config domain.AIProviderConfig, clock domain.Clock) (*Analyzer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := config.Key()
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%w: %s: api key is required", domain.ErrInvalidAIProvider, config.Type)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Analyzer{
+		config:  config,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		key:     key,
+		client:  ai.NewClient(config.Timeout, config.MaxRetries, config.RetryBackoff),
+		clock:   clock,
+	}, nil
+}
+
+func (a *Analyzer) Name() string {
+	return a.config.ProviderName()
+}
+
+// Analyze sends the prompt of the request to the model of its pipeline
+func (a *Analyzer) Analyze(ctx context.Context, analysisRequest domain.AnalysisRequest) (domain.Analysis, error) {
+	model := a.config.ModelFor(analysisRequest.Pipeline)
+
+	body := request{
+		SystemInstruction: content{Parts: []part{{Text: ai.SystemPrompt}}},
+		Contents:          []content{{Role: "user", Parts: []part{{Text: ai.Prompt(analysisRequest)}}}},
+		GenerationConfig: generationConfig{
+			Temperature:      a.config.Temperature,
+			MaxOutputTokens:  a.config.MaxTokens,
+			ResponseMimeType: "application/json",
+			ResponseSchema:   responseSchema,
+		},
+	}
+
+	endpoint := a.baseURL + "/models/" + url.PathEscape(model) + ":generateContent"
+	headers := map[string]string{"x-goog-api-key": a.key}
+
+	var result response
+	if err := a.client.PostJSON(ctx, endpoint, headers, body, &result); err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+
+	text, err := answer(result)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+
+	analysis, err := ai.ParseAnalysis(text)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+
+	analysis.Provider = a.Name()
+	analysis.Model = model
+	if result.ModelVersion != "" {
+		analysis.Model = result.ModelVersion
+	}
+	analysis.Usage = domain.TokenUsage{
+		Prompt:     result.UsageMetadata.PromptTokenCount,
+		Completion: result.UsageMetadata.CandidatesTokenCount,
+	}
+	analysis.CreatedAt = a.clock.Now()
+
+	return analysis, nil
+}
+
+// answer returns the text of the first candidate, or why it was blocked
+func answer(result response) (string, error) {
+	if reason := result.PromptFeedback.BlockReason; reason != "" {
+		return "", &BlockedError{Stage: BLOCK_STAGE_PROMPT, Reason: reason, Categories: blocked(result.PromptFeedback.SafetyRatings)}
+	}
+
+	if len(result.Candidates) == 0 {
+		return "", fmt.Errorf("%w: no candidates", domain.ErrInvalidAnalysis)
+	}
+
+	candidate := result.Candidates[0]
+	if blockReasons[candidate.FinishReason] {
+		return "", &BlockedError{Stage: BLOCK_STAGE_RESPONSE, Reason: candidate.FinishReason, Categories: blocked(candidate.SafetyRatings)}
+	}
+
+	var sb strings.Builder
+	for _, p := range candidate.Content.Parts {
+		sb.WriteString(p.Text)
+	}
+
+	return sb.String(), nil
+}
+
+func blocked(ratings []safetyRating) []string {
+	var categories []string
+
+	for _, rating := range ratings {
+		if rating.Blocked {
+			categories = append(categories, rating.Category)
+		}
+	}
+
+	return categories
+}
@@ -0,0 +1,145 @@
+package gemini_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log-guardian/internal/adapters/ai/aitest"
+	"log-guardian/internal/adapters/ai/gemini"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func respond(w http.ResponseWriter, content string, usage domain.TokenUsage) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"candidates": []any{map[string]any{
+			"content":      map[string]any{"role": "model", "parts": []any{map[string]string{"text": content}}},
+			"finishReason": "STOP",
+		}},
+		"usageMetadata": map[string]int{"promptTokenCount": usage.Prompt, "candidatesTokenCount": usage.Completion},
+		"modelVersion":  "gemini-1.5-flash-002",
+	})
+}
+
+func TestAnalyzer_Conformance(t *testing.T) {
+	aitest.Run(t, aitest.Provider{
+		New: func(baseURL string, clock domain.Clock) (ports.AIAnalyzer, error) {
+			return gemini.NewAnalyzer(domain.AIProviderConfig{
+				Type:    domain.AI_PROVIDER_GEMINI,
+				BaseURL: baseURL,
+				Model:   "gemini-1.5-flash",
+				APIKey:  "key",
+			}, clock)
+		},
+		Respond: respond,
+	})
+}
+
+func TestAnalyzer_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-1.5-pro:generateContent", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-goog-api-key"))
+
+		var body struct {
+			SystemInstruction struct {
+				Parts []struct{ Text string }
+			} `json:"systemInstruction"`
+			GenerationConfig struct {
+				MaxOutputTokens  int            `json:"maxOutputTokens"`
+				ResponseMimeType string         `json:"responseMimeType"`
+				ResponseSchema   map[string]any `json:"responseSchema"`
+			} `json:"generationConfig"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.NotEmpty(t, body.SystemInstruction.Parts)
+		assert.Equal(t, 256, body.GenerationConfig.MaxOutputTokens)
+		assert.Equal(t, "application/json", body.GenerationConfig.ResponseMimeType)
+		assert.Equal(t, "OBJECT", body.GenerationConfig.ResponseSchema["type"])
+
+		respond(w, aitest.Answer, domain.TokenUsage{Prompt: 10, Completion: 5})
+	}))
+	defer server.Close()
+
+	t.Setenv("LOG_GUARDIAN_GEMINI_KEY", "secret")
+
+	analyzer, err := gemini.NewAnalyzer(domain.AIProviderConfig{
+		Type:      domain.AI_PROVIDER_GEMINI,
+		BaseURL:   server.URL + "/v1beta",
+		Model:     "gemini-1.5-flash",
+		Models:    []domain.PipelineModel{{Pipeline: "payments", Model: "gemini-1.5-pro"}},
+		APIKeyEnv: "LOG_GUARDIAN_GEMINI_KEY",
+		MaxTokens: 256,
+	}, aitest.FixedClock(time.Time{}))
+	require.NoError(t, err)
+
+	analysis, err := analyzer.Analyze(context.Background(), domain.AnalysisRequest{Pipeline: "payments"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-1.5-flash-002", analysis.Model)
+	assert.Equal(t, domain.AI_PROVIDER_GEMINI, analyzer.Name())
+}
+
+func TestAnalyzer_Blocked(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected gemini.BlockedError
+	}{
+		{
+			name: "ShouldReportBlockedPrompt",
+			response: `{"promptFeedback": {"blockReason": "SAFETY", "safetyRatings": [
+				{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "HIGH", "blocked": true},
+				{"category": "HARM_CATEGORY_HARASSMENT", "probability": "NEGLIGIBLE"}
+			]}}`,
+			expected: gemini.BlockedError{
+				Stage:      gemini.BLOCK_STAGE_PROMPT,
+				Reason:     "SAFETY",
+				Categories: []string{"HARM_CATEGORY_DANGEROUS_CONTENT"},
+			},
+		},
+		{
+			name:     "ShouldReportBlockedAnswer",
+			response: `{"candidates": [{"content": {"parts": []}, "finishReason": "RECITATION"}]}`,
+			expected: gemini.BlockedError{Stage: gemini.BLOCK_STAGE_RESPONSE, Reason: "RECITATION"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			analyzer, err := gemini.NewAnalyzer(domain.AIProviderConfig{
+				Type:    domain.AI_PROVIDER_GEMINI,
+				BaseURL: server.URL,
+				Model:   "gemini-1.5-flash",
+				APIKey:  "key",
+			}, aitest.FixedClock(time.Time{}))
+			require.NoError(t, err)
+
+			_, err = analyzer.Analyze(context.Background(), domain.AnalysisRequest{})
+
+			assert.ErrorIs(t, err, domain.ErrContentBlocked)
+
+			var blocked *gemini.BlockedError
+			require.True(t, errors.As(err, &blocked))
+			assert.Equal(t, tt.expected, *blocked)
+		})
+	}
+}
+
+func TestNewAnalyzer_MissingKey(t *testing.T) {
+	analyzer, err := gemini.NewAnalyzer(domain.AIProviderConfig{Type: domain.AI_PROVIDER_GEMINI, Model: "gemini-1.5-flash"}, aitest.FixedClock(time.Time{}))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidAIProvider)
+	assert.Nil(t, analyzer)
+}
@@ -0,0 +1,168 @@
+package heuristic
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"regexp"
+)
+
+const (
+	defaultConfidence = 0.5
+	// contextConfidence scales the confidence of a signature only found in the surrounding events
+	contextConfidence = 0.5
+)
+
+// unmatched is answered when no signature is found, so the last resort always gives an analysis
+var unmatched = domain.HeuristicRule{
+	Name:          "unmatched",
+	Summary:       "No known failure signature in the event",
+	ProbableCause: "The event matches none of the known failure signatures and no AI provider answered, the cause can't be told from the message alone",
+	SuggestedFix:  "Read the event along with its surrounding events, and add a heuristic rule for its signature if it comes back",
+	Confidence:    0.1,
+}
+
+// DefaultRules are the well-known failure signatures, tried after the rules of the config
+var DefaultRules = []domain.HeuristicRule{
+	{
+		Name:          "oom_killed",
+		Pattern:       `(?i)OOMKilled|out of memory|oom[-_ ]kill|cannot allocate memory|OutOfMemoryError`,
+		Summary:       "The process ran out of memory",
+		ProbableCause: "The process used more memory than its limit and was killed, or could not allocate memory, because of a leak, a load spike or a limit set too low",
+		SuggestedFix:  "Check the memory usage before the kill, raise the memory limit or the heap size if the usage is legitimate, otherwise profile the process for a leak",
+		Confidence:    0.9,
+	},
+	{
+		Name:          "disk_full",
+		Pattern:       `(?i)no space left on device|ENOSPC|disk (is )?full|disk quota exceeded`,
+		Summary:       "The disk is full",
+		ProbableCause: "The volume ran out of space or inodes, usually because of logs, temporary files or data growing without rotation",
+		SuggestedFix:  "Free space on the volume, add log rotation or retention for what fills it, and grow the volume if the usage is expected",
+		Confidence:    0.9,
+	},
+	{
+		Name:          "nil_pointer",
+		Pattern:       `(?i)nil pointer dereference|invalid memory address|NullPointerException|segmentation (fault|violation)|SIGSEGV`,
+		Summary:       "The process dereferenced a nil pointer",
+		ProbableCause: "A value was used before being set, often an error ignored or an optional field of a response assumed to be present",
+		SuggestedFix:  "Find the failing line in the stack trace, check the error returned before it and guard the value against nil",
+		Confidence:    0.85,
+	},
+	{
+		Name:          "tls_handshake",
+		Pattern:       `(?i)tls: |tls handshake|x509: |certificate (has )?expired|certificate verify failed|SSL routines`,
+		Summary:       "The TLS handshake failed",
+		ProbableCause: "The certificate is expired, not trusted, or issued for another host, or the two sides share no TLS version or cipher",
+		SuggestedFix:  "Check the certificate chain and expiry of the server with openssl s_client, the CA bundle of the client and the host name it connects to",
+		Confidence:    0.8,
+	},
+	{
+		Name:          "dns_resolution",
+		Pattern:       `(?i)no such host|NXDOMAIN|server misbehaving|temporary failure in name resolution|could not resolve host|name or service not known`,
+		Summary:       "A host name could not be resolved",
+		ProbableCause: "The host name is wrong or no longer exists, or the DNS server is unreachable or failing",
+		SuggestedFix:  "Check the host name in the configuration, resolve it from the same host or pod with dig or nslookup, and check the DNS server and resolv.conf",
+		Confidence:    0.8,
+	},
+	{
+		Name:          "connection_refused",
+		Pattern:       `(?i)connection refused|ECONNREFUSED`,
+		Summary:       "A connection was refused",
+		ProbableCause: "Nothing listens on the target address: the service is down, restarting, or listening on another port or interface",
+		SuggestedFix:  "Check that the target service is running and healthy, and that the host and port in the configuration match where it listens",
+		Confidence:    0.8,
+	},
+	{
+		Name:          "deadline_exceeded",
+		Pattern:       `(?i)context deadline exceeded|DeadlineExceeded|i/o timeout`,
+		Summary:       "A call timed out",
+		ProbableCause: "A dependency answered slower than the timeout of the caller, because it is overloaded, blocked on its own dependencies, or the timeout is too short",
+		SuggestedFix:  "Check the latency and saturation of the called service, look for slow queries or lock contention, and review the timeout and retry settings",
+		Confidence:    0.7,
+	},
+}
+
+// Analyzer recognises well-known failure signatures in the event, then in the surrounding events,
+// and returns the canned analysis of the first rule matching, naming the rule as its model. It calls
+// no model and answers a generic analysis when no rule matches, which makes it the last resort when
+// no AI provider answers
+type Analyzer struct {
+	name  string
+	rules []rule
+	clock domain.Clock
+}
+
+type rule struct {
+	domain.HeuristicRule
+	re *regexp.Regexp
+}
+
+func NewAnalyzer(config domain.AIProviderConfig, clock domain.Clock) (*Analyzer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	rules := config.Heuristic.Rules
+	if !config.Heuristic.ReplaceDefaults {
+		rules = append(append([]domain.HeuristicRule(nil), rules...), DefaultRules...)
+	}
+
+	a := &Analyzer{name: config.ProviderName(), clock: clock}
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: heuristic rule %s: %w", domain.ErrInvalidAIProvider, r.Name, err)
+		}
+
+		if r.Confidence == 0 {
+			r.Confidence = defaultConfidence
+		}
+		a.rules = append(a.rules, rule{HeuristicRule: r, re: re})
+	}
+
+	return a, nil
+}
+
+func (a *Analyzer) Name() string {
+	return a.name
+}
+
+// Analyze returns the analysis of the first signature found, in the message of the event first.
+// A signature only found in the surrounding events is less certain, and a low confidence generic
+// analysis is returned when none is found
+func (a *Analyzer) Analyze(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+	if r, ok := a.match(request.Event.Message); ok {
+		return a.analysis(r, r.Confidence), nil
+	}
+
+	for _, event := range request.Context {
+		if r, ok := a.match(event.Message); ok {
+			return a.analysis(r, r.Confidence*contextConfidence), nil
+		}
+	}
+
+	return a.analysis(rule{HeuristicRule: unmatched}, unmatched.Confidence), nil
+}
+
+func (a *Analyzer) match(message string) (rule, bool) {
+	for _, r := range a.rules {
+		if r.re.MatchString(message) {
+			return r, true
+		}
+	}
+
+	return rule{}, false
+}
+
+func (a *Analyzer) analysis(r rule, confidence float64) domain.Analysis {
+	return domain.Analysis{
+		Summary:       r.Summary,
+		ProbableCause: r.ProbableCause,
+		SuggestedFix:  r.SuggestedFix,
+		Confidence:    confidence,
+		Provider:      a.name,
+		Model:         r.Name,
+		Heuristic:     true,
+		CreatedAt:     a.clock.Now(),
+	}
+}
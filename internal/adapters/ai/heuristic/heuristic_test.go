@@ -0,0 +1,157 @@
+package heuristic_test
+
+import (
+	"context"
+	"log-guardian/internal/adapters/ai/aitest"
+	"log-guardian/internal/adapters/ai/heuristic"
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func analyze(t *testing.T, config domain.AIProviderConfig, request domain.AnalysisRequest) (domain.Analysis, error) {
+	t.Helper()
+
+	analyzer, err := heuristic.NewAnalyzer(config, aitest.FixedClock(now))
+	require.NoError(t, err)
+
+	return analyzer.Analyze(context.Background(), request)
+}
+
+func TestAnalyzer_DefaultRules(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: `container "api" terminated: reason OOMKilled, exit code 137`, expected: "oom_killed"},
+		{message: "java.lang.OutOfMemoryError: Java heap space", expected: "oom_killed"},
+		{message: "dial tcp 10.0.0.5:5432: connect: connection refused", expected: "connection_refused"},
+		{message: "rpc error: code = DeadlineExceeded desc = context deadline exceeded", expected: "deadline_exceeded"},
+		{message: "read tcp 10.0.0.4:443: i/o timeout", expected: "deadline_exceeded"},
+		{message: "panic: runtime error: invalid memory address or nil pointer dereference", expected: "nil_pointer"},
+		{message: "write /var/lib/postgresql/data/base/16384: no space left on device", expected: "disk_full"},
+		{message: "remote error: tls: handshake failure", expected: "tls_handshake"},
+		{message: "x509: certificate has expired or is not yet valid", expected: "tls_handshake"},
+		{message: "dial tcp: lookup payments.internal on 10.96.0.10:53: no such host", expected: "dns_resolution"},
+		{message: "curl: (6) Could not resolve host: api.example.com", expected: "dns_resolution"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result, err := analyze(t, domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC}, domain.AnalysisRequest{
+				Event: domain.LogEvent{Message: tt.message},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Model)
+			assert.NotEmpty(t, result.Summary)
+			assert.NotEmpty(t, result.ProbableCause)
+			assert.NotEmpty(t, result.SuggestedFix)
+			assert.Equal(t, domain.AI_PROVIDER_HEURISTIC, result.Provider)
+			assert.Equal(t, now, result.CreatedAt)
+		})
+	}
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	custom := domain.HeuristicRule{
+		Name:          "pool_exhausted",
+		Pattern:       `connection pool exhausted|too many connections`,
+		Summary:       "The database connection pool is exhausted",
+		ProbableCause: "Connections are leaked or held by slow queries",
+		SuggestedFix:  "Check the open connections with pg_stat_activity",
+	}
+
+	tests := []struct {
+		name          string
+		config        domain.HeuristicConfig
+		request       domain.AnalysisRequest
+		expected      domain.Analysis
+		expectedError error
+	}{
+		{
+			name:    "ShouldTryConfiguredRulesFirst",
+			config:  domain.HeuristicConfig{Rules: []domain.HeuristicRule{custom}},
+			request: domain.AnalysisRequest{Event: domain.LogEvent{Message: "too many connections, connection refused"}},
+			expected: domain.Analysis{
+				Summary:       custom.Summary,
+				ProbableCause: custom.ProbableCause,
+				SuggestedFix:  custom.SuggestedFix,
+				Confidence:    0.5,
+				Provider:      domain.AI_PROVIDER_HEURISTIC,
+				Model:         "pool_exhausted",
+				Heuristic:     true,
+				CreatedAt:     now,
+			},
+		},
+		{
+			name:    "ShouldReplaceDefaultRules",
+			config:  domain.HeuristicConfig{Rules: []domain.HeuristicRule{custom}, ReplaceDefaults: true},
+			request: domain.AnalysisRequest{Event: domain.LogEvent{Message: "connection refused"}},
+			expected: domain.Analysis{
+				Summary:       "No known failure signature in the event",
+				ProbableCause: "The event matches none of the known failure signatures and no AI provider answered, the cause can't be told from the message alone",
+				SuggestedFix:  "Read the event along with its surrounding events, and add a heuristic rule for its signature if it comes back",
+				Confidence:    0.1,
+				Provider:      domain.AI_PROVIDER_HEURISTIC,
+				Model:         "unmatched",
+				Heuristic:     true,
+				CreatedAt:     now,
+			},
+		},
+		{
+			name: "ShouldLowerConfidenceOfSurroundingEvents",
+			request: domain.AnalysisRequest{
+				Event:   domain.LogEvent{Message: "request failed"},
+				Context: []domain.LogEvent{{Message: "healthy"}, {Message: "connection refused"}},
+			},
+			expected: domain.Analysis{
+				Summary:       heuristic.DefaultRules[5].Summary,
+				ProbableCause: heuristic.DefaultRules[5].ProbableCause,
+				SuggestedFix:  heuristic.DefaultRules[5].SuggestedFix,
+				Confidence:    0.4,
+				Provider:      domain.AI_PROVIDER_HEURISTIC,
+				Model:         "connection_refused",
+				Heuristic:     true,
+				CreatedAt:     now,
+			},
+		},
+		{
+			name:    "ShouldAnswerGenericAnalysisWithoutKnownSignature",
+			request: domain.AnalysisRequest{Event: domain.LogEvent{Message: "payment declined"}},
+			expected: domain.Analysis{
+				Summary:       "No known failure signature in the event",
+				ProbableCause: "The event matches none of the known failure signatures and no AI provider answered, the cause can't be told from the message alone",
+				SuggestedFix:  "Read the event along with its surrounding events, and add a heuristic rule for its signature if it comes back",
+				Confidence:    0.1,
+				Provider:      domain.AI_PROVIDER_HEURISTIC,
+				Model:         "unmatched",
+				Heuristic:     true,
+				CreatedAt:     now,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := analyze(t, domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC, Heuristic: tt.config}, tt.request)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNewAnalyzer_ShouldRejectInvalidRule(t *testing.T) {
+	_, err := heuristic.NewAnalyzer(domain.AIProviderConfig{
+		Type:      domain.AI_PROVIDER_HEURISTIC,
+		Heuristic: domain.HeuristicConfig{Rules: []domain.HeuristicRule{{Name: "broken", Pattern: "(", Summary: "x"}}},
+	}, aitest.FixedClock(now))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidAIProvider)
+}
@@ -0,0 +1,208 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"strings"
+)
+
+const (
+	defaultBaseURL = "http://localhost:11434"
+	// maxLineSize bounds a line of the streamed answer
+	maxLineSize = 1 << 20
+)
+
+// Analyzer calls a local Ollama server on its chat or generate endpoint, asking for a JSON answer.
+// A streamed answer is read chunk by chunk until the final one carrying the token counts
+type Analyzer struct {
+	config domain.AIProviderConfig
+	url    string
+	key    string
+	client *ai.Client
+	clock  domain.Clock
+}
+
+type request struct {
+	Model     string         `json:"model"`
+	Messages  []message      `json:"messages,omitempty"`
+	System    string         `json:"system,omitempty"`
+	Prompt    string         `json:"prompt,omitempty"`
+	Format    string         `json:"format"`
+	Stream    bool           `json:"stream"`
+	KeepAlive any            `json:"keep_alive,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chunk is a line of the answer, the whole answer when it isn't streamed. The chat endpoint
+// answers in Message, the generate endpoint in Response
+type chunk struct {
+	Model           string  `json:"model"`
+	Message         message `json:"message"`
+	Response        string  `json:"response"`
+	Done            bool    `json:"done"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+func NewAnalyzer(config domain.AIProviderConfig, clock domain.Clock) (*Analyzer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := config.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	endpoint := config.Ollama.Endpoint
+	if endpoint == "" {
+		endpoint = domain.OLLAMA_ENDPOINT_CHAT
+	}
+
+	return &Analyzer{
+		config: config,
+		url:    strings.TrimSuffix(baseURL, "/") + "/api/" + endpoint,
+		key:    key,
+		client: ai.NewClient(config.Timeout, config.MaxRetries, config.RetryBackoff),
+		clock:  clock,
+	}, nil
+}
+
+func (a *Analyzer) Name() string {
+	return a.config.ProviderName()
+}
+
+// Analyze sends the prompt of the request with the model of its pipeline
+func (a *Analyzer) Analyze(ctx context.Context, analysisRequest domain.AnalysisRequest) (domain.Analysis, error) {
+	model := a.config.ModelFor(analysisRequest.Pipeline)
+	prompt := ai.Prompt(analysisRequest)
+
+	body := request{
+		Model:     model,
+		Format:    "json",
+		Stream:    a.config.Ollama.Stream,
+		KeepAlive: keepAlive(a.config.Ollama),
+		Options:   a.options(),
+	}
+
+	if strings.HasSuffix(a.url, "/api/"+domain.OLLAMA_ENDPOINT_GENERATE) {
+		body.System = ai.SystemPrompt
+		body.Prompt = prompt
+	} else {
+		body.Messages = []message{
+			{Role: "system", Content: ai.SystemPrompt},
+			{Role: "user", Content: prompt},
+		}
+	}
+
+	headers := map[string]string{}
+	if a.key != "" {
+		headers["Authorization"] = "Bearer " + a.key
+	}
+
+	response, err := a.client.Post(ctx, a.url, headers, body)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+	defer response.Body.Close()
+
+	content, final, err := read(response.Body)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+
+	analysis, err := ai.ParseAnalysis(content)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.Name(), err)
+	}
+
+	analysis.Provider = a.Name()
+	analysis.Model = model
+	if final.Model != "" {
+		analysis.Model = final.Model
+	}
+	analysis.Usage = domain.TokenUsage{Prompt: final.PromptEvalCount, Completion: final.EvalCount}
+	analysis.CreatedAt = a.clock.Now()
+
+	return analysis, nil
+}
+
+func (a *Analyzer) options() map[string]any {
+	options := map[string]any{"temperature": a.config.Temperature}
+	if a.config.MaxTokens > 0 {
+		options["num_predict"] = a.config.MaxTokens
+	}
+
+	return options
+}
+
+// read joins the chunks of the answer, it returns the content and the final chunk
+func read(body io.Reader) (string, chunk, error) {
+	var (
+		content strings.Builder
+		last    chunk
+	)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var c chunk
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return "", chunk{}, fmt.Errorf("%w: %w", domain.ErrInvalidAnalysis, err)
+		}
+
+		if c.Error != "" {
+			return "", chunk{}, fmt.Errorf("%w: %s", domain.ErrAnalysisFailed, c.Error)
+		}
+
+		content.WriteString(c.Message.Content)
+		content.WriteString(c.Response)
+		last = c
+
+		if c.Done {
+			return content.String(), last, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", chunk{}, fmt.Errorf("%w: %w", domain.ErrProviderUnavailable, err)
+	}
+
+	return "", chunk{}, fmt.Errorf("%w: %w", domain.ErrProviderUnavailable, errors.New("answer ended before done"))
+}
+
+// keepAlive returns the keep_alive value, -1 keeps the model loaded and nothing leaves the
+// server default
+func keepAlive(config domain.OllamaConfig) any {
+	switch {
+	case config.KeepAlive < 0:
+		return -1
+	case config.KeepAlive > 0:
+		return config.KeepAlive.String()
+	}
+
+	return nil
+}
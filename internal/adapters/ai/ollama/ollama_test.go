@@ -0,0 +1,219 @@
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"log-guardian/internal/adapters/ai/aitest"
+	"log-guardian/internal/adapters/ai/ollama"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const answer = `{"summary":"disk full","probable_cause":"logs fill /var","suggested_fix":"rotate the logs","confidence":0.9}`
+
+// streamed splits the answer into the chunks of a streamed chat answer
+func streamed(content string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(content); i += 16 {
+		end := min(i+16, len(content))
+		line, _ := json.Marshal(map[string]any{"message": map[string]string{"role": "assistant", "content": content[i:end]}, "done": false})
+		sb.Write(line)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(`{"model":"llama3:8b","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":80,"eval_count":25}` + "\n")
+
+	return sb.String()
+}
+
+func TestAnalyzer_Conformance(t *testing.T) {
+	aitest.Run(t, aitest.Provider{
+		New: func(baseURL string, clock domain.Clock) (ports.AIAnalyzer, error) {
+			return ollama.NewAnalyzer(domain.AIProviderConfig{
+				Type:    domain.AI_PROVIDER_OLLAMA,
+				BaseURL: baseURL,
+				Model:   "llama3",
+				Ollama:  domain.OllamaConfig{Stream: true},
+			}, clock)
+		},
+		Respond: func(w http.ResponseWriter, content string, usage domain.TokenUsage) {
+			for i := 0; i < len(content); i += 16 {
+				line, _ := json.Marshal(map[string]any{"message": map[string]string{"content": content[i:min(i+16, len(content))]}})
+				w.Write(append(line, '\n'))
+			}
+			line, _ := json.Marshal(map[string]any{"done": true, "prompt_eval_count": usage.Prompt, "eval_count": usage.Completion})
+			w.Write(line)
+		},
+	})
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         domain.AIProviderConfig
+		pipeline       string
+		response       string
+		expectedPath   string
+		expectedModel  string
+		expectedStream bool
+		expectedKeep   any
+		expectedUsage  domain.TokenUsage
+		expectedResult string
+	}{
+		{
+			name: "ShouldReadStreamedChat",
+			config: domain.AIProviderConfig{
+				Type:   domain.AI_PROVIDER_OLLAMA,
+				Model:  "llama3",
+				Ollama: domain.OllamaConfig{Stream: true, KeepAlive: 10 * time.Minute},
+			},
+			response:       streamed(answer),
+			expectedPath:   "/api/chat",
+			expectedModel:  "llama3",
+			expectedStream: true,
+			expectedKeep:   "10m0s",
+			expectedUsage:  domain.TokenUsage{Prompt: 80, Completion: 25},
+			expectedResult: "llama3:8b",
+		},
+		{
+			name: "ShouldReadGenerate",
+			config: domain.AIProviderConfig{
+				Type:   domain.AI_PROVIDER_OLLAMA,
+				Model:  "llama3",
+				Ollama: domain.OllamaConfig{Endpoint: domain.OLLAMA_ENDPOINT_GENERATE, KeepAlive: -1},
+			},
+			response:       `{"model":"llama3","response":` + quote(answer) + `,"done":true,"prompt_eval_count":70,"eval_count":20}`,
+			expectedPath:   "/api/generate",
+			expectedModel:  "llama3",
+			expectedKeep:   float64(-1),
+			expectedUsage:  domain.TokenUsage{Prompt: 70, Completion: 20},
+			expectedResult: "llama3",
+		},
+		{
+			name: "ShouldUseModelOfPipeline",
+			config: domain.AIProviderConfig{
+				Type:   domain.AI_PROVIDER_OLLAMA,
+				Model:  "llama3",
+				Models: []domain.PipelineModel{{Pipeline: "payments", Model: "qwen2.5:14b"}},
+			},
+			pipeline:       "payments",
+			response:       `{"message":{"content":` + quote(answer) + `},"done":true}`,
+			expectedPath:   "/api/chat",
+			expectedModel:  "qwen2.5:14b",
+			expectedResult: "qwen2.5:14b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.expectedPath, r.URL.Path)
+
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, tt.expectedModel, body["model"])
+				assert.Equal(t, tt.expectedStream, body["stream"])
+				assert.Equal(t, "json", body["format"])
+				assert.Equal(t, tt.expectedKeep, body["keep_alive"])
+
+				if tt.expectedPath == "/api/generate" {
+					assert.Contains(t, body["prompt"], "no space left on device")
+					assert.NotEmpty(t, body["system"])
+				} else {
+					assert.Len(t, body["messages"], 2)
+				}
+
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			tt.config.BaseURL = server.URL
+			analyzer, err := ollama.NewAnalyzer(tt.config, aitest.FixedClock(now))
+			require.NoError(t, err)
+
+			analysis, err := analyzer.Analyze(context.Background(), domain.AnalysisRequest{
+				Pipeline: tt.pipeline,
+				Event:    domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Message: "write /var/log/app.log: no space left on device"},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, "disk full", analysis.Summary)
+			assert.Equal(t, "rotate the logs", analysis.SuggestedFix)
+			assert.Equal(t, domain.AI_PROVIDER_OLLAMA, analysis.Provider)
+			assert.Equal(t, tt.expectedUsage, analysis.Usage)
+			assert.Equal(t, now, analysis.CreatedAt)
+			assert.Equal(t, tt.expectedResult, analysis.Model)
+		})
+	}
+}
+
+func TestAnalyzer_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		response      string
+		expectedError error
+	}{
+		{
+			name:          "ShouldReportErrorChunk",
+			status:        http.StatusOK,
+			response:      `{"message":{"content":"{\"sum"},"done":false}` + "\n" + `{"error":"model crashed"}` + "\n",
+			expectedError: domain.ErrAnalysisFailed,
+		},
+		{
+			name:          "ShouldReportInterruptedStream",
+			status:        http.StatusOK,
+			response:      `{"message":{"content":"{\"summary\":"},"done":false}` + "\n",
+			expectedError: domain.ErrProviderUnavailable,
+		},
+		{
+			name:          "ShouldReportMalformedChunk",
+			status:        http.StatusOK,
+			response:      "not json\n",
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+		{
+			name:          "ShouldReportMissingModel",
+			status:        http.StatusNotFound,
+			response:      `{"error":"model 'llama9' not found"}`,
+			expectedError: domain.ErrAnalysisFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			analyzer, err := ollama.NewAnalyzer(domain.AIProviderConfig{
+				Type:    domain.AI_PROVIDER_OLLAMA,
+				BaseURL: server.URL,
+				Model:   "llama9",
+				Ollama:  domain.OllamaConfig{Stream: true},
+			}, aitest.FixedClock(time.Time{}))
+			require.NoError(t, err)
+
+			_, err = analyzer.Analyze(context.Background(), domain.AnalysisRequest{})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
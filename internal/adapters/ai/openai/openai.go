@@ -0,0 +1,132 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"strings"
+)
+
+const defaultBaseURL = "https://api.openai.com/v1"
+
+// Analyzer calls the chat completions API of OpenAI, or of any server compatible with it such as
+// vLLM, LiteLLM or Azure OpenAI, asking for a JSON object answer
+type Analyzer struct {
+	name        string
+	url         string
+	config      domain.AIProviderConfig
+	key         string
+	temperature float64
+	maxTokens   int
+	client      *ai.Client
+	clock       domain.Clock
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []message       `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func NewAnalyzer(config domain.AIProviderConfig, clock domain.Clock) (*Analyzer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := config.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Analyzer{
+		name:        config.ProviderName(),
+		url:         strings.TrimSuffix(baseURL, "/") + "/chat/completions",
+		config:      config,
+		key:         key,
+		temperature: config.Temperature,
+		maxTokens:   config.MaxTokens,
+		client:      ai.NewClient(config.Timeout, config.MaxRetries, config.RetryBackoff),
+		clock:       clock,
+	}, nil
+}
+
+func (a *Analyzer) Name() string {
+	return a.name
+}
+
+// Analyze sends the prompt of the request and parses the first choice
+func (a *Analyzer) Analyze(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+	model := a.config.ModelFor(request.Pipeline)
+
+	body := chatRequest{
+		Model: model,
+		Messages: []message{
+			{Role: "system", Content: ai.SystemPrompt},
+			{Role: "user", Content: ai.Prompt(request)},
+		},
+		Temperature:    a.temperature,
+		MaxTokens:      a.maxTokens,
+		ResponseFormat: &responseFormat{Type: "json_object"},
+	}
+
+	headers := map[string]string{}
+	if a.key != "" {
+		headers["Authorization"] = "Bearer " + a.key
+	}
+
+	var response chatResponse
+	if err := a.client.PostJSON(ctx, a.url, headers, body, &response); err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.name, err)
+	}
+
+	if len(response.Choices) == 0 {
+		return domain.Analysis{}, fmt.Errorf("%s: %w: no choices", a.name, domain.ErrInvalidAnalysis)
+	}
+
+	analysis, err := ai.ParseAnalysis(response.Choices[0].Message.Content)
+	if err != nil {
+		return domain.Analysis{}, fmt.Errorf("%s: %w", a.name, err)
+	}
+
+	analysis.Provider = a.name
+	analysis.Model = model
+	if response.Model != "" {
+		analysis.Model = response.Model
+	}
+	analysis.Usage = domain.TokenUsage{
+		Prompt:     response.Usage.PromptTokens,
+		Completion: response.Usage.CompletionTokens,
+	}
+	analysis.CreatedAt = a.clock.Now()
+
+	return analysis, nil
+}
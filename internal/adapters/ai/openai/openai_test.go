@@ -0,0 +1,166 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"log-guardian/internal/adapters/ai/aitest"
+	"log-guardian/internal/adapters/ai/openai"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Conformance(t *testing.T) {
+	aitest.Run(t, aitest.Provider{
+		New: func(baseURL string, clock domain.Clock) (ports.AIAnalyzer, error) {
+			return openai.NewAnalyzer(domain.AIProviderConfig{
+				Type:    domain.AI_PROVIDER_OPENAI,
+				BaseURL: baseURL,
+				Model:   "gpt-4o-mini",
+			}, clock)
+		},
+		Respond: func(w http.ResponseWriter, content string, usage domain.TokenUsage) {
+			json.NewEncoder(w).Encode(map[string]any{
+				"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}},
+				"usage":   map[string]int{"prompt_tokens": usage.Prompt, "completion_tokens": usage.Completion},
+			})
+		},
+	})
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		var body struct {
+			Model          string `json:"model"`
+			Messages       []struct{ Role, Content string }
+			MaxTokens      int `json:"max_tokens"`
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-4o-mini", body.Model)
+		assert.Equal(t, 300, body.MaxTokens)
+		assert.Equal(t, "json_object", body.ResponseFormat.Type)
+		require.Len(t, body.Messages, 2)
+		assert.Equal(t, "system", body.Messages[0].Role)
+		assert.Contains(t, body.Messages[1].Content, "connection refused")
+		assert.Contains(t, body.Messages[1].Content, "Incident inc-1 on payments: payments errors (ERROR, 3 events, 1 alerts)")
+
+		w.Write([]byte(`{
+			"model": "gpt-4o-mini-2024-07-18",
+			"choices": [{"message": {"role": "assistant", "content": "{\"summary\":\"db unreachable\",\"probable_cause\":\"postgres is down\",\"suggested_fix\":\"restart postgres\",\"confidence\":0.7}"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 120, "completion_tokens": 40}
+		}`))
+	}))
+	defer server.Close()
+
+	analyzer, err := openai.NewAnalyzer(domain.AIProviderConfig{
+		Type:      domain.AI_PROVIDER_OPENAI,
+		BaseURL:   server.URL + "/v1/",
+		Model:     "gpt-4o-mini",
+		APIKey:    "sk-test",
+		MaxTokens: 300,
+	}, aitest.FixedClock(now))
+	require.NoError(t, err)
+
+	analysis, err := analyzer.Analyze(context.Background(), domain.AnalysisRequest{
+		Event: domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Message: "dial tcp 10.0.0.5:5432: connection refused"},
+		Incident: &domain.Incident{
+			ID:         "inc-1",
+			Service:    "payments",
+			Title:      "payments errors",
+			Severity:   domain.LOG_LEVEL_ERROR,
+			EventCount: 3,
+			AlertCount: 1,
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.Analysis{
+		Summary:       "db unreachable",
+		ProbableCause: "postgres is down",
+		SuggestedFix:  "restart postgres",
+		Confidence:    0.7,
+		Provider:      domain.AI_PROVIDER_OPENAI,
+		Model:         "gpt-4o-mini-2024-07-18",
+		Usage:         domain.TokenUsage{Prompt: 120, Completion: 40},
+		CreatedAt:     now,
+	}, analysis)
+	assert.Equal(t, domain.AI_PROVIDER_OPENAI, analyzer.Name())
+}
+
+func TestAnalyzer_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedError error
+	}{
+		{
+			name:          "ShouldFailWithoutChoices",
+			status:        http.StatusOK,
+			body:          `{"choices": []}`,
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+		{
+			name:          "ShouldFailOnInvalidContent",
+			status:        http.StatusOK,
+			body:          `{"choices": [{"message": {"content": "sorry"}}]}`,
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+		{
+			name:          "ShouldReportRateLimits",
+			status:        http.StatusTooManyRequests,
+			body:          `{"error": {"message": "Rate limit reached"}}`,
+			expectedError: domain.ErrRateLimited,
+		},
+		{
+			name:          "ShouldReportRejectedRequests",
+			status:        http.StatusBadRequest,
+			body:          `{"error": {"message": "model not found"}}`,
+			expectedError: domain.ErrAnalysisFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			analyzer, err := openai.NewAnalyzer(domain.AIProviderConfig{
+				Name:    "local",
+				Type:    domain.AI_PROVIDER_OPENAI,
+				BaseURL: server.URL,
+				Model:   "llama3",
+			}, aitest.FixedClock(time.Time{}))
+			require.NoError(t, err)
+
+			_, err = analyzer.Analyze(context.Background(), domain.AnalysisRequest{})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.ErrorContains(t, err, "local: ")
+		})
+	}
+}
+
+func TestNewAnalyzer_InvalidConfig(t *testing.T) {
+	analyzer, err := openai.NewAnalyzer(domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI}, aitest.FixedClock(time.Time{}))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidAIProvider)
+	assert.Nil(t, analyzer)
+}
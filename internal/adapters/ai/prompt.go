@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SystemPrompt tells the model its role and the shape of the answer expected by ParseAnalysis
+const SystemPrompt = `You are a site reliability engineer investigating production logs. ` +
+	`Explain what went wrong from the log events you are given. ` +
+	`Answer only with a JSON object with the fields "summary", "probable_cause", "suggested_fix" ` +
+	`and "confidence", a number between 0 and 1 telling how sure you are of the cause.`
+
+// Prompt returns the prompt rendered for the request, or describes the incident, the event and the
+// events surrounding it, one event per line
+func Prompt(request domain.AnalysisRequest) string {
+	if request.Prompt != "" {
+		return request.Prompt
+	}
+
+	var sb strings.Builder
+
+	if incident := request.Incident; incident != nil {
+		fmt.Fprintf(&sb, "Incident %s on %s: %s (%s, %d events, %d alerts)\n\n",
+			incident.ID, incident.Service, incident.Title, incident.Severity, incident.EventCount, incident.AlertCount)
+	}
+
+	sb.WriteString("Event:\n")
+	writeEvent(&sb, request.Event)
+
+	if len(request.Context) > 0 {
+		sb.WriteString("\nSurrounding events:\n")
+		for _, event := range request.Context {
+			writeEvent(&sb, event)
+		}
+	}
+
+	return sb.String()
+}
+
+func writeEvent(sb *strings.Builder, event domain.LogEvent) {
+	fmt.Fprintf(sb, "%s [%s] %s: %s", event.Timestamp.Format(time.RFC3339), event.Severity, event.Source, event.Message)
+
+	keys := make([]string, 0, len(event.Metadata))
+	for key := range event.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch value := event.Metadata[key].(type) {
+		case string, bool, int, int64, float64:
+			fmt.Fprintf(sb, " %s=%v", key, value)
+		}
+	}
+	sb.WriteByte('\n')
+}
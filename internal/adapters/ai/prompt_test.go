@@ -0,0 +1,40 @@
+package ai_test
+
+import (
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrompt(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	prompt := ai.Prompt(domain.AnalysisRequest{
+		Event: domain.LogEvent{
+			Timestamp: at,
+			Source:    domain.SOURCE_FILE,
+			Severity:  domain.LOG_LEVEL_ERROR,
+			Message:   "payment failed",
+			Metadata:  map[string]interface{}{"pod": "api-1", "status": 502, "labels": map[string]string{"app": "api"}},
+		},
+		Context: []domain.LogEvent{
+			{Timestamp: at.Add(-time.Second), Source: domain.SOURCE_UNIX, Severity: domain.LOG_LEVEL_WARNING, Message: "slow upstream"},
+		},
+		Incident: &domain.Incident{ID: "inc-1", Service: "payments", Title: "payment failed", Severity: domain.LOG_LEVEL_ERROR, EventCount: 3, AlertCount: 1},
+	})
+
+	assert.Equal(t, "Incident inc-1 on payments: payment failed (ERROR, 3 events, 1 alerts)\n\n"+
+		"Event:\n"+
+		"2025-01-01T12:00:00Z [ERROR] file: payment failed pod=api-1 status=502\n"+
+		"\nSurrounding events:\n"+
+		"2025-01-01T11:59:59Z [WARNING] unix: slow upstream\n", prompt)
+}
+
+func TestPrompt_ShouldUseRenderedPrompt(t *testing.T) {
+	prompt := ai.Prompt(domain.AnalysisRequest{Prompt: "rendered", Event: domain.LogEvent{Message: "ignored"}})
+
+	assert.Equal(t, "rendered", prompt)
+}
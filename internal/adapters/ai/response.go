@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"strings"
+)
+
+// answer holds the fields the model is asked for, the others of domain.Analysis are never taken
+// from its answer
+type answer struct {
+	Summary       string  `json:"summary"`
+	ProbableCause string  `json:"probable_cause"`
+	SuggestedFix  string  `json:"suggested_fix"`
+	Confidence    float64 `json:"confidence"`
+}
+
+// ParseAnalysis reads the JSON object answered by the model, ignoring the text around it such as
+// markdown fences. The confidence is kept between 0 and 1
+func ParseAnalysis(content string) (domain.Analysis, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return domain.Analysis{}, fmt.Errorf("%w: no JSON object in %q", domain.ErrInvalidAnalysis, truncate(content))
+	}
+
+	var parsed answer
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return domain.Analysis{}, fmt.Errorf("%w: %w", domain.ErrInvalidAnalysis, err)
+	}
+
+	if parsed.Summary == "" {
+		return domain.Analysis{}, fmt.Errorf("%w: missing summary", domain.ErrInvalidAnalysis)
+	}
+
+	return domain.Analysis{
+		Summary:       parsed.Summary,
+		ProbableCause: parsed.ProbableCause,
+		SuggestedFix:  parsed.SuggestedFix,
+		Confidence:    min(max(parsed.Confidence, 0), 1),
+	}, nil
+}
+
+// truncate shortens the texts quoted in the errors
+func truncate(text string) string {
+	const limit = 200
+
+	if len(text) <= limit {
+		return text
+	}
+
+	return text[:limit] + "..."
+}
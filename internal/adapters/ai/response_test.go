@@ -0,0 +1,68 @@
+package ai_test
+
+import (
+	"log-guardian/internal/adapters/ai"
+	"log-guardian/internal/core/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnalysis(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expected      domain.Analysis
+		expectedError error
+	}{
+		{
+			name:    "ShouldParseJSONObject",
+			content: `{"summary":"db down","probable_cause":"connection pool exhausted","suggested_fix":"raise the pool size","confidence":0.8}`,
+			expected: domain.Analysis{
+				Summary:       "db down",
+				ProbableCause: "connection pool exhausted",
+				SuggestedFix:  "raise the pool size",
+				Confidence:    0.8,
+			},
+		},
+		{
+			name:     "ShouldIgnoreMarkdownFences",
+			content:  "Here you go:\n```json\n{\"summary\":\"db down\",\"confidence\":0.5}\n```",
+			expected: domain.Analysis{Summary: "db down", Confidence: 0.5},
+		},
+		{
+			name:     "ShouldClampConfidence",
+			content:  `{"summary":"db down","confidence":7}`,
+			expected: domain.Analysis{Summary: "db down", Confidence: 1},
+		},
+		{
+			name:     "ShouldIgnoreFieldsNotAskedFor",
+			content:  `{"summary":"db down","heuristic":true,"cached":true,"provider":"fake","created_at":"2020-01-01T00:00:00Z"}`,
+			expected: domain.Analysis{Summary: "db down"},
+		},
+		{
+			name:          "ShouldFailWithoutJSON",
+			content:       "I don't know",
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+		{
+			name:          "ShouldFailOnMalformedJSON",
+			content:       `{"summary": }`,
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+		{
+			name:          "ShouldFailWithoutSummary",
+			content:       `{"probable_cause":"unknown"}`,
+			expectedError: domain.ErrInvalidAnalysis,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := ai.ParseAnalysis(tt.content)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, analysis)
+		})
+	}
+}
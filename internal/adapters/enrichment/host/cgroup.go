@@ -0,0 +1,105 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const procRoot = "/proc"
+
+// regexContainerID matches the 64 hex chars id used by docker, containerd and cri-o,
+// optionally wrapped by the runtime prefix and the systemd ".scope" suffix
+var regexContainerID = regexp.MustCompile(`(?:docker-|cri-containerd-|crio-|containerd-)?([0-9a-f]{64})(?:\.scope)?$`)
+
+// ParseContainerID extracts the container ID from the content of a /proc/<pid>/cgroup file
+func ParseContainerID(cgroup []byte) string {
+	for _, line := range strings.Split(string(cgroup), "\n") {
+		// each line has the format hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		for _, segment := range strings.Split(parts[2], "/") {
+			matches := regexContainerID.FindStringSubmatch(segment)
+			if len(matches) > 1 {
+				return matches[1]
+			}
+		}
+	}
+
+	return ""
+}
+
+// ContainerID returns the container ID of the process, or empty when it runs outside a container
+func ContainerID(system System, pid int) string {
+	data, err := system.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+
+	return ParseContainerID(data)
+}
+
+// FindWriterPID scans the open file descriptors of the running processes looking for
+// the one holding the given path, it returns false when no process is found.
+// The current process is skipped, since the file ingestion keeps the file open too
+func FindWriterPID(system System, path string) (int, bool) {
+	entries, err := system.ReadDir(procRoot)
+	if err != nil {
+		return 0, false
+	}
+
+	self := os.Getpid()
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+
+		fdDir := filepath.Join(procRoot, entry.Name(), "fd")
+		fds, err := system.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := system.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && target == path {
+				return pid, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// FileWriterResolver returns a PIDResolver for the writer of the file. The scan is costly, so a
+// found process is kept and a failed lookup is only retried after the retry interval
+func FileWriterResolver(system System, path string, retry time.Duration) PIDResolver {
+	var (
+		mu        sync.Mutex
+		pid       int
+		found     bool
+		lastCheck time.Time
+	)
+
+	return func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if found || time.Since(lastCheck) < retry {
+			return pid, found
+		}
+
+		lastCheck = time.Now()
+		pid, found = FindWriterPID(system, path)
+
+		return pid, found
+	}
+}
@@ -0,0 +1,165 @@
+package host_test
+
+import (
+	"errors"
+	"io/fs"
+	"log-guardian/internal/adapters/enrichment/host"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const containerID = "3f4e1c2b9a8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+
+func TestParseContainerID(t *testing.T) {
+	tests := []struct {
+		name     string
+		cgroup   string
+		expected string
+	}{
+		{
+			name:     "docker cgroup v1",
+			cgroup:   "12:memory:/docker/" + containerID + "\n11:cpu:/docker/" + containerID,
+			expected: containerID,
+		},
+		{
+			name:     "kubernetes with containerd",
+			cgroup:   "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1234.slice/cri-containerd-" + containerID + ".scope",
+			expected: containerID,
+		},
+		{
+			name:     "systemd docker scope",
+			cgroup:   "0::/system.slice/docker-" + containerID + ".scope",
+			expected: containerID,
+		},
+		{
+			name:     "cri-o",
+			cgroup:   "0::/kubepods/burstable/pod1234/crio-" + containerID,
+			expected: containerID,
+		},
+		{
+			name:     "host process",
+			cgroup:   "0::/user.slice/user-1000.slice/session-2.scope",
+			expected: "",
+		},
+		{
+			name:     "malformed content",
+			cgroup:   "not a cgroup file",
+			expected: "",
+		},
+		{
+			name:     "empty content",
+			cgroup:   "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, host.ParseContainerID([]byte(tt.cgroup)))
+		})
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ShouldReadTheCgroupOfTheProcess", func(t *testing.T) {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().ReadFile("/proc/42/cgroup").Return([]byte("0::/docker/"+containerID), nil)
+
+		assert.Equal(t, containerID, host.ContainerID(system, 42))
+	})
+
+	t.Run("ShouldReturnEmptyWhenTheProcessIsGone", func(t *testing.T) {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().ReadFile("/proc/42/cgroup").Return(nil, os.ErrNotExist)
+
+		assert.Empty(t, host.ContainerID(system, 42))
+	})
+}
+
+func TestFindWriterPID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	proc := fstest.MapFS{
+		"self/fd/0":  &fstest.MapFile{},
+		"100/fd/0":   &fstest.MapFile{},
+		"100/fd/1":   &fstest.MapFile{},
+		"200/fd/3":   &fstest.MapFile{},
+		"300/status": &fstest.MapFile{},
+	}
+	links := map[string]string{
+		"/proc/self/fd/0": "/var/log/app.log",
+		"/proc/100/fd/0":  "/dev/null",
+		"/proc/100/fd/1":  "/var/log/other.log",
+		"/proc/200/fd/3":  "/var/log/app.log",
+	}
+
+	newSystem := func() *host.MockSystem {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().ReadDir(gomock.Any()).AnyTimes().DoAndReturn(func(name string) ([]fs.DirEntry, error) {
+			rel, _ := filepath.Rel("/proc", name)
+			return fs.ReadDir(proc, rel)
+		})
+		system.EXPECT().Readlink(gomock.Any()).AnyTimes().DoAndReturn(func(name string) (string, error) {
+			if target, ok := links[name]; ok {
+				return target, nil
+			}
+			return "", os.ErrNotExist
+		})
+		return system
+	}
+
+	t.Run("ShouldFindTheProcessHoldingTheFile", func(t *testing.T) {
+		pid, ok := host.FindWriterPID(newSystem(), "/var/log/app.log")
+		assert.True(t, ok)
+		assert.Equal(t, 200, pid)
+	})
+
+	t.Run("ShouldReturnFalseWhenNoProcessHoldsTheFile", func(t *testing.T) {
+		_, ok := host.FindWriterPID(newSystem(), "/var/log/missing.log")
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldReturnFalseWhenProcIsNotReadable", func(t *testing.T) {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().ReadDir("/proc").Return(nil, errors.New("permission denied"))
+
+		_, ok := host.FindWriterPID(system, "/var/log/app.log")
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldCacheTheFoundProcess", func(t *testing.T) {
+		resolve := host.FileWriterResolver(newSystem(), "/var/log/app.log", time.Minute)
+
+		pid, ok := resolve()
+		assert.True(t, ok)
+		assert.Equal(t, 200, pid)
+
+		// drop the process, the resolver must keep the first result
+		delete(links, "/proc/200/fd/3")
+		pid, ok = resolve()
+		assert.True(t, ok)
+		assert.Equal(t, 200, pid)
+	})
+
+	t.Run("ShouldNotRetryBeforeTheInterval", func(t *testing.T) {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().ReadDir("/proc").Times(1).Return(nil, errors.New("permission denied"))
+
+		resolve := host.FileWriterResolver(system, "/var/log/app.log", time.Minute)
+
+		_, ok := resolve()
+		assert.False(t, ok)
+		_, ok = resolve()
+		assert.False(t, ok)
+	})
+}
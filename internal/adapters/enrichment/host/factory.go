@@ -0,0 +1,14 @@
+package host
+
+import (
+	"io/fs"
+	"os"
+)
+
+type OSSystem struct{}
+
+func (OSSystem) Hostname() (string, error)                  { return os.Hostname() }
+func (OSSystem) Getenv(key string) string                   { return os.Getenv(key) }
+func (OSSystem) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (OSSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSSystem) Readlink(name string) (string, error)       { return os.Readlink(name) }
@@ -0,0 +1,114 @@
+package host
+
+import (
+	"log-guardian/internal/core/domain"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+const (
+	MetadataHostname     = "hostname"
+	MetadataNodeName     = "node_name"
+	MetadataOS           = "os"
+	MetadataAgentVersion = "agent_version"
+	MetadataContainerID  = "container_id"
+	MetadataPID          = domain.METADATA_PID
+
+	envNodeName = "NODE_NAME"
+)
+
+// PIDResolver discovers the process producing the logs of an input
+type PIDResolver func() (int, bool)
+
+type HostEnricher struct {
+	system      System
+	static      map[string]string
+	containerID bool
+	resolvePID  PIDResolver
+	containers  map[int]string
+	mu          sync.Mutex
+}
+
+// NewHostEnricher creates an enricher with the host identity. When containerID is enabled the
+// container is looked up from the "pid" metadata of the event or, when absent, from resolvePID
+func NewHostEnricher(system System, version string, containerID bool, resolvePID PIDResolver) *HostEnricher {
+	static := map[string]string{
+		MetadataOS:           runtime.GOOS,
+		MetadataAgentVersion: version,
+	}
+
+	if hostname, err := system.Hostname(); err == nil && hostname != "" {
+		static[MetadataHostname] = hostname
+	}
+
+	if nodeName := system.Getenv(envNodeName); nodeName != "" {
+		static[MetadataNodeName] = nodeName
+	}
+
+	return &HostEnricher{
+		system:      system,
+		static:      static,
+		containerID: containerID,
+		resolvePID:  resolvePID,
+		containers:  make(map[int]string),
+	}
+}
+
+// Enrich adds the host metadata to the event, keeping the values already present
+func (h *HostEnricher) Enrich(event *domain.LogEvent) {
+	for key, value := range h.static {
+		if _, ok := event.GetMetadata(key); !ok {
+			event.AddMetadata(key, value)
+		}
+	}
+
+	if !h.containerID {
+		return
+	}
+
+	if _, ok := event.GetMetadata(MetadataContainerID); ok {
+		return
+	}
+
+	pid, ok := h.pid(*event)
+	if !ok {
+		return
+	}
+
+	if id := h.container(pid); id != "" {
+		event.AddMetadata(MetadataContainerID, id)
+	}
+}
+
+func (h *HostEnricher) pid(event domain.LogEvent) (int, bool) {
+	if value, ok := event.GetMetadata(MetadataPID); ok {
+		switch pid := value.(type) {
+		case int:
+			return pid, true
+		case string:
+			parsed, err := strconv.Atoi(pid)
+			return parsed, err == nil
+		}
+	}
+
+	if h.resolvePID == nil {
+		return 0, false
+	}
+
+	return h.resolvePID()
+}
+
+// container returns the cached container ID of the process, reading the cgroup on the first call
+func (h *HostEnricher) container(pid int) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id, ok := h.containers[pid]
+	if !ok {
+		id = ContainerID(h.system, pid)
+		h.containers[pid] = id
+	}
+
+	return id
+}
@@ -0,0 +1,125 @@
+package host_test
+
+import (
+	"errors"
+	"log-guardian/internal/adapters/enrichment/host"
+	"log-guardian/internal/core/domain"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHostEnricher_Enrich(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newSystem := func() *host.MockSystem {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().Hostname().Return("worker-1", nil)
+		system.EXPECT().Getenv("NODE_NAME").Return("node-a")
+		return system
+	}
+
+	t.Run("ShouldAddTheHostIdentity", func(t *testing.T) {
+		enricher := host.NewHostEnricher(newSystem(), "1.2.3", false, nil)
+
+		event := domain.LogEvent{Source: domain.SOURCE_STDIN, Message: "hello"}
+		enricher.Enrich(&event)
+
+		assert.Equal(t, map[string]interface{}{
+			host.MetadataHostname:     "worker-1",
+			host.MetadataNodeName:     "node-a",
+			host.MetadataOS:           runtime.GOOS,
+			host.MetadataAgentVersion: "1.2.3",
+		}, event.Metadata)
+	})
+
+	t.Run("ShouldSkipUnknownHostnameAndNodeName", func(t *testing.T) {
+		system := host.NewMockSystem(ctrl)
+		system.EXPECT().Hostname().Return("", errors.New("no hostname"))
+		system.EXPECT().Getenv("NODE_NAME").Return("")
+
+		enricher := host.NewHostEnricher(system, "1.2.3", false, nil)
+
+		event := domain.LogEvent{}
+		enricher.Enrich(&event)
+
+		_, ok := event.GetMetadata(host.MetadataHostname)
+		assert.False(t, ok)
+		_, ok = event.GetMetadata(host.MetadataNodeName)
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldKeepExistingMetadata", func(t *testing.T) {
+		enricher := host.NewHostEnricher(newSystem(), "1.2.3", false, nil)
+
+		event := domain.LogEvent{Metadata: map[string]interface{}{host.MetadataHostname: "original"}}
+		enricher.Enrich(&event)
+
+		assert.Equal(t, "original", event.Metadata[host.MetadataHostname])
+	})
+
+	t.Run("ShouldAddTheContainerFromTheEventPID", func(t *testing.T) {
+		system := newSystem()
+		// the cgroup is read only once per process
+		system.EXPECT().ReadFile("/proc/42/cgroup").Times(1).Return([]byte("0::/docker/"+containerID), nil)
+
+		enricher := host.NewHostEnricher(system, "1.2.3", true, nil)
+
+		for range 2 {
+			event := domain.LogEvent{Metadata: map[string]interface{}{host.MetadataPID: 42}}
+			enricher.Enrich(&event)
+
+			assert.Equal(t, containerID, event.Metadata[host.MetadataContainerID])
+		}
+	})
+
+	t.Run("ShouldAddTheContainerFromTheResolver", func(t *testing.T) {
+		system := newSystem()
+		system.EXPECT().ReadFile("/proc/7/cgroup").Return([]byte("0::/docker/"+containerID), nil)
+
+		enricher := host.NewHostEnricher(system, "1.2.3", true, func() (int, bool) { return 7, true })
+
+		event := domain.LogEvent{}
+		enricher.Enrich(&event)
+
+		assert.Equal(t, containerID, event.Metadata[host.MetadataContainerID])
+	})
+
+	t.Run("ShouldAcceptStringPID", func(t *testing.T) {
+		system := newSystem()
+		system.EXPECT().ReadFile("/proc/9/cgroup").Return([]byte("0::/docker/"+containerID), nil)
+
+		enricher := host.NewHostEnricher(system, "1.2.3", true, nil)
+
+		event := domain.LogEvent{Metadata: map[string]interface{}{host.MetadataPID: "9"}}
+		enricher.Enrich(&event)
+
+		assert.Equal(t, containerID, event.Metadata[host.MetadataContainerID])
+	})
+
+	t.Run("ShouldSkipTheContainerWhenThePIDIsUnknown", func(t *testing.T) {
+		enricher := host.NewHostEnricher(newSystem(), "1.2.3", true, func() (int, bool) { return 0, false })
+
+		event := domain.LogEvent{}
+		enricher.Enrich(&event)
+
+		_, ok := event.GetMetadata(host.MetadataContainerID)
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldSkipTheContainerWhenTheProcessIsNotContainerized", func(t *testing.T) {
+		system := newSystem()
+		system.EXPECT().ReadFile("/proc/42/cgroup").Return([]byte("0::/user.slice"), nil)
+
+		enricher := host.NewHostEnricher(system, "1.2.3", true, nil)
+
+		event := domain.LogEvent{Metadata: map[string]interface{}{host.MetadataPID: 42}}
+		enricher.Enrich(&event)
+
+		_, ok := event.GetMetadata(host.MetadataContainerID)
+		assert.False(t, ok)
+	})
+}
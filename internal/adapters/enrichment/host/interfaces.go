@@ -0,0 +1,14 @@
+package host
+
+import "io/fs"
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+// System abstracts the operating system calls used to describe the host
+type System interface {
+	Hostname() (string, error)
+	Getenv(key string) string
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Readlink(name string) (string, error)
+}
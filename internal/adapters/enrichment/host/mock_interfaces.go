@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+//
+// Generated by this command:
+//
+//	mockgen -source=interfaces.go -destination=mock_interfaces.go -package=host
+//
+
+// Package host is a generated GoMock package.
+package host
+
+import (
+	fs "io/fs"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSystem is a mock of System interface.
+type MockSystem struct {
+	ctrl     *gomock.Controller
+	recorder *MockSystemMockRecorder
+	isgomock struct{}
+}
+
+// MockSystemMockRecorder is the mock recorder for MockSystem.
+type MockSystemMockRecorder struct {
+	mock *MockSystem
+}
+
+// NewMockSystem creates a new mock instance.
+func NewMockSystem(ctrl *gomock.Controller) *MockSystem {
+	mock := &MockSystem{ctrl: ctrl}
+	mock.recorder = &MockSystemMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSystem) EXPECT() *MockSystemMockRecorder {
+	return m.recorder
+}
+
+// Getenv mocks base method.
+func (m *MockSystem) Getenv(key string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Getenv", key)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Getenv indicates an expected call of Getenv.
+func (mr *MockSystemMockRecorder) Getenv(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Getenv", reflect.TypeOf((*MockSystem)(nil).Getenv), key)
+}
+
+// Hostname mocks base method.
+func (m *MockSystem) Hostname() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hostname")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hostname indicates an expected call of Hostname.
+func (mr *MockSystemMockRecorder) Hostname() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hostname", reflect.TypeOf((*MockSystem)(nil).Hostname))
+}
+
+// ReadDir mocks base method.
+func (m *MockSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDir", name)
+	ret0, _ := ret[0].([]fs.DirEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDir indicates an expected call of ReadDir.
+func (mr *MockSystemMockRecorder) ReadDir(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDir", reflect.TypeOf((*MockSystem)(nil).ReadDir), name)
+}
+
+// ReadFile mocks base method.
+func (m *MockSystem) ReadFile(name string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFile", name)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFile indicates an expected call of ReadFile.
+func (mr *MockSystemMockRecorder) ReadFile(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockSystem)(nil).ReadFile), name)
+}
+
+// Readlink mocks base method.
+func (m *MockSystem) Readlink(name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readlink", name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Readlink indicates an expected call of Readlink.
+func (mr *MockSystemMockRecorder) Readlink(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readlink", reflect.TypeOf((*MockSystem)(nil).Readlink), name)
+}
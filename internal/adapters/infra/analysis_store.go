@@ -0,0 +1,34 @@
+package infra
+
+import (
+	"log-guardian/internal/core/domain"
+)
+
+// FileAnalysisStore keeps the cached analyses in a JSON file, replaced atomically on each save
+type FileAnalysisStore struct {
+	file *watchedFile
+}
+
+func NewFileAnalysisStore(path string) *FileAnalysisStore {
+	return &FileAnalysisStore{file: &watchedFile{path: path}}
+}
+
+// Load returns the saved analyses, none when the file doesn't exist yet
+func (s *FileAnalysisStore) Load() ([]domain.CachedAnalysis, error) {
+	var analyses []domain.CachedAnalysis
+	if err := s.file.read(&analyses); err != nil {
+		return nil, err
+	}
+
+	return analyses, nil
+}
+
+func (s *FileAnalysisStore) Save(analyses []domain.CachedAnalysis) error {
+	return s.file.write(analyses)
+}
+
+// Changed reports whether another process saved or removed the file since it was last loaded or
+// saved by this store
+func (s *FileAnalysisStore) Changed() (bool, error) {
+	return s.file.changed()
+}
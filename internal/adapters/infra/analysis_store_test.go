@@ -0,0 +1,85 @@
+package infra_test
+
+import (
+	"log-guardian/internal/adapters/infra"
+	"log-guardian/internal/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileAnalysisStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "analyses.json")
+	store := infra.NewFileAnalysisStore(path)
+
+	analyses, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, analyses, "a missing file holds no analyses")
+
+	cachedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	saved := []domain.CachedAnalysis{{
+		Key:         "0a1b2c3d:abc",
+		Fingerprint: "abc",
+		Analysis: domain.Analysis{
+			Summary:       "db down",
+			ProbableCause: "postgres refuses connections",
+			Confidence:    0.8,
+			Provider:      "openai",
+			Usage:         domain.TokenUsage{Prompt: 100, Completion: 20},
+			CreatedAt:     cachedAt,
+		},
+		CachedAt:  cachedAt,
+		ExpiresAt: cachedAt.Add(24 * time.Hour),
+		Hits:      3,
+	}}
+	require.NoError(t, store.Save(saved))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, saved, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestFileAnalysisStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analyses.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := infra.NewFileAnalysisStore(path).Load()
+	assert.Error(t, err)
+}
+
+func TestFileAnalysisStore_Changed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analyses.json")
+	store := infra.NewFileAnalysisStore(path)
+	other := infra.NewFileAnalysisStore(path)
+
+	_, err := store.Load()
+	require.NoError(t, err)
+	changed, err := store.Changed()
+	require.NoError(t, err)
+	assert.False(t, changed, "nothing is saved yet")
+
+	require.NoError(t, store.Save([]domain.CachedAnalysis{{Key: "t:abc"}}))
+	changed, err = store.Changed()
+	require.NoError(t, err)
+	assert.False(t, changed, "the store's own save isn't a change")
+
+	require.NoError(t, other.Save(nil))
+	changed, err = store.Changed()
+	require.NoError(t, err)
+	assert.True(t, changed, "another process saved the file")
+
+	_, err = store.Load()
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+	changed, err = store.Changed()
+	require.NoError(t, err)
+	assert.True(t, changed, "the file was removed")
+}
@@ -0,0 +1,36 @@
+package infra
+
+import (
+	"log-guardian/internal/core/domain"
+)
+
+// FileIncidentStore keeps the incidents in a JSON file, replaced atomically on each save
+type FileIncidentStore struct {
+	file *watchedFile
+}
+
+func NewFileIncidentStore(path string) *FileIncidentStore {
+	return &FileIncidentStore{file: &watchedFile{path: path}}
+}
+
+// Load returns the saved incidents, none when the file doesn't exist yet
+func (s *FileIncidentStore) Load() ([]domain.Incident, error) {
+	var incidents []domain.Incident
+	if err := s.file.read(&incidents); err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+// Save writes the incidents to a temporary file renamed over the previous one, so a crash never
+// leaves a truncated file behind
+func (s *FileIncidentStore) Save(incidents []domain.Incident) error {
+	return s.file.write(incidents)
+}
+
+// Changed reports whether another process saved or removed the file since it was last loaded or
+// saved by this store
+func (s *FileIncidentStore) Changed() (bool, error) {
+	return s.file.changed()
+}
@@ -0,0 +1,53 @@
+package infra_test
+
+import (
+	"log-guardian/internal/adapters/infra"
+	"log-guardian/internal/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileIncidentStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "incidents.json")
+	store := infra.NewFileIncidentStore(path)
+
+	incidents, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, incidents, "a missing file holds no incidents")
+
+	opened := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	saved := []domain.Incident{{
+		ID:           "1",
+		Service:      "payments",
+		Status:       domain.INCIDENT_STATUS_OPEN,
+		Severity:     domain.LOG_LEVEL_ERROR,
+		Fingerprints: []string{"abc"},
+		OpenedAt:     opened,
+		UpdatedAt:    opened,
+		Timeline:     []domain.TimelineEntry{{At: opened, Kind: domain.TIMELINE_STATUS, Message: "open"}},
+	}}
+
+	require.NoError(t, store.Save(saved))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, saved, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is renamed")
+}
+
+func TestFileIncidentStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := infra.NewFileIncidentStore(path).Load()
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// watchedFile reads and writes a JSON file, telling whether another process replaced it since it
+// was last read or written. Every write replaces the file with a new one
+type watchedFile struct {
+	path string
+
+	mu   sync.Mutex
+	seen os.FileInfo
+}
+
+// read decodes the file into v, it is left as is when the file doesn't exist yet
+func (f *watchedFile) read(v any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the file is looked at before it is read, a write made in between is found by changed
+	seen, err := f.stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := readJSON(f.path, v); err != nil {
+		return err
+	}
+	f.seen = seen
+
+	return nil
+}
+
+func (f *watchedFile) write(v any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := writeJSON(f.path, v); err != nil {
+		return err
+	}
+
+	seen, err := f.stat()
+	if err != nil {
+		return err
+	}
+	f.seen = seen
+
+	return nil
+}
+
+// changed reports whether the file was written or removed by someone else since it was last read
+// or written
+func (f *watchedFile) changed() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, err := f.stat()
+	if err != nil {
+		return false, err
+	}
+
+	if current == nil || f.seen == nil {
+		return current != f.seen, nil
+	}
+
+	return !os.SameFile(current, f.seen), nil
+}
+
+// stat returns nil when the file doesn't exist
+func (f *watchedFile) stat() (os.FileInfo, error) {
+	info, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return info, err
+}
+
+// readJSON decodes the file into v, it returns false when the file doesn't exist yet
+func readJSON(path string, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+// writeJSON writes v to a temporary file renamed over the previous one, so a crash never leaves a
+// truncated file behind
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
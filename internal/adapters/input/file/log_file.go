@@ -125,6 +125,7 @@ func (lf *LogFileIngestion) handleWrite(reader *bufio.Reader, output chan<- doma
 }
 
 func (lf *LogFileIngestion) emit(msg string, output chan<- domain.LogEvent) {
-	event, _ := domain.NewLogEvent(domain.SOURCE_FILE, msg, domain.LOG_LEVEL_INFO, nil, lf.idGen)
+	metadata := map[string]interface{}{domain.METADATA_INPUT: lf.filePath}
+	event, _ := domain.NewLogEvent(domain.SOURCE_FILE, msg, domain.MessageLogLevel(msg), metadata, lf.idGen)
 	output <- *event
 }
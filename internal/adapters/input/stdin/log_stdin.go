@@ -34,7 +34,7 @@ func (i *StdinIngestion) Read(ctx context.Context, output chan<- domain.LogEvent
 				continue
 			}
 
-			event, _ := domain.NewLogEvent(domain.SOURCE_STDIN, line, domain.LOG_LEVEL_INFO, nil, i.idGen)
+			event, _ := domain.NewLogEvent(domain.SOURCE_STDIN, line, domain.MessageLogLevel(line), nil, i.idGen)
 
 			select {
 			case <-ctx.Done():
@@ -24,6 +24,7 @@ type UnixIngestion struct {
 	connectionProvider ConnectionProvider
 	idGen              domain.IDGenerator
 	maxMessageSize     int
+	peerPID            int
 }
 
 func NewUnixIngestion(connectionProvider ConnectionProvider, idGen domain.IDGenerator, socketPath string, timeout time.Duration) *UnixIngestion {
@@ -56,6 +57,10 @@ func (u *UnixIngestion) Run(ctx context.Context, output chan<- domain.LogEvent,
 	}
 	defer connection.Close()
 
+	if pid, ok := peerPID(connection); ok {
+		u.peerPID = pid
+	}
+
 	reader := bufio.NewReaderSize(connection, initialBufSize)
 
 	for {
@@ -98,7 +103,12 @@ func (u *UnixIngestion) SendError(ctx context.Context, err error, errChan chan<-
 }
 
 func (u *UnixIngestion) Emit(ctx context.Context, msg string, output chan<- domain.LogEvent) {
-	event, _ := domain.NewLogEvent(domain.SOURCE_UNIX, msg, domain.LOG_LEVEL_INFO, nil, u.idGen)
+	metadata := map[string]interface{}{domain.METADATA_INPUT: u.socketPath}
+	if u.peerPID > 0 {
+		metadata[domain.METADATA_PID] = u.peerPID
+	}
+
+	event, _ := domain.NewLogEvent(domain.SOURCE_UNIX, msg, domain.MessageLogLevel(msg), metadata, u.idGen)
 
 	select {
 	case <-ctx.Done():
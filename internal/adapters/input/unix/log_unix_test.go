@@ -140,6 +140,24 @@ func TestUnixIngestion_Read(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "ShouldReadTheLevelWrittenInTheLine",
+			socketPath: validSocketPath,
+			input:      "FATAL out of memory\nlevel=warn cache miss\n",
+			useNetPipe: true,
+			expectedOutput: []domain.LogEvent{
+				{
+					Source:   domain.SOURCE_UNIX,
+					Severity: domain.LOG_LEVEL_FATAL,
+					Message:  "FATAL out of memory",
+				},
+				{
+					Source:   domain.SOURCE_UNIX,
+					Severity: domain.LOG_LEVEL_WARNING,
+					Message:  "level=warn cache miss",
+				},
+			},
+		},
 	}
 
 	for _, c := range testCases {
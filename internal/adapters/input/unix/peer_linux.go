@@ -0,0 +1,35 @@
+//go:build linux
+
+package unix
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerPID returns the process ID on the other side of the socket using SO_PEERCRED
+func peerPID(conn Conn) (int, bool) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return 0, false
+	}
+
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+
+	var (
+		cred    *syscall.Ucred
+		credErr error
+	)
+
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil || cred.Pid <= 0 {
+		return 0, false
+	}
+
+	return int(cred.Pid), true
+}
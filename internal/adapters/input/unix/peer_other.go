@@ -0,0 +1,8 @@
+//go:build !linux
+
+package unix
+
+// peerPID is only supported on linux
+func peerPID(_ Conn) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultRetries = 3
+	defaultBackoff = time.Second
+	// maxRetryDelay bounds the delays asked by the webhooks, a webhook asking for an hour would
+	// hold the notifications queued behind it
+	maxRetryDelay = time.Minute
+	// maxErrorLength bounds the response body quoted in the errors
+	maxErrorLength = 200
+)
+
+// Client posts JSON to a webhook. The rate limited requests are retried after the delay asked by
+// the Retry-After or X-RateLimit-Reset-After header, the network errors and 5xx responses after an
+// exponential backoff. Once the X-RateLimit-Remaining header reaches zero, the next request waits
+// for the bucket of the webhook to reset instead of being rejected. The delays are capped to a minute
+type Client struct {
+	http    *http.Client
+	retries int
+	backoff time.Duration
+
+	mu       sync.Mutex
+	resumeAt time.Time
+}
+
+// NewClient returns a client retrying 3 times when retries is zero
+func NewClient(timeout time.Duration, retries int, backoff time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if retries <= 0 {
+		retries = defaultRetries
+	}
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	return &Client{
+		http:    &http.Client{Timeout: timeout},
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+// Post sends the body to the webhook until it is accepted or the retries are spent
+func (c *Client) Post(ctx context.Context, url string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrNotificationFailed, err)
+	}
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		if err := c.waitReset(ctx); err != nil {
+			return err
+		}
+
+		delay, retry, err := c.send(ctx, url, payload)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= c.retries {
+			return err
+		}
+
+		if delay == 0 {
+			delay = wait
+			wait *= 2
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", domain.ErrNotificationFailed, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send makes one attempt, it returns whether to retry and the delay asked by the webhook before
+// the next attempt
+func (c *Client) send(ctx context.Context, url string, payload []byte) (time.Duration, bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", domain.ErrNotificationFailed, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return 0, ctx.Err() == nil, fmt.Errorf("%w: %w", domain.ErrNotificationFailed, err)
+	}
+	defer response.Body.Close()
+	c.track(response.Header)
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorLength))
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return 0, false, nil
+	}
+
+	err = fmt.Errorf("%w: status %d: %s", domain.ErrNotificationFailed, response.StatusCode, strings.TrimSpace(string(message)))
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return retryAfter(response.Header), true, err
+	case response.StatusCode >= 500:
+		return 0, true, err
+	}
+
+	return 0, false, err
+}
+
+// waitReset waits until the webhook accepts requests again
+func (c *Client) waitReset(ctx context.Context) error {
+	c.mu.Lock()
+	delay := time.Until(c.resumeAt)
+	c.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", domain.ErrNotificationFailed, ctx.Err())
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// track remembers when the webhook accepts requests again once its requests left reach zero
+func (c *Client) track(header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resumeAt = time.Now().Add(seconds(header.Get("X-RateLimit-Reset-After")))
+}
+
+// retryAfter reads the delay before the next attempt, from Retry-After or X-RateLimit-Reset-After
+func retryAfter(header http.Header) time.Duration {
+	if delay := seconds(header.Get("Retry-After")); delay > 0 {
+		return delay
+	}
+
+	return seconds(header.Get("X-RateLimit-Reset-After"))
+}
+
+// seconds parses a delay header given in seconds, possibly with a fraction, up to maxRetryDelay
+func seconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return min(time.Duration(seconds*float64(time.Second)), maxRetryDelay)
+}
@@ -0,0 +1,114 @@
+package notify_test
+
+import (
+	"context"
+	"log-guardian/internal/adapters/notify"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Post(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		retries          int
+		expectedAttempts int32
+		expectedError    error
+	}{
+		{name: "ShouldPost", statuses: []int{http.StatusOK}, expectedAttempts: 1},
+		{name: "ShouldRetryServerErrors", statuses: []int{http.StatusBadGateway, http.StatusOK}, expectedAttempts: 2},
+		{name: "ShouldRetryRateLimited", statuses: []int{http.StatusTooManyRequests, http.StatusNoContent}, expectedAttempts: 2},
+		{
+			name:             "ShouldNotRetryRejected",
+			statuses:         []int{http.StatusBadRequest, http.StatusOK},
+			expectedAttempts: 1,
+			expectedError:    domain.ErrNotificationFailed,
+		},
+		{
+			name:             "ShouldFailWhenRetriesAreSpent",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			retries:          2,
+			expectedAttempts: 3,
+			expectedError:    domain.ErrNotificationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				w.WriteHeader(tt.statuses[attempts.Add(1)-1])
+			}))
+			defer server.Close()
+
+			client := notify.NewClient(time.Second, tt.retries, time.Millisecond)
+			err := client.Post(context.Background(), server.URL, map[string]string{"text": "hello"})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_ShouldWaitRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	err := notify.NewClient(time.Second, 1, time.Millisecond).Post(context.Background(), server.URL, struct{}{})
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestClient_ShouldWaitBucketReset(t *testing.T) {
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, time.Now())
+		w.Header().Set("X-RateLimit-Limit", "5")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "0.2")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := notify.NewClient(time.Second, 0, time.Millisecond)
+
+	assert.NoError(t, client.Post(context.Background(), server.URL, struct{}{}))
+	assert.NoError(t, client.Post(context.Background(), server.URL, struct{}{}))
+
+	assert.Len(t, requests, 2)
+	assert.GreaterOrEqual(t, requests[1].Sub(requests[0]), 200*time.Millisecond, "the second request waits for the bucket to reset")
+}
+
+func TestClient_ShouldStopWaitingOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "60")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := notify.NewClient(time.Second, 0, time.Millisecond)
+	assert.NoError(t, client.Post(context.Background(), server.URL, struct{}{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, client.Post(ctx, server.URL, struct{}{}), domain.ErrNotificationFailed)
+}
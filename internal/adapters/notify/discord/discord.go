@@ -0,0 +1,182 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/adapters/notify"
+	"log-guardian/internal/core/domain"
+	"strings"
+	"time"
+)
+
+// Embed limits, https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxFields            = 25
+	maxFieldNameLength   = 256
+	maxFieldValueLength  = 1024
+	maxEmbedLength       = 6000
+	// maxSummaryLength leaves the rest of the description to the log excerpt
+	maxSummaryLength = 2048
+	// excerptRoom is kept for the log excerpt while the other parts of the embed are added
+	excerptRoom = 1000
+)
+
+// Notifier posts the alerts and the incidents to a Discord webhook as an embed coloured by
+// severity. The log excerpt is shortened to keep the embed within the Discord limits, and the
+// mentions in the logs never ping anyone
+type Notifier struct {
+	config domain.NotifierConfig
+	url    string
+	client *notify.Client
+}
+
+type payload struct {
+	Username        string          `json:"username,omitempty"`
+	AvatarURL       string          `json:"avatar_url,omitempty"`
+	Embeds          []embed         `json:"embeds"`
+	AllowedMentions allowedMentions `json:"allowed_mentions"`
+}
+
+type embed struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color"`
+	Fields      []field `json:"fields,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+type field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+func NewNotifier(config domain.NotifierConfig) (*Notifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	url, err := config.URL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{
+		config: config,
+		url:    url,
+		client: notify.NewClient(config.Timeout, config.MaxRetries, 0),
+	}, nil
+}
+
+func (n *Notifier) Name() string {
+	return n.config.NotifierName()
+}
+
+func (n *Notifier) NotifyAlert(ctx context.Context, alert domain.Alert) error {
+	if !n.config.AcceptsAlert(alert) {
+		return nil
+	}
+
+	return n.client.Post(ctx, n.url, n.payload(notify.AlertMessage(alert)))
+}
+
+func (n *Notifier) NotifyIncident(ctx context.Context, incident domain.Incident) error {
+	if !n.config.AcceptsIncident(incident) {
+		return nil
+	}
+
+	return n.client.Post(ctx, n.url, n.payload(notify.IncidentMessage(incident)))
+}
+
+func (n *Notifier) payload(m notify.Message) payload {
+	e := embed{Color: m.Color()}
+	if !m.At.IsZero() {
+		e.Timestamp = m.At.Format(time.RFC3339)
+	}
+
+	// the parts are added by importance, each within what the previous ones left of the embed,
+	// keeping some room for the log excerpt added last
+	left := maxEmbedLength
+	if m.Sample != "" {
+		left -= excerptRoom
+	}
+
+	fit := func(text string, limit int) string {
+		if limit = min(limit, left); limit < 1 {
+			return ""
+		}
+
+		text = notify.Truncate(text, limit)
+		left -= len([]rune(text))
+
+		return text
+	}
+
+	e.Title = fit(m.Title, maxTitleLength)
+
+	analysis := fitFields(analysisFields(m.Analysis), false, fit)
+	summary := fit(m.Summary, maxSummaryLength)
+	fields := fitFields(m.Fields, true, fit)
+	e.Fields = append(fields[:min(len(fields), maxFields-len(analysis))], analysis...)
+	e.Description = summary
+
+	if m.Sample != "" {
+		left += excerptRoom
+
+		fenced := "\n```\n%s\n```"
+		if summary == "" {
+			fenced = "```\n%s\n```"
+		}
+
+		budget := min(maxDescriptionLength-len([]rune(summary)), left) - len([]rune(fmt.Sprintf(fenced, "")))
+		if budget > 0 {
+			sample := notify.Excerpt(strings.ReplaceAll(m.Sample, "```", "'''"), budget)
+			e.Description = summary + fmt.Sprintf(fenced, sample)
+		}
+	}
+
+	return payload{
+		Username:        n.config.Discord.Username,
+		AvatarURL:       n.config.Discord.AvatarURL,
+		Embeds:          []embed{e},
+		AllowedMentions: allowedMentions{Parse: []string{}},
+	}
+}
+
+// fitFields keeps the fields fitting in the embed, Discord rejects the fields without name or value
+func fitFields(fields []notify.Field, inline bool, fit func(string, int) string) []field {
+	var fitted []field
+	for _, f := range fields {
+		if f.Name == "" || f.Value == "" || len(fitted) >= maxFields {
+			continue
+		}
+
+		name := fit(f.Name, maxFieldNameLength)
+		value := fit(f.Value, maxFieldValueLength)
+		if name == "" || value == "" {
+			break
+		}
+
+		fitted = append(fitted, field{Name: name, Value: value, Inline: inline})
+	}
+
+	return fitted
+}
+
+func analysisFields(a *domain.Analysis) []notify.Field {
+	if a == nil {
+		return nil
+	}
+
+	return []notify.Field{
+		{Name: fmt.Sprintf("AI analysis (%s, confidence %.0f%%)", a.Provider, a.Confidence*100), Value: a.Summary},
+		{Name: "Probable cause", Value: a.ProbableCause},
+		{Name: "Suggested fix", Value: a.SuggestedFix},
+	}
+}
@@ -0,0 +1,230 @@
+package discord_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log-guardian/internal/adapters/notify/discord"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var alert = domain.Alert{
+	Rule:     "db-errors",
+	State:    domain.ALERT_STATE_FIRING,
+	Severity: domain.LOG_LEVEL_FATAL,
+	Summary:  "5 errors in 1m",
+	Count:    5,
+	StartsAt: now,
+	Samples: []domain.LogEvent{{
+		Source:  domain.SOURCE_FILE,
+		Message: "@everyone connection refused",
+		Metadata: map[string]interface{}{
+			domain.METADATA_ANALYSIS: domain.Analysis{
+				Summary:       "Postgres is down",
+				ProbableCause: "The primary restarted",
+				Confidence:    0.8,
+				Provider:      "ollama",
+			},
+		},
+	}},
+}
+
+type embed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Color       int    `json:"color"`
+	Timestamp   string `json:"timestamp"`
+	Fields      []struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	} `json:"fields"`
+}
+
+type payload struct {
+	Username        string  `json:"username"`
+	Embeds          []embed `json:"embeds"`
+	AllowedMentions struct {
+		Parse []string `json:"parse"`
+	} `json:"allowed_mentions"`
+}
+
+// capture records the payloads posted to the webhook, the first responses use the handlers
+func capture(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *[]payload) {
+	t.Helper()
+
+	var payloads []payload
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var p payload
+		require.NoError(t, json.Unmarshal(body, &p))
+		payloads = append(payloads, p)
+
+		if attempt := int(attempts.Add(1)); attempt <= len(handlers) {
+			handlers[attempt-1](w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &payloads
+}
+
+func newNotifier(t *testing.T, config domain.NotifierConfig) *discord.Notifier {
+	t.Helper()
+
+	config.Type = domain.NOTIFIER_DISCORD
+	notifier, err := discord.NewNotifier(config)
+	require.NoError(t, err)
+
+	return notifier
+}
+
+func TestNotifier_NotifyAlert(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL, Discord: domain.DiscordConfig{Username: "guardian"}})
+
+	require.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+	require.Len(t, *payloads, 1)
+
+	p := (*payloads)[0]
+	assert.Equal(t, "guardian", p.Username)
+	assert.Equal(t, []string{}, p.AllowedMentions.Parse, "the mentions in the logs never ping")
+	require.Len(t, p.Embeds, 1)
+
+	e := p.Embeds[0]
+	assert.Equal(t, "[FIRING] db-errors", e.Title)
+	assert.Equal(t, 0x8b0000, e.Color)
+	assert.Equal(t, "2025-01-01T12:00:00Z", e.Timestamp)
+	assert.Equal(t, "5 errors in 1m\n```\n@everyone connection refused\n```", e.Description)
+
+	var names []string
+	for _, f := range e.Fields {
+		names = append(names, f.Name+"="+f.Value)
+	}
+	assert.Equal(t, []string{
+		"Severity=FATAL",
+		"Count=5",
+		"Source=file",
+		"AI analysis (ollama, confidence 80%)=Postgres is down",
+		"Probable cause=The primary restarted",
+	}, names)
+}
+
+func TestNotifier_NotifyIncident(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	err := notifier.NotifyIncident(context.Background(), domain.Incident{
+		Title:    "payments errors",
+		Status:   domain.INCIDENT_STATUS_OPEN,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Timeline: []domain.TimelineEntry{{Kind: domain.TIMELINE_EVENT, Message: "disk full"}},
+	})
+
+	require.NoError(t, err)
+	e := (*payloads)[0].Embeds[0]
+	assert.Equal(t, "```\ndisk full\n```", e.Description)
+	for _, f := range e.Fields {
+		assert.NotEmpty(t, f.Value, "the incident without service has no service field")
+	}
+}
+
+func TestNotifier_ShouldRespectLengthLimits(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	long := alert
+	long.Rule = strings.Repeat("r", 300)
+	long.Summary = strings.Repeat("s", 3000)
+	long.Labels = map[string]string{}
+	for i := range 30 {
+		long.Labels[strings.Repeat("k", i+1)] = strings.Repeat("v", 1500)
+	}
+	long.Samples = []domain.LogEvent{{Message: strings.Repeat("goroutine 1 [running]:\n\tmain.main()\n", 200)}}
+
+	require.NoError(t, notifier.NotifyAlert(context.Background(), long))
+
+	e := (*payloads)[0].Embeds[0]
+	total := len([]rune(e.Title)) + len([]rune(e.Description))
+	for _, f := range e.Fields {
+		assert.LessOrEqual(t, len([]rune(f.Name)), 256)
+		assert.LessOrEqual(t, len([]rune(f.Value)), 1024)
+		total += len([]rune(f.Name)) + len([]rune(f.Value))
+	}
+
+	assert.LessOrEqual(t, len([]rune(e.Title)), 256)
+	assert.LessOrEqual(t, len([]rune(e.Description)), 4096)
+	assert.LessOrEqual(t, len(e.Fields), 25)
+	assert.LessOrEqual(t, total, 6000)
+}
+
+func TestNotifier_ShouldShortenExcerpt(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	long := alert
+	long.Samples = []domain.LogEvent{{Message: strings.Repeat("goroutine 1 [running]:\n\tmain.main()\n", 200)}}
+
+	require.NoError(t, notifier.NotifyAlert(context.Background(), long))
+
+	description := (*payloads)[0].Embeds[0].Description
+	assert.LessOrEqual(t, len([]rune(description)), 4096)
+	assert.Contains(t, description, "more characters\n```")
+	assert.True(t, strings.HasPrefix(description, "5 errors in 1m\n```\ngoroutine 1 [running]:\n"))
+}
+
+func TestNotifier_ShouldRetryRateLimited(t *testing.T) {
+	server, payloads := capture(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Bucket", "abcd")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "0.3")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.3, "global": false}`))
+	})
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	start := time.Now()
+	require.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+
+	assert.Len(t, *payloads, 2)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
+
+func TestNotifier_ShouldSkipBelowMinSeverity(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL, MinSeverity: domain.LOG_LEVEL_FATAL})
+
+	assert.NoError(t, notifier.NotifyIncident(context.Background(), domain.Incident{Severity: domain.LOG_LEVEL_ERROR}))
+	assert.Empty(t, *payloads)
+}
+
+func TestNotifier_ShouldReportRejectedMessage(t *testing.T) {
+	server, _ := capture(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message": "Invalid Form Body", "code": 50035}`))
+	})
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	err := notifier.NotifyAlert(context.Background(), alert)
+
+	assert.ErrorIs(t, err, domain.ErrNotificationFailed)
+	assert.ErrorContains(t, err, "Invalid Form Body")
+}
@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// contextKeys are the metadata locating where the event comes from, in the order they are shown
+var contextKeys = []string{domain.METADATA_INPUT, "hostname", "namespace", "pod", "container"}
+
+// severityColors are the colours of the notifications by severity, the resolved ones are green
+var severityColors = map[domain.LogLevel]int{
+	domain.LOG_LEVEL_FATAL:   0x8b0000,
+	domain.LOG_LEVEL_ERROR:   0xe01e5a,
+	domain.LOG_LEVEL_WARNING: 0xecb22e,
+	domain.LOG_LEVEL_INFO:    0x36c5f0,
+	domain.LOG_LEVEL_DEBUG:   0x9e9e9e,
+}
+
+const resolvedColor = 0x2eb67d
+
+// Message is the content of a notification, each channel renders it in its own format
+type Message struct {
+	Title    string
+	Summary  string
+	Severity domain.LogLevel
+	Resolved bool
+	Fields   []Field
+	Sample   string
+	Analysis *domain.Analysis
+	At       time.Time
+}
+
+// Field is a labelled value shown with the notification
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Color returns the colour of the message as 0xRRGGBB
+func (m Message) Color() int {
+	if m.Resolved {
+		return resolvedColor
+	}
+
+	return severityColors[m.Severity]
+}
+
+// AlertMessage describes the alert with the context, the message and the analysis of its latest sample
+func AlertMessage(alert domain.Alert) Message {
+	m := Message{
+		Title:    fmt.Sprintf("[%s] %s", strings.ToUpper(string(alert.State)), alert.Rule),
+		Summary:  alert.Summary,
+		Severity: alert.Severity,
+		Resolved: alert.State == domain.ALERT_STATE_RESOLVED,
+		At:       alert.StartsAt,
+		Fields: []Field{
+			{Name: "Severity", Value: string(alert.Severity)},
+			{Name: "Count", Value: fmt.Sprint(alert.Count)},
+		},
+	}
+
+	if m.Resolved {
+		m.At = alert.EndsAt
+	}
+
+	keys := make([]string, 0, len(alert.Labels))
+	for key := range alert.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		m.Fields = append(m.Fields, Field{Name: key, Value: alert.Labels[key]})
+	}
+
+	m.describe(alert.Samples, alert.Labels)
+
+	return m
+}
+
+// IncidentMessage describes the incident with the context and the message of its latest event, and
+// the analysis of the incident or else of its latest analyzed event
+func IncidentMessage(incident domain.Incident) Message {
+	m := Message{
+		Title:    fmt.Sprintf("[%s] %s", strings.ToUpper(string(incident.Status)), incident.Title),
+		Severity: incident.Severity,
+		Resolved: incident.Status == domain.INCIDENT_STATUS_RESOLVED,
+		At:       incident.UpdatedAt,
+		Fields: []Field{
+			{Name: "Severity", Value: string(incident.Severity)},
+			{Name: "Service", Value: incident.Service},
+			{Name: "Events", Value: fmt.Sprint(incident.EventCount)},
+			{Name: "Alerts", Value: fmt.Sprint(incident.AlertCount)},
+			{Name: "Opened", Value: incident.OpenedAt.Format(time.RFC3339)},
+		},
+	}
+
+	if len(incident.Samples) > 0 {
+		m.describe(incident.Samples, nil)
+		if incident.Analysis != nil {
+			m.Analysis = incident.Analysis
+		}
+		return m
+	}
+
+	m.Analysis = incident.Analysis
+
+	for i := len(incident.Timeline) - 1; i >= 0; i-- {
+		if incident.Timeline[i].Kind == domain.TIMELINE_EVENT {
+			m.Sample = incident.Timeline[i].Message
+			break
+		}
+	}
+
+	return m
+}
+
+// describe adds the context and the message of the latest sample, and the latest analysis of the samples
+func (m *Message) describe(samples []domain.LogEvent, labels map[string]string) {
+	if len(samples) == 0 {
+		return
+	}
+
+	sample := samples[len(samples)-1]
+	m.Sample = sample.Message
+	m.Fields = append(m.Fields, eventContext(sample, labels)...)
+
+	for i := len(samples) - 1; i >= 0; i-- {
+		if analysis, ok := samples[i].Metadata[domain.METADATA_ANALYSIS].(domain.Analysis); ok {
+			m.Analysis = &analysis
+			break
+		}
+	}
+}
+
+// eventContext returns the source of the event and the metadata locating it, the labels of the
+// alert already shown are skipped
+func eventContext(event domain.LogEvent, labels map[string]string) []Field {
+	var fields []Field
+	if event.Source != "" {
+		fields = append(fields, Field{Name: "Source", Value: event.Source})
+	}
+
+	for _, key := range contextKeys {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+
+		if value, ok := event.Metadata[key]; ok && value != "" {
+			fields = append(fields, Field{Name: key, Value: fmt.Sprint(value)})
+		}
+	}
+
+	return fields
+}
+
+// Excerpt shortens the log text to max runes, telling how much was cut. The text is cut at the end
+// of a line when one ends in the second half, so a stack trace keeps whole frames
+func Excerpt(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	// the count of the marker is at most the length of the text
+	keep := max - len([]rune(fmt.Sprintf("\n… %d more characters", len(runes))))
+	if keep <= 0 {
+		return Truncate(text, max)
+	}
+
+	for i := keep - 1; i >= keep/2; i-- {
+		if runes[i] == '\n' {
+			keep = i
+			break
+		}
+	}
+
+	return fmt.Sprintf("%s\n… %d more characters", string(runes[:keep]), len(runes)-keep)
+}
+
+// Truncate shortens the text to max runes, ending it with an ellipsis when it was cut
+func Truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,172 @@
+package notify_test
+
+import (
+	"log-guardian/internal/adapters/notify"
+	"log-guardian/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestAlertMessage(t *testing.T) {
+	analysis := domain.Analysis{Summary: "postgres is down", Provider: "openai"}
+
+	message := notify.AlertMessage(domain.Alert{
+		Rule:     "db-errors",
+		State:    domain.ALERT_STATE_FIRING,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Summary:  "5 errors in 1m",
+		Labels:   map[string]string{"service": "payments", "pod": "payments-7f9"},
+		Count:    5,
+		StartsAt: now,
+		Samples: []domain.LogEvent{
+			{Message: "first", Metadata: map[string]interface{}{domain.METADATA_ANALYSIS: analysis}},
+			{Source: domain.SOURCE_FILE, Message: "connection refused", Metadata: map[string]interface{}{
+				domain.METADATA_INPUT: "/var/log/payments.log",
+				"pod":                 "payments-7f9",
+				"namespace":           "prod",
+			}},
+		},
+	})
+
+	assert.Equal(t, "[FIRING] db-errors", message.Title)
+	assert.Equal(t, "5 errors in 1m", message.Summary)
+	assert.Equal(t, "connection refused", message.Sample, "the latest sample is shown")
+	assert.Equal(t, now, message.At)
+	assert.Equal(t, 0xe01e5a, message.Color())
+	assert.Equal(t, []notify.Field{
+		{Name: "Severity", Value: "ERROR"},
+		{Name: "Count", Value: "5"},
+		{Name: "pod", Value: "payments-7f9"},
+		{Name: "service", Value: "payments"},
+		{Name: "Source", Value: domain.SOURCE_FILE},
+		{Name: domain.METADATA_INPUT, Value: "/var/log/payments.log"},
+		{Name: "namespace", Value: "prod"},
+	}, message.Fields)
+	require.NotNil(t, message.Analysis)
+	assert.Equal(t, analysis, *message.Analysis)
+}
+
+func TestAlertMessage_Resolved(t *testing.T) {
+	message := notify.AlertMessage(domain.Alert{
+		Rule:     "db-errors",
+		State:    domain.ALERT_STATE_RESOLVED,
+		Severity: domain.LOG_LEVEL_FATAL,
+		StartsAt: now,
+		EndsAt:   now.Add(time.Minute),
+	})
+
+	assert.Equal(t, "[RESOLVED] db-errors", message.Title)
+	assert.True(t, message.Resolved)
+	assert.Equal(t, 0x2eb67d, message.Color())
+	assert.Equal(t, now.Add(time.Minute), message.At)
+	assert.Empty(t, message.Sample)
+	assert.Nil(t, message.Analysis)
+}
+
+func TestIncidentMessage(t *testing.T) {
+	message := notify.IncidentMessage(domain.Incident{
+		Title:      "payments errors",
+		Service:    "payments",
+		Status:     domain.INCIDENT_STATUS_REOPENED,
+		Severity:   domain.LOG_LEVEL_FATAL,
+		EventCount: 12,
+		AlertCount: 2,
+		OpenedAt:   now,
+		UpdatedAt:  now.Add(time.Hour),
+		Timeline: []domain.TimelineEntry{
+			{Kind: domain.TIMELINE_EVENT, Message: "disk full"},
+			{Kind: domain.TIMELINE_STATUS, Message: "reopened"},
+		},
+	})
+
+	assert.Equal(t, "[REOPENED] payments errors", message.Title)
+	assert.Equal(t, "disk full", message.Sample)
+	assert.Equal(t, now.Add(time.Hour), message.At)
+	assert.Equal(t, 0x8b0000, message.Color())
+	assert.Contains(t, message.Fields, notify.Field{Name: "Service", Value: "payments"})
+	assert.Contains(t, message.Fields, notify.Field{Name: "Events", Value: "12"})
+}
+
+func TestIncidentMessage_WithSamples(t *testing.T) {
+	analysis := domain.Analysis{Summary: "disk of the node is full", Provider: "openai"}
+
+	message := notify.IncidentMessage(domain.Incident{
+		Title:    "payments errors",
+		Service:  "payments",
+		Status:   domain.INCIDENT_STATUS_OPEN,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Timeline: []domain.TimelineEntry{{Kind: domain.TIMELINE_EVENT, Message: "disk full"}},
+		Samples: []domain.LogEvent{
+			{Message: "disk full", Metadata: map[string]interface{}{domain.METADATA_ANALYSIS: analysis}},
+			{Source: domain.SOURCE_FILE, Message: "write failed: no space left", Metadata: map[string]interface{}{
+				"pod":       "payments-7f9",
+				"namespace": "prod",
+			}},
+		},
+	})
+
+	assert.Equal(t, "write failed: no space left", message.Sample, "the latest sample is shown")
+	assert.Contains(t, message.Fields, notify.Field{Name: "Source", Value: domain.SOURCE_FILE})
+	assert.Contains(t, message.Fields, notify.Field{Name: "namespace", Value: "prod"})
+	assert.Contains(t, message.Fields, notify.Field{Name: "pod", Value: "payments-7f9"})
+	require.NotNil(t, message.Analysis)
+	assert.Equal(t, analysis, *message.Analysis)
+}
+
+func TestIncidentMessage_WithAnalysis(t *testing.T) {
+	analysis := domain.Analysis{Summary: "payments cannot reach the database", Provider: "openai"}
+	incident := domain.Incident{
+		Title:    "payments errors",
+		Status:   domain.INCIDENT_STATUS_OPEN,
+		Analysis: &analysis,
+		Samples: []domain.LogEvent{
+			{Message: "disk full", Metadata: map[string]interface{}{domain.METADATA_ANALYSIS: domain.Analysis{Summary: "disk full"}}},
+		},
+	}
+
+	message := notify.IncidentMessage(incident)
+	require.NotNil(t, message.Analysis)
+	assert.Equal(t, analysis, *message.Analysis, "the analysis of the incident is preferred")
+
+	incident.Samples = nil
+	message = notify.IncidentMessage(incident)
+	require.NotNil(t, message.Analysis)
+	assert.Equal(t, analysis, *message.Analysis)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", notify.Truncate("short", 5))
+	assert.Equal(t, "shor…", notify.Truncate("shorter", 5))
+	assert.Equal(t, "héll…", notify.Truncate("héllo wörld", 5))
+}
+
+func TestExcerpt(t *testing.T) {
+	trace := "panic: boom\n" + strings.Repeat("main.handler()\n\t/app/main.go:42\n", 20)
+
+	tests := []struct {
+		name     string
+		text     string
+		max      int
+		expected string
+	}{
+		{name: "ShouldKeepShortText", text: "connection refused", max: 50, expected: "connection refused"},
+		{name: "ShouldCutLongLine", text: strings.Repeat("x", 100), max: 50, expected: strings.Repeat("x", 28) + "\n… 72 more characters"},
+		{name: "ShouldCutAtLineEnd", text: trace, max: 100, expected: "panic: boom\nmain.handler()\n\t/app/main.go:42\nmain.handler()\n\t/app/main.go:42\n… 577 more characters"},
+		{name: "ShouldTruncateBelowMarker", text: strings.Repeat("x", 100), max: 10, expected: strings.Repeat("x", 9) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excerpt := notify.Excerpt(tt.text, tt.max)
+
+			assert.Equal(t, tt.expected, excerpt)
+			assert.LessOrEqual(t, len([]rune(excerpt)), tt.max)
+		})
+	}
+}
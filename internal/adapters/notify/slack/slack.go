@@ -0,0 +1,153 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/adapters/notify"
+	"log-guardian/internal/core/domain"
+	"strings"
+	"time"
+)
+
+// Block Kit limits, https://api.slack.com/reference/block-kit/blocks
+const (
+	maxHeaderLength  = 150
+	maxTextLength    = 3000
+	maxFieldLength   = 2000
+	maxSectionFields = 10
+)
+
+// Notifier posts the alerts and the incidents to a Slack incoming webhook as Block Kit messages,
+// in an attachment coloured by severity
+type Notifier struct {
+	config domain.NotifierConfig
+	url    string
+	client *notify.Client
+}
+
+type payload struct {
+	Text        string       `json:"text"`
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconEmoji   string       `json:"icon_emoji,omitempty"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	Color  string  `json:"color"`
+	Blocks []block `json:"blocks"`
+}
+
+type block struct {
+	Type     string `json:"type"`
+	Text     *text  `json:"text,omitempty"`
+	Fields   []text `json:"fields,omitempty"`
+	Elements []text `json:"elements,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func NewNotifier(config domain.NotifierConfig) (*Notifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	url, err := config.URL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{
+		config: config,
+		url:    url,
+		client: notify.NewClient(config.Timeout, config.MaxRetries, 0),
+	}, nil
+}
+
+func (n *Notifier) Name() string {
+	return n.config.NotifierName()
+}
+
+func (n *Notifier) NotifyAlert(ctx context.Context, alert domain.Alert) error {
+	if !n.config.AcceptsAlert(alert) {
+		return nil
+	}
+
+	return n.client.Post(ctx, n.url, n.payload(notify.AlertMessage(alert)))
+}
+
+func (n *Notifier) NotifyIncident(ctx context.Context, incident domain.Incident) error {
+	if !n.config.AcceptsIncident(incident) {
+		return nil
+	}
+
+	return n.client.Post(ctx, n.url, n.payload(notify.IncidentMessage(incident)))
+}
+
+func (n *Notifier) payload(m notify.Message) payload {
+	blocks := []block{{Type: "header", Text: plain(notify.Truncate(m.Title, maxHeaderLength))}}
+
+	if m.Summary != "" {
+		blocks = append(blocks, block{Type: "section", Text: markdown(notify.Truncate(escape(m.Summary), maxTextLength))})
+	}
+
+	for start := 0; start < len(m.Fields); start += maxSectionFields {
+		fields := make([]text, 0, maxSectionFields)
+		for _, field := range m.Fields[start:min(start+maxSectionFields, len(m.Fields))] {
+			fields = append(fields, *markdown(notify.Truncate(fmt.Sprintf("*%s*\n%s", escape(field.Name), escape(field.Value)), maxFieldLength)))
+		}
+		blocks = append(blocks, block{Type: "section", Fields: fields})
+	}
+
+	if m.Sample != "" {
+		// the fence and the label take a few characters of the section
+		sample := notify.Excerpt(escape(strings.ReplaceAll(m.Sample, "```", "'''")), maxTextLength-32)
+		blocks = append(blocks, block{Type: "section", Text: markdown("*Sample*\n```" + sample + "```")})
+	}
+
+	if m.Analysis != nil {
+		blocks = append(blocks, block{Type: "divider"}, block{Type: "section", Text: markdown(analysis(*m.Analysis))})
+	}
+
+	if !m.At.IsZero() {
+		blocks = append(blocks, block{Type: "context", Elements: []text{*markdown(m.At.Format(time.RFC3339))}})
+	}
+
+	return payload{
+		Text:        m.Title,
+		Channel:     n.config.Slack.Channel,
+		Username:    n.config.Slack.Username,
+		IconEmoji:   n.config.Slack.IconEmoji,
+		Attachments: []attachment{{Color: fmt.Sprintf("#%06x", m.Color()), Blocks: blocks}},
+	}
+}
+
+func analysis(a domain.Analysis) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "*AI analysis* (%s, confidence %.0f%%)\n%s", escape(a.Provider), a.Confidence*100, escape(a.Summary))
+	if a.ProbableCause != "" {
+		fmt.Fprintf(&sb, "\n*Probable cause:* %s", escape(a.ProbableCause))
+	}
+	if a.SuggestedFix != "" {
+		fmt.Fprintf(&sb, "\n*Suggested fix:* %s", escape(a.SuggestedFix))
+	}
+
+	return notify.Truncate(sb.String(), maxTextLength)
+}
+
+func plain(s string) *text {
+	return &text{Type: "plain_text", Text: s}
+}
+
+func markdown(s string) *text {
+	return &text{Type: "mrkdwn", Text: s}
+}
+
+// escape replaces the characters Slack reads as control sequences in mrkdwn
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
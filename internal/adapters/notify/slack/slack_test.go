@@ -0,0 +1,209 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log-guardian/internal/adapters/notify/slack"
+	"log-guardian/internal/core/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var alert = domain.Alert{
+	Rule:     "db-errors",
+	State:    domain.ALERT_STATE_FIRING,
+	Severity: domain.LOG_LEVEL_ERROR,
+	Summary:  "5 errors in 1m",
+	Count:    5,
+	StartsAt: now,
+	Samples: []domain.LogEvent{{
+		Source:  domain.SOURCE_FILE,
+		Message: "dial tcp <db>:5432: connection refused",
+		Metadata: map[string]interface{}{
+			"pod": "payments-7f9",
+			domain.METADATA_ANALYSIS: domain.Analysis{
+				Summary:       "Postgres is down",
+				ProbableCause: "The primary restarted",
+				SuggestedFix:  "Check the database pod",
+				Confidence:    0.8,
+				Provider:      "openai",
+			},
+		},
+	}},
+}
+
+// capture records the payloads posted to the webhook
+func capture(t *testing.T, statuses ...int) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+
+	var payloads []map[string]any
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(body, &payload))
+		payloads = append(payloads, payload)
+
+		status := http.StatusOK
+		if attempt := int(attempts.Add(1)); attempt <= len(statuses) {
+			status = statuses[attempt-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &payloads
+}
+
+func newNotifier(t *testing.T, config domain.NotifierConfig) *slack.Notifier {
+	t.Helper()
+
+	config.Type = domain.NOTIFIER_SLACK
+	notifier, err := slack.NewNotifier(config)
+	require.NoError(t, err)
+
+	return notifier
+}
+
+func TestNotifier_NotifyAlert(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{
+		WebhookURL: server.URL,
+		Slack:      domain.SlackConfig{Channel: "#alerts", Username: "log-guardian"},
+	})
+
+	require.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+	require.Len(t, *payloads, 1)
+
+	payload := (*payloads)[0]
+	assert.Equal(t, "[FIRING] db-errors", payload["text"])
+	assert.Equal(t, "#alerts", payload["channel"])
+	assert.Equal(t, "log-guardian", payload["username"])
+
+	attachment := payload["attachments"].([]any)[0].(map[string]any)
+	assert.Equal(t, "#e01e5a", attachment["color"])
+
+	var types []string
+	var texts []string
+	for _, b := range attachment["blocks"].([]any) {
+		block := b.(map[string]any)
+		types = append(types, block["type"].(string))
+
+		if text, ok := block["text"].(map[string]any); ok {
+			texts = append(texts, text["text"].(string))
+		}
+		for _, field := range append(asSlice(block["fields"]), asSlice(block["elements"])...) {
+			texts = append(texts, field.(map[string]any)["text"].(string))
+		}
+	}
+
+	assert.Equal(t, []string{"header", "section", "section", "section", "divider", "section", "context"}, types)
+	assert.Equal(t, []string{
+		"[FIRING] db-errors",
+		"5 errors in 1m",
+		"*Severity*\nERROR",
+		"*Count*\n5",
+		"*Source*\nfile",
+		"*pod*\npayments-7f9",
+		"*Sample*\n```dial tcp &lt;db&gt;:5432: connection refused```",
+		"*AI analysis* (openai, confidence 80%)\nPostgres is down\n*Probable cause:* The primary restarted\n*Suggested fix:* Check the database pod",
+		"2025-01-01T12:00:00Z",
+	}, texts)
+}
+
+func TestNotifier_NotifyIncident(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	err := notifier.NotifyIncident(context.Background(), domain.Incident{
+		Title:     "payments errors",
+		Status:    domain.INCIDENT_STATUS_RESOLVED,
+		Severity:  domain.LOG_LEVEL_ERROR,
+		UpdatedAt: now,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *payloads, 1)
+	assert.Equal(t, "[RESOLVED] payments errors", (*payloads)[0]["text"])
+	assert.Equal(t, "#2eb67d", (*payloads)[0]["attachments"].([]any)[0].(map[string]any)["color"])
+}
+
+func TestNotifier_ShouldSkipBelowMinSeverity(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL, MinSeverity: domain.LOG_LEVEL_FATAL})
+
+	assert.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+	assert.NoError(t, notifier.NotifyIncident(context.Background(), domain.Incident{Severity: domain.LOG_LEVEL_WARNING}))
+	assert.Empty(t, *payloads)
+}
+
+func TestNotifier_ShouldSkipOtherPipelines(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL, Pipelines: []string{"payments"}})
+
+	assert.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+	assert.NoError(t, notifier.NotifyIncident(context.Background(), domain.Incident{Pipelines: []string{"platform"}}))
+	assert.Empty(t, *payloads)
+}
+
+func TestNotifier_ShouldWaitRetryAfter(t *testing.T) {
+	server, payloads := capture(t, http.StatusTooManyRequests)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	start := time.Now()
+	require.NoError(t, notifier.NotifyAlert(context.Background(), alert))
+
+	assert.Len(t, *payloads, 2)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestNotifier_ShouldReportRejectedMessage(t *testing.T) {
+	server, _ := capture(t, http.StatusBadRequest)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	err := notifier.NotifyAlert(context.Background(), alert)
+
+	assert.ErrorIs(t, err, domain.ErrNotificationFailed)
+}
+
+func TestNotifier_ShouldTruncateLongSample(t *testing.T) {
+	server, payloads := capture(t)
+	notifier := newNotifier(t, domain.NotifierConfig{WebhookURL: server.URL})
+
+	long := alert
+	long.Samples = []domain.LogEvent{{Message: strings.Repeat("x", 5000)}}
+	require.NoError(t, notifier.NotifyAlert(context.Background(), long))
+
+	for _, b := range (*payloads)[0]["attachments"].([]any)[0].(map[string]any)["blocks"].([]any) {
+		if text, ok := b.(map[string]any)["text"].(map[string]any); ok {
+			assert.LessOrEqual(t, len([]rune(text["text"].(string))), 3000)
+		}
+	}
+}
+
+func TestNewNotifier_ShouldRejectInvalidConfig(t *testing.T) {
+	_, err := slack.NewNotifier(domain.NotifierConfig{Type: domain.NOTIFIER_SLACK})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidNotifier)
+}
+
+func asSlice(value any) []any {
+	slice, _ := value.([]any)
+	return slice
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileOutput appends the events to a file as JSON lines, the lines of a write are flushed together
+type FileOutput struct {
+	path string
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewFileOutput opens the file for appending, creating it and its folder when missing
+func NewFileOutput(path string) (*FileOutput, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileOutput{path: path, file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+func (f *FileOutput) Name() string {
+	return domain.OUTPUT_FILE + ":" + f.path
+}
+
+func (f *FileOutput) Write(events []domain.LogEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, event := range events {
+		line, err := event.ToJSON()
+		if err != nil {
+			return fmt.Errorf("event %s: %w", event.ID, err)
+		}
+
+		f.writer.Write(line)
+		f.writer.WriteByte('\n')
+	}
+
+	return f.writer.Flush()
+}
+
+func (f *FileOutput) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.writer.Flush(); err != nil {
+		f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
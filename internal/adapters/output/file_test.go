@@ -0,0 +1,54 @@
+package output_test
+
+import (
+	"bufio"
+	"log-guardian/internal/adapters/output"
+	"log-guardian/internal/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "platform", "debug.log")
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := domain.LogEvent{ID: "1", Timestamp: at, Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_DEBUG, Message: "cache warmed"}
+	second := domain.LogEvent{ID: "2", Timestamp: at, Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_DEBUG, Message: "gc paused",
+		Metadata: map[string]interface{}{domain.METADATA_PIPELINE: "platform-debug"}}
+
+	out, err := output.NewFileOutput(path)
+	require.NoError(t, err)
+	assert.Equal(t, "file:"+path, out.Name())
+
+	require.NoError(t, out.Write([]domain.LogEvent{first}))
+	require.NoError(t, out.Close())
+
+	// the file is appended to when opened again
+	out, err = output.NewFileOutput(path)
+	require.NoError(t, err)
+	require.NoError(t, out.Write([]domain.LogEvent{second}))
+	require.NoError(t, out.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []domain.LogEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event domain.LogEvent
+		require.NoError(t, event.FromJSON(scanner.Bytes()))
+		events = append(events, event)
+	}
+
+	assert.Equal(t, []domain.LogEvent{first, second}, events)
+}
+
+func TestNewFileOutput_ShouldFailOnUnwritablePath(t *testing.T) {
+	_, err := output.NewFileOutput(t.TempDir())
+	assert.Error(t, err, "a folder can't be opened for writing")
+}
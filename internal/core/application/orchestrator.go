@@ -7,8 +7,18 @@ import (
 	"log-guardian/internal/core/ports"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"sync"
 	"syscall"
+	"time"
+)
+
+const (
+	// flushInterval is how often the pipelines are asked to release the events they hold
+	flushInterval = time.Second
+	// notificationQueue bounds the notifications waiting for delivery on each channel
+	notificationQueue = 100
 )
 
 type orchestrator struct {
@@ -17,14 +27,96 @@ type orchestrator struct {
 		file  ports.InputProvider
 		unix  ports.InputProvider
 	}
-	config    *domain.RuntimeConfig
-	wg        *sync.WaitGroup
-	once      sync.Once
-	ctx       context.Context
-	ctxCancel context.CancelFunc
-	signal    chan os.Signal
-	outputs   []domain.LogEvent
-	errors    []error
+	config     *domain.RuntimeConfig
+	wg         *sync.WaitGroup
+	once       sync.Once
+	ctx        context.Context
+	ctxCancel  context.CancelFunc
+	signal     chan os.Signal
+	enrichers  map[string]ports.Enricher
+	pipelines  []ports.Stage
+	sinks      map[string][]ports.Output
+	dispatcher ports.Dispatcher
+	alerter    ports.Alerter
+	incidents  ports.IncidentTracker
+	analyzer   ports.AIAnalyzer
+	notifiers  []ports.Notifier
+	pending    chan notification
+	queues     []chan notification
+	deliveries sync.WaitGroup
+	notifyCtx  context.Context
+	notifyStop context.CancelFunc
+	outputs    []domain.LogEvent
+	alerts     []domain.Alert
+	changes    []domain.Incident
+	mu         sync.Mutex
+	errors     []error
+}
+
+// notification is an alert or an incident waiting for delivery
+type notification struct {
+	alert    *domain.Alert
+	incident *domain.Incident
+}
+
+// Option configures optional collaborators of the orchestrator
+type Option func(*orchestrator)
+
+// WithEnricher enriches the events coming from the given source
+func WithEnricher(source string, enricher ports.Enricher) Option {
+	return func(o *orchestrator) {
+		o.enrichers[source] = enricher
+	}
+}
+
+// WithDispatcher reorders the ingested events before they reach the pipelines
+func WithDispatcher(dispatcher ports.Dispatcher) Option {
+	return func(o *orchestrator) {
+		o.dispatcher = dispatcher
+	}
+}
+
+// WithPipeline runs the events through the pipeline before collecting them
+func WithPipeline(pipeline ports.Stage) Option {
+	return func(o *orchestrator) {
+		o.pipelines = append(o.pipelines, pipeline)
+	}
+}
+
+// WithOutput writes the results of the named pipeline to the output, instead of collecting them
+func WithOutput(pipeline string, output ports.Output) Option {
+	return func(o *orchestrator) {
+		o.sinks[pipeline] = append(o.sinks[pipeline], output)
+	}
+}
+
+// WithAlerter evaluates the alert rules over the processed events
+func WithAlerter(alerter ports.Alerter) Option {
+	return func(o *orchestrator) {
+		o.alerter = alerter
+	}
+}
+
+// WithIncidents groups the processed events and the alerts into incidents
+func WithIncidents(tracker ports.IncidentTracker) Option {
+	return func(o *orchestrator) {
+		o.incidents = tracker
+	}
+}
+
+// WithIncidentAnalyzer asks the analyzer about the incidents opening or reopening, before their
+// notification is delivered
+func WithIncidentAnalyzer(analyzer ports.AIAnalyzer) Option {
+	return func(o *orchestrator) {
+		o.analyzer = analyzer
+	}
+}
+
+// WithNotifier delivers the alerts and the incident changes to the channel
+func WithNotifier(notifier ports.Notifier) Option {
+	return func(o *orchestrator) {
+		o.notifiers = append(o.notifiers, notifier)
+	}
 }
 
 func NewOrchestrator(
@@ -33,6 +125,7 @@ func NewOrchestrator(
 	stdin ports.InputProvider,
 	file ports.InputProvider,
 	unix ports.InputProvider,
+	opts ...Option,
 ) *orchestrator {
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 
@@ -45,12 +138,18 @@ func NewOrchestrator(
 		ctx:       ctxWithCancel,
 		ctxCancel: cancel,
 		signal:    signalChan,
+		enrichers: make(map[string]ports.Enricher),
+		sinks:     make(map[string][]ports.Output),
 	}
 
 	orc.ingests.stdin = stdin
 	orc.ingests.file = file
 	orc.ingests.unix = unix
 
+	for _, opt := range opts {
+		opt(orc)
+	}
+
 	return orc
 }
 
@@ -70,17 +169,27 @@ func (o *orchestrator) Execute() {
 		o.watchUnix(outputChan, errChan)
 	}
 
+	events := o.dispatch(outputChan)
+	o.startNotifiers()
+
 	fmt.Println("Log Guardian is running")
 
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
 outer:
 	for {
 		select {
-		case event := <-outputChan:
-			o.outputs = append(o.outputs, event)
+		case event := <-events:
+			o.enrich(&event)
+			o.process(event)
 		case <-o.ctx.Done():
 			break outer
 		case err := <-errChan:
-			o.errors = append(o.errors, err)
+			o.fail(err)
+		case now := <-ticker.C:
+			o.flush(now, false)
+			o.evaluate(now)
 		case <-o.signal:
 			break outer
 		}
@@ -89,6 +198,310 @@ outer:
 	fmt.Println("Log Guardian is shutting down")
 
 	o.Shutdown()
+	now := time.Now()
+	o.flush(now, true)
+	o.evaluate(now)
+	o.closeIncidents()
+	o.closeOutputs()
+	o.stopNotifiers()
+	o.printStats()
+}
+
+func (o *orchestrator) enrich(event *domain.LogEvent) {
+	if enricher, ok := o.enrichers[event.Source]; ok {
+		enricher.Enrich(event)
+	}
+}
+
+// dispatch returns the channel the events are processed from
+func (o *orchestrator) dispatch(input <-chan domain.LogEvent) <-chan domain.LogEvent {
+	if o.dispatcher == nil {
+		return input
+	}
+
+	output := make(chan domain.LogEvent)
+	o.dispatcher.Run(o.ctx, input, output)
+
+	return output
+}
+
+// process runs the event through the pipelines, without pipelines the event is collected as is
+func (o *orchestrator) process(event domain.LogEvent) {
+	if len(o.pipelines) == 0 {
+		o.collect(event)
+		return
+	}
+
+	for _, pipeline := range o.pipelines {
+		o.collect(pipeline.Process(event)...)
+	}
+}
+
+// flush collects the events released by the pipelines holding events back
+func (o *orchestrator) flush(now time.Time, final bool) {
+	for _, pipeline := range o.pipelines {
+		if flusher, ok := pipeline.(ports.Flusher); ok {
+			o.collect(flusher.Flush(now, final)...)
+		}
+	}
+}
+
+// collect keeps the processed events and hands them to the alerter and the incident tracker, the
+// results of the pipelines with outputs are written to them instead
+func (o *orchestrator) collect(events ...domain.LogEvent) {
+	events = o.write(events)
+
+	o.mu.Lock()
+	o.outputs = append(o.outputs, events...)
+	o.mu.Unlock()
+
+	for _, event := range events {
+		if o.alerter != nil {
+			o.alerter.Observe(event)
+		}
+
+		if o.incidents != nil {
+			o.incidents.ObserveEvent(event)
+		}
+	}
+}
+
+// write sends the events of the pipelines with outputs to them, returning the other events
+func (o *orchestrator) write(events []domain.LogEvent) []domain.LogEvent {
+	if len(o.sinks) == 0 {
+		return events
+	}
+
+	var (
+		kept       []domain.LogEvent
+		byPipeline = make(map[string][]domain.LogEvent)
+	)
+
+	for _, event := range events {
+		name, _ := event.Metadata[domain.METADATA_PIPELINE].(string)
+		if _, ok := o.sinks[name]; ok {
+			byPipeline[name] = append(byPipeline[name], event)
+			continue
+		}
+
+		kept = append(kept, event)
+	}
+
+	for name, batch := range byPipeline {
+		for _, output := range o.sinks[name] {
+			if err := output.Write(batch); err != nil {
+				o.fail(fmt.Errorf("output %s: %w", output.Name(), err))
+			}
+		}
+	}
+
+	return kept
+}
+
+// evaluate collects the alerts that fired or resolved and the incidents whose status changed
+func (o *orchestrator) evaluate(now time.Time) {
+	if o.alerter != nil {
+		alerts := o.alerter.Evaluate(now)
+		o.mu.Lock()
+		o.alerts = append(o.alerts, alerts...)
+		o.mu.Unlock()
+
+		for _, alert := range alerts {
+			if o.incidents != nil {
+				o.incidents.ObserveAlert(alert)
+			}
+			o.notify(notification{alert: &alert})
+		}
+	}
+
+	if o.incidents != nil {
+		changes := o.incidents.Tick(now)
+		o.mu.Lock()
+		o.changes = append(o.changes, changes...)
+		o.mu.Unlock()
+
+		for _, incident := range changes {
+			o.notify(notification{incident: &incident})
+		}
+	}
+}
+
+// closeIncidents saves the incidents state on shutdown
+func (o *orchestrator) closeIncidents() {
+	if o.incidents == nil {
+		return
+	}
+
+	if err := o.incidents.Close(); err != nil {
+		o.fail(err)
+	}
+}
+
+// closeOutputs flushes and closes the pipeline outputs on shutdown
+func (o *orchestrator) closeOutputs() {
+	for _, outputs := range o.sinks {
+		for _, output := range outputs {
+			if err := output.Close(); err != nil {
+				o.fail(fmt.Errorf("output %s: %w", output.Name(), err))
+			}
+		}
+	}
+}
+
+// startNotifiers delivers the notifications in the background, a worker per channel keeps their order
+func (o *orchestrator) startNotifiers() {
+	o.notifyCtx, o.notifyStop = context.WithCancel(context.Background())
+
+	for _, notifier := range o.notifiers {
+		queue := make(chan notification, notificationQueue)
+		o.queues = append(o.queues, queue)
+
+		o.deliveries.Add(1)
+		go o.deliver(notifier, queue)
+	}
+
+	if o.analyzer != nil && len(o.queues) > 0 {
+		o.pending = make(chan notification, notificationQueue)
+
+		o.deliveries.Add(1)
+		go o.analyzeIncidents(o.pending)
+	}
+}
+
+// analyzeIncidents attaches the analysis to the incidents opening or reopening, then queues the
+// notifications on the channels in the order they were raised
+func (o *orchestrator) analyzeIncidents(pending <-chan notification) {
+	defer o.deliveries.Done()
+	defer o.closeQueues()
+
+	for n := range pending {
+		if incident := n.incident; incident != nil &&
+			(incident.Status == domain.INCIDENT_STATUS_OPEN || incident.Status == domain.INCIDENT_STATUS_REOPENED) {
+			analysis, err := o.analyzer.Analyze(o.notifyCtx, incident.AnalysisRequest())
+			if err != nil {
+				o.fail(fmt.Errorf("incident %s: %w", incident.ID, err))
+			} else {
+				incident.Analysis = &analysis
+			}
+		}
+
+		o.queue(n)
+	}
+}
+
+// deliver sends the queued notifications. The context of the orchestrator isn't used, the
+// notifications raised on shutdown are still delivered until the shutdown timeout
+func (o *orchestrator) deliver(notifier ports.Notifier, queue <-chan notification) {
+	defer o.deliveries.Done()
+
+	for n := range queue {
+		var err error
+		if n.alert != nil {
+			err = notifier.NotifyAlert(o.notifyCtx, *n.alert)
+		} else {
+			err = notifier.NotifyIncident(o.notifyCtx, *n.incident)
+		}
+
+		if err != nil {
+			o.fail(fmt.Errorf("notifier %s: %w", notifier.Name(), err))
+		}
+	}
+}
+
+// notify queues the notification for delivery, through the incident analysis when there is one
+func (o *orchestrator) notify(n notification) {
+	if o.pending == nil {
+		o.queue(n)
+		return
+	}
+
+	select {
+	case o.pending <- n:
+	default:
+		o.fail(fmt.Errorf("incident analysis: %w: queue full", domain.ErrNotificationFailed))
+	}
+}
+
+// queue queues the notification on each channel, it is dropped for the channels too far behind
+func (o *orchestrator) queue(n notification) {
+	for i, queue := range o.queues {
+		select {
+		case queue <- n:
+		default:
+			o.fail(fmt.Errorf("notifier %s: %w: queue full", o.notifiers[i].Name(), domain.ErrNotificationFailed))
+		}
+	}
+}
+
+func (o *orchestrator) closeQueues() {
+	for _, queue := range o.queues {
+		close(queue)
+	}
+}
+
+// stopNotifiers waits for the queued notifications to be delivered, the deliveries still running
+// after the shutdown timeout are cancelled
+func (o *orchestrator) stopNotifiers() {
+	if o.pending != nil {
+		// the incident analysis closes the queues once the pending notifications are queued
+		close(o.pending)
+	} else {
+		o.closeQueues()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		o.deliveries.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Duration(o.config.ShutdownTimeout) * time.Second):
+		o.notifyStop()
+		<-done
+	}
+
+	o.notifyStop()
+}
+
+func (o *orchestrator) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.errors = append(o.errors, err)
+}
+
+func (o *orchestrator) printStats() {
+	if provider, ok := o.dispatcher.(ports.StatsProvider); ok {
+		printStats("dispatcher", provider.Stats())
+	}
+
+	if provider, ok := o.alerter.(ports.StatsProvider); ok {
+		printStats("alerting", provider.Stats())
+	}
+
+	if provider, ok := o.incidents.(ports.StatsProvider); ok {
+		printStats("incidents", provider.Stats())
+	}
+
+	for _, pipeline := range o.pipelines {
+		if provider, ok := pipeline.(ports.StatsProvider); ok {
+			printStats("pipeline "+pipeline.Name(), provider.Stats())
+		}
+	}
+}
+
+func printStats(name string, stats map[string]uint64) {
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("%s: %s=%d\n", name, key, stats[key])
+	}
 }
 
 func (o *orchestrator) watchStdin(output chan<- domain.LogEvent, errChan chan<- error) {
@@ -124,9 +537,30 @@ func (s *orchestrator) OnShutdown() {
 }
 
 func (o *orchestrator) GetOutput() []domain.LogEvent {
-	return o.outputs
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return slices.Clone(o.outputs)
+}
+
+func (o *orchestrator) GetAlerts() []domain.Alert {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return slices.Clone(o.alerts)
+}
+
+// GetIncidents returns the incidents whose status changed
+func (o *orchestrator) GetIncidents() []domain.Incident {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return slices.Clone(o.changes)
 }
 
 func (o *orchestrator) GetErrors() []error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
 	return o.errors
 }
@@ -525,3 +525,583 @@ func TestOrchestrator_Execute_WithNilProviders(t *testing.T) {
 		t.Errorf("Expected 0 errors, got %d", len(errors))
 	}
 }
+
+func TestOrchestrator_Execute_WithEnricher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	stdinEnricher := ports.NewMockEnricher(ctrl)
+	stdinEnricher.EXPECT().Enrich(gomock.Any()).Times(1).Do(func(event *domain.LogEvent) {
+		event.AddMetadata("hostname", "node-a")
+	})
+
+	// the file enricher must not be called for stdin events
+	fileEnricher := ports.NewMockEnricher(ctrl)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithEnricher(domain.SOURCE_STDIN, stdinEnricher),
+		application.WithEnricher(domain.SOURCE_FILE, fileEnricher),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN, Message: "test message"}
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	outputs := orc.GetOutput()
+	if len(outputs) != 1 {
+		t.Fatalf("Expected 1 output, got %d", len(outputs))
+	}
+
+	if hostname, _ := outputs[0].GetMetadata("hostname"); hostname != "node-a" {
+		t.Errorf("Expected hostname 'node-a', got '%v'", hostname)
+	}
+}
+
+func TestOrchestrator_Execute_WithPipelines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	dropAll := ports.NewMockStage(ctrl)
+	dropAll.EXPECT().Name().AnyTimes().Return("drop-all")
+	dropAll.EXPECT().Process(gomock.Any()).Times(2).Return(nil)
+
+	keepAll := ports.NewMockStage(ctrl)
+	keepAll.EXPECT().Name().AnyTimes().Return("keep-all")
+	keepAll.EXPECT().Process(gomock.Any()).Times(2).DoAndReturn(func(event domain.LogEvent) []domain.LogEvent {
+		return []domain.LogEvent{event}
+	})
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithPipeline(dropAll),
+		application.WithPipeline(keepAll),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- domain.LogEvent{ID: "first", Source: domain.SOURCE_STDIN}
+			output <- domain.LogEvent{ID: "second", Source: domain.SOURCE_STDIN}
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	outputs := orc.GetOutput()
+	if len(outputs) != 2 {
+		t.Fatalf("Expected 2 outputs, got %d", len(outputs))
+	}
+
+	if outputs[0].ID != "first" || outputs[1].ID != "second" {
+		t.Errorf("Expected the events in order, got '%s' and '%s'", outputs[0].ID, outputs[1].ID)
+	}
+}
+
+func TestOrchestrator_Execute_WithOutputs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	debug := domain.LogEvent{ID: "debug", Metadata: map[string]interface{}{domain.METADATA_PIPELINE: "platform-debug"}}
+	errored := domain.LogEvent{ID: "error", Metadata: map[string]interface{}{domain.METADATA_PIPELINE: "default"}}
+
+	// the router tags the results of each pipeline with its name
+	router := ports.NewMockStage(ctrl)
+	router.EXPECT().Name().AnyTimes().Return("router")
+	router.EXPECT().Process(gomock.Any()).Times(1).Return([]domain.LogEvent{debug, errored})
+
+	alerter := ports.NewMockAlerter(ctrl)
+	alerter.EXPECT().Observe(errored).Times(1)
+	alerter.EXPECT().Evaluate(gomock.Any()).AnyTimes().Return(nil)
+
+	out := ports.NewMockOutput(ctrl)
+	out.EXPECT().Name().AnyTimes().Return("file:debug.log")
+	out.EXPECT().Write([]domain.LogEvent{debug}).Return(errors.New("disk full")).Times(1)
+	out.EXPECT().Close().Return(nil).Times(1)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithPipeline(router),
+		application.WithOutput("platform-debug", out),
+		application.WithAlerter(alerter),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- domain.LogEvent{ID: "line", Source: domain.SOURCE_STDIN}
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		orc.Execute()
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+	<-done
+
+	outputs := orc.GetOutput()
+	if len(outputs) != 1 || outputs[0].ID != "error" {
+		t.Errorf("Expected only the events of the pipeline without outputs, got %v", outputs)
+	}
+
+	errs := orc.GetErrors()
+	if len(errs) != 1 || errs[0].Error() != "output file:debug.log: disk full" {
+		t.Errorf("Expected the write error, got %v", errs)
+	}
+}
+
+// flushingStage is a pipeline that holds events back
+type flushingStage struct {
+	*ports.MockStage
+	*ports.MockFlusher
+}
+
+func TestOrchestrator_Execute_FlushesPipelinesOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	stage := ports.NewMockStage(ctrl)
+	stage.EXPECT().Name().AnyTimes().Return("held")
+
+	flusher := ports.NewMockFlusher(ctrl)
+	flusher.EXPECT().Flush(gomock.Any(), false).AnyTimes().Return(nil)
+	flusher.EXPECT().Flush(gomock.Any(), true).Times(1).Return([]domain.LogEvent{{ID: "held-id"}})
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithPipeline(flushingStage{MockStage: stage, MockFlusher: flusher}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		orc.Execute()
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	orc.Shutdown()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Execute to return after shutdown")
+	}
+
+	outputs := orc.GetOutput()
+	if len(outputs) != 1 || outputs[0].ID != "held-id" {
+		t.Errorf("Expected the held event to be flushed, got %v", outputs)
+	}
+}
+
+func TestOrchestrator_Execute_WithDispatcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	dispatcher := ports.NewMockDispatcher(ctrl)
+	dispatcher.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Do(
+		func(ctx context.Context, input <-chan domain.LogEvent, output chan<- domain.LogEvent) {
+			go func() {
+				event := <-input
+				event.AddMetadata("dispatched", "true")
+				output <- event
+			}()
+		},
+	)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix, application.WithDispatcher(dispatcher))
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN}
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	outputs := orc.GetOutput()
+	if len(outputs) != 1 {
+		t.Fatalf("Expected 1 output, got %d", len(outputs))
+	}
+
+	if dispatched, _ := outputs[0].GetMetadata("dispatched"); dispatched != "true" {
+		t.Errorf("Expected the event to go through the dispatcher")
+	}
+}
+
+func TestOrchestrator_Execute_WithAlerter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	event := domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_FATAL}
+	alert := domain.Alert{Rule: "any-fatal", State: domain.ALERT_STATE_FIRING}
+
+	alerter := ports.NewMockAlerter(ctrl)
+	alerter.EXPECT().Observe(event).Times(1)
+	alerter.EXPECT().Evaluate(gomock.Any()).Return([]domain.Alert{alert}).Times(1)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix, application.WithAlerter(alerter))
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- event
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	alerts := orc.GetAlerts()
+	if len(alerts) != 1 || alerts[0].Rule != "any-fatal" {
+		t.Errorf("Expected the alert of the alerter, got %v", alerts)
+	}
+}
+
+func TestOrchestrator_Execute_WithIncidents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	event := domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_ERROR}
+	alert := domain.Alert{Rule: "errors", State: domain.ALERT_STATE_FIRING}
+	incident := domain.Incident{ID: "incident-id", Status: domain.INCIDENT_STATUS_OPEN}
+
+	alerter := ports.NewMockAlerter(ctrl)
+	alerter.EXPECT().Observe(event).Times(1)
+	alerter.EXPECT().Evaluate(gomock.Any()).Return([]domain.Alert{alert}).Times(1)
+
+	tracker := ports.NewMockIncidentTracker(ctrl)
+	tracker.EXPECT().ObserveEvent(event).Times(1)
+	tracker.EXPECT().ObserveAlert(alert).Times(1)
+	tracker.EXPECT().Tick(gomock.Any()).Return([]domain.Incident{incident}).Times(1)
+	tracker.EXPECT().Close().Return(errors.New("disk full")).Times(1)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithAlerter(alerter),
+		application.WithIncidents(tracker),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- event
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	incidents := orc.GetIncidents()
+	if len(incidents) != 1 || incidents[0].ID != "incident-id" {
+		t.Errorf("Expected the incident of the tracker, got %v", incidents)
+	}
+
+	if errs := orc.GetErrors(); len(errs) != 1 {
+		t.Errorf("Expected the close error, got %v", errs)
+	}
+}
+
+func TestOrchestrator_Execute_WithNotifier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	event := domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_ERROR}
+	alert := domain.Alert{Rule: "errors", State: domain.ALERT_STATE_FIRING}
+	incident := domain.Incident{ID: "incident-id", Status: domain.INCIDENT_STATUS_OPEN}
+
+	alerter := ports.NewMockAlerter(ctrl)
+	alerter.EXPECT().Observe(event).Times(1)
+	alerter.EXPECT().Evaluate(gomock.Any()).Return([]domain.Alert{alert}).Times(1)
+
+	tracker := ports.NewMockIncidentTracker(ctrl)
+	tracker.EXPECT().ObserveEvent(event).Times(1)
+	tracker.EXPECT().ObserveAlert(alert).Times(1)
+	tracker.EXPECT().Tick(gomock.Any()).Return([]domain.Incident{incident}).Times(1)
+	tracker.EXPECT().Close().Return(nil).Times(1)
+
+	notifier := ports.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return("slack").AnyTimes()
+	notifier.EXPECT().NotifyAlert(gomock.Any(), alert).Return(nil).Times(1)
+	notifier.EXPECT().NotifyIncident(gomock.Any(), incident).Return(errors.New("status 404")).Times(1)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithAlerter(alerter),
+		application.WithIncidents(tracker),
+		application.WithNotifier(notifier),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- event
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	go orc.Execute()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+
+	errs := orc.GetErrors()
+	if len(errs) != 1 || errs[0].Error() != "notifier slack: status 404" {
+		t.Errorf("Expected the notification error, got %v", errs)
+	}
+}
+
+func TestOrchestrator_Execute_WithIncidentAnalyzer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	event := domain.LogEvent{ID: "test-id", Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_ERROR, Message: "disk full"}
+	opened := domain.Incident{ID: "opened-id", Status: domain.INCIDENT_STATUS_OPEN, Samples: []domain.LogEvent{event}}
+	resolved := domain.Incident{ID: "resolved-id", Status: domain.INCIDENT_STATUS_RESOLVED}
+	analysis := domain.Analysis{Summary: "the disk of the node is full"}
+
+	tracker := ports.NewMockIncidentTracker(ctrl)
+	tracker.EXPECT().ObserveEvent(event).Times(1)
+	tracker.EXPECT().Tick(gomock.Any()).Return([]domain.Incident{opened, resolved}).Times(1)
+	tracker.EXPECT().Close().Return(nil).Times(1)
+
+	// only the opening incident is analyzed
+	analyzer := ports.NewMockAIAnalyzer(ctrl)
+	analyzer.EXPECT().Analyze(gomock.Any(), opened.AnalysisRequest()).Return(analysis, nil).Times(1)
+
+	analyzed := opened
+	analyzed.Analysis = &analysis
+
+	notifier := ports.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return("slack").AnyTimes()
+	gomock.InOrder(
+		notifier.EXPECT().NotifyIncident(gomock.Any(), analyzed).Return(nil).Times(1),
+		notifier.EXPECT().NotifyIncident(gomock.Any(), resolved).Return(nil).Times(1),
+	)
+
+	orc := application.NewOrchestrator(ctx, config, stdin, file, unix,
+		application.WithIncidents(tracker),
+		application.WithIncidentAnalyzer(analyzer),
+		application.WithNotifier(notifier),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			output <- event
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown.OnShutdown()
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		orc.Execute()
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	orc.Shutdown()
+	<-done
+
+	if errs := orc.GetErrors(); len(errs) != 0 {
+		t.Errorf("Expected no error, got %v", errs)
+	}
+}
+
+func TestOrchestrator_Execute_CancelsSlowNotificationsOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 1,
+		Ingests: domain.Ingests{
+			Stdin: domain.StdinConfig{Enabled: true},
+		},
+	}
+
+	stdin := ports.NewMockInputProvider(ctrl)
+	file := ports.NewMockInputProvider(ctrl)
+	unix := ports.NewMockInputProvider(ctrl)
+
+	alert := domain.Alert{Rule: "errors", State: domain.ALERT_STATE_FIRING}
+
+	alerter := ports.NewMockAlerter(ctrl)
+	alerter.EXPECT().Evaluate(gomock.Any()).Return([]domain.Alert{alert}).Times(1)
+
+	// the webhook keeps asking to retry later
+	notifier := ports.NewMockNotifier(ctrl)
+	notifier.EXPECT().Name().Return("slack").AnyTimes()
+	notifier.EXPECT().NotifyAlert(gomock.Any(), alert).DoAndReturn(func(ctx context.Context, alert domain.Alert) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}).Times(1)
+
+	orc := application.NewOrchestrator(context.Background(), config, stdin, file, unix,
+		application.WithAlerter(alerter),
+		application.WithNotifier(notifier),
+	)
+
+	stdin.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any(), orc).DoAndReturn(
+		func(ctx context.Context, output chan<- domain.LogEvent, errChan chan<- error, shutdown ports.IngestionShutdown) {
+			shutdown.OnShutdown()
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		orc.Execute()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	orc.Shutdown()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("The shutdown waited for the notification past the shutdown timeout")
+	}
+
+	errs := orc.GetErrors()
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("Expected the cancelled notification, got %v", errs)
+	}
+}
@@ -0,0 +1,289 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	AI_PROVIDER_OPENAI = "openai"
+	AI_PROVIDER_OLLAMA = "ollama"
+	AI_PROVIDER_GEMINI = "gemini"
+	// AI_PROVIDER_HEURISTIC matches the events against known failure signatures, without any model
+	AI_PROVIDER_HEURISTIC = "heuristic"
+
+	OLLAMA_ENDPOINT_CHAT     = "chat"
+	OLLAMA_ENDPOINT_GENERATE = "generate"
+)
+
+var ErrInvalidAIProvider = errors.New("invalid ai provider")
+
+// AIConfig lists the providers the analyze stages call, in the order they are tried
+type AIConfig struct {
+	Providers []AIProviderConfig  `yaml:"providers"`
+	Cache     AnalysisCacheConfig `yaml:"cache"`
+	Budget    CostBudgetConfig    `yaml:"budget"`
+}
+
+// CostBudgetConfig caps the estimated cost of the analyses over each Period, in total with Limit and
+// for the named pipelines with Pipelines. The paid providers aren't called once the budget is spent,
+// a zero limit is unlimited. Pipelines is a list since the config loader lowercases the keys of maps
+type CostBudgetConfig struct {
+	Limit     float64          `yaml:"limit"`
+	Period    time.Duration    `yaml:"period"`
+	Pipelines []PipelineBudget `yaml:"pipelines"`
+}
+
+// PipelineBudget caps the estimated cost of the analyses of Pipeline
+type PipelineBudget struct {
+	Pipeline string  `yaml:"pipeline"`
+	Limit    float64 `yaml:"limit"`
+}
+
+func (c CostBudgetConfig) Validate() error {
+	if c.Limit < 0 || c.Period < 0 {
+		return fmt.Errorf("%w: budget limit and period must be positive", ErrInvalidAIProvider)
+	}
+
+	for _, budget := range c.Pipelines {
+		if budget.Pipeline == "" || budget.Limit < 0 {
+			return fmt.Errorf("%w: budget of pipeline %q needs a name and a positive limit", ErrInvalidAIProvider, budget.Pipeline)
+		}
+	}
+
+	return nil
+}
+
+// LimitOf returns the budget of the pipeline, zero when it has none
+func (c CostBudgetConfig) LimitOf(pipeline string) float64 {
+	for _, budget := range c.Pipelines {
+		if budget.Pipeline == pipeline {
+			return budget.Limit
+		}
+	}
+
+	return 0
+}
+
+// AnalysisCacheConfig keeps the analyses for TTL, and up to MaxEntries of them, so the next occurrences
+// of an event reuse its analysis. The cache is saved to Path when set
+type AnalysisCacheConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	TTL        time.Duration `yaml:"ttl"`
+	MaxEntries int           `yaml:"max_entries" mapstructure:"max_entries"`
+	Path       string        `yaml:"path"`
+}
+
+func (c AnalysisCacheConfig) Validate() error {
+	if c.TTL < 0 || c.MaxEntries < 0 {
+		return fmt.Errorf("%w: cache ttl and max_entries must be positive", ErrInvalidAIProvider)
+	}
+
+	return nil
+}
+
+func (c AIConfig) Validate() error {
+	names := make(map[string]bool, len(c.Providers))
+
+	for _, provider := range c.Providers {
+		if err := provider.Validate(); err != nil {
+			return err
+		}
+
+		if names[provider.ProviderName()] {
+			return fmt.Errorf("%w: duplicated name %s", ErrInvalidAIProvider, provider.ProviderName())
+		}
+		names[provider.ProviderName()] = true
+	}
+
+	if err := c.Cache.Validate(); err != nil {
+		return err
+	}
+
+	return c.Budget.Validate()
+}
+
+// AIProviderConfig sets how a model is called. The API key is read from APIKeyEnv, or from APIKeyFile,
+// when APIKey is empty. The requests taking longer than Timeout are cancelled, the ones failing on a transient error
+// are retried MaxRetries times, waiting RetryBackoff doubled on each attempt. Models overrides Model
+// for the requests of the named pipelines, it is a list since the config loader lowercases the keys
+// of maps. RequestsPerMinute and TokensPerMinute limit how much the
+// provider is called, Pricing estimates the cost of the analyses
+type AIProviderConfig struct {
+	Name         string          `yaml:"name"`
+	Type         string          `yaml:"type"`
+	BaseURL      string          `yaml:"base_url" mapstructure:"base_url"`
+	Model        string          `yaml:"model"`
+	Models       []PipelineModel `yaml:"models"`
+	APIKey       string          `yaml:"api_key" mapstructure:"api_key"`
+	APIKeyEnv    string          `yaml:"api_key_env" mapstructure:"api_key_env"`
+	APIKeyFile   string          `yaml:"api_key_file" mapstructure:"api_key_file"`
+	Timeout      time.Duration   `yaml:"timeout"`
+	MaxRetries   int             `yaml:"max_retries" mapstructure:"max_retries"`
+	RetryBackoff time.Duration   `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	Temperature  float64         `yaml:"temperature"`
+	MaxTokens    int             `yaml:"max_tokens" mapstructure:"max_tokens"`
+	Ollama       OllamaConfig    `yaml:"ollama"`
+	Heuristic    HeuristicConfig `yaml:"heuristic"`
+
+	RequestsPerMinute int       `yaml:"requests_per_minute" mapstructure:"requests_per_minute"`
+	TokensPerMinute   int       `yaml:"tokens_per_minute" mapstructure:"tokens_per_minute"`
+	Pricing           AIPricing `yaml:"pricing"`
+}
+
+// AIPricing is the price of a million prompt and completion tokens, a provider without price is free
+type AIPricing struct {
+	Prompt     float64 `yaml:"prompt"`
+	Completion float64 `yaml:"completion"`
+}
+
+// Paid reports whether the provider charges for the tokens
+func (p AIPricing) Paid() bool {
+	return p.Prompt > 0 || p.Completion > 0
+}
+
+// Cost returns the estimated cost of the tokens
+func (p AIPricing) Cost(usage TokenUsage) float64 {
+	return (float64(usage.Prompt)*p.Prompt + float64(usage.Completion)*p.Completion) / 1_000_000
+}
+
+// OllamaConfig sets the Ollama endpoint, chat or generate, whether the answer is streamed, and
+// how long the model stays loaded after a request, a negative duration keeps it loaded
+type OllamaConfig struct {
+	Endpoint  string        `yaml:"endpoint"`
+	Stream    bool          `yaml:"stream"`
+	KeepAlive time.Duration `yaml:"keep_alive" mapstructure:"keep_alive"`
+}
+
+// HeuristicConfig is the rule pack of the heuristic provider. The rules are tried in order before
+// the built-in ones, ReplaceDefaults drops the built-in rules
+type HeuristicConfig struct {
+	Rules           []HeuristicRule `yaml:"rules"`
+	ReplaceDefaults bool            `yaml:"replace_defaults" mapstructure:"replace_defaults"`
+}
+
+// HeuristicRule is a failure signature, a regular expression matched against the messages, with the
+// analysis returned when it matches. A rule without confidence is given 0.5
+type HeuristicRule struct {
+	Name          string  `yaml:"name"`
+	Pattern       string  `yaml:"pattern"`
+	Summary       string  `yaml:"summary"`
+	ProbableCause string  `yaml:"probable_cause" mapstructure:"probable_cause"`
+	SuggestedFix  string  `yaml:"suggested_fix" mapstructure:"suggested_fix"`
+	Confidence    float64 `yaml:"confidence"`
+}
+
+func (r HeuristicRule) Validate() error {
+	if r.Name == "" || r.Pattern == "" || r.Summary == "" {
+		return fmt.Errorf("%w: heuristic rule %q: name, pattern and summary are required", ErrInvalidAIProvider, r.Name)
+	}
+
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("%w: heuristic rule %s: %w", ErrInvalidAIProvider, r.Name, err)
+	}
+
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return fmt.Errorf("%w: heuristic rule %s: confidence must be between 0 and 1", ErrInvalidAIProvider, r.Name)
+	}
+
+	return nil
+}
+
+func (c AIProviderConfig) Validate() error {
+	if c.Type == AI_PROVIDER_HEURISTIC {
+		for _, rule := range c.Heuristic.Rules {
+			if err := rule.Validate(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	switch c.Type {
+	case AI_PROVIDER_OPENAI, AI_PROVIDER_GEMINI:
+	case AI_PROVIDER_OLLAMA:
+		switch c.Ollama.Endpoint {
+		case "", OLLAMA_ENDPOINT_CHAT, OLLAMA_ENDPOINT_GENERATE:
+		default:
+			return fmt.Errorf("%w: ollama: unknown endpoint %q", ErrInvalidAIProvider, c.Ollama.Endpoint)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAIProvider, c.Type)
+	}
+
+	if c.Model == "" {
+		return fmt.Errorf("%w: %s: model is required", ErrInvalidAIProvider, c.Type)
+	}
+
+	for _, model := range c.Models {
+		if model.Pipeline == "" || model.Model == "" {
+			return fmt.Errorf("%w: %s: missing pipeline or model of %q", ErrInvalidAIProvider, c.Type, model.Pipeline)
+		}
+	}
+
+	if c.Timeout < 0 || c.MaxRetries < 0 || c.RetryBackoff < 0 || c.MaxTokens < 0 ||
+		c.RequestsPerMinute < 0 || c.TokensPerMinute < 0 || c.Pricing.Prompt < 0 || c.Pricing.Completion < 0 {
+		return fmt.Errorf("%w: %s: durations and limits must be positive", ErrInvalidAIProvider, c.Type)
+	}
+
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return fmt.Errorf("%w: %s: temperature must be between 0 and 2", ErrInvalidAIProvider, c.Type)
+	}
+
+	return nil
+}
+
+// PipelineModel is the model used for the requests of Pipeline
+type PipelineModel struct {
+	Pipeline string `yaml:"pipeline"`
+	Model    string `yaml:"model"`
+}
+
+// ProviderName returns the name of the provider, its type when it has no name
+func (c AIProviderConfig) ProviderName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.Type
+}
+
+// ModelFor returns the model used for the requests of the pipeline
+func (c AIProviderConfig) ModelFor(pipeline string) string {
+	for _, model := range c.Models {
+		if model.Pipeline == pipeline {
+			return model.Model
+		}
+	}
+
+	return c.Model
+}
+
+// Key returns the API key, set in the config, read from the environment or from the file
+func (c AIProviderConfig) Key() (string, error) {
+	if c.APIKey != "" {
+		return c.APIKey, nil
+	}
+
+	if c.APIKeyEnv != "" {
+		if key := os.Getenv(c.APIKeyEnv); key != "" {
+			return key, nil
+		}
+	}
+
+	if c.APIKeyFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(c.APIKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: api key file: %w", ErrInvalidAIProvider, c.Type, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
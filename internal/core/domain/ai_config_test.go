@@ -0,0 +1,218 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIProviderConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        domain.AIProviderConfig
+		expectedError error
+	}{
+		{
+			name:   "valid openai provider",
+			config: domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "gpt-4o-mini", Timeout: time.Second},
+		},
+		{
+			name: "valid ollama provider",
+			config: domain.AIProviderConfig{
+				Type:   domain.AI_PROVIDER_OLLAMA,
+				Model:  "llama3",
+				Models: []domain.PipelineModel{{Pipeline: "payments", Model: "qwen2.5"}},
+				Ollama: domain.OllamaConfig{Endpoint: domain.OLLAMA_ENDPOINT_GENERATE, Stream: true},
+			},
+		},
+		{
+			name:          "unknown ollama endpoint",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OLLAMA, Model: "llama3", Ollama: domain.OllamaConfig{Endpoint: "embed"}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "missing model of pipeline",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OLLAMA, Model: "llama3", Models: []domain.PipelineModel{{Pipeline: "payments", Model: ""}}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "unknown type",
+			config:        domain.AIProviderConfig{Type: "claude", Model: "x"},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "missing model",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "negative retries",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "x", MaxRetries: -1},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name: "valid heuristic provider",
+			config: domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC, Heuristic: domain.HeuristicConfig{Rules: []domain.HeuristicRule{
+				{Name: "pool", Pattern: "too many connections", Summary: "pool exhausted", Confidence: 0.6},
+			}}},
+		},
+		{
+			name: "heuristic rule without pattern",
+			config: domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC, Heuristic: domain.HeuristicConfig{Rules: []domain.HeuristicRule{
+				{Name: "pool", Summary: "pool exhausted"},
+			}}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name: "heuristic rule with invalid pattern",
+			config: domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC, Heuristic: domain.HeuristicConfig{Rules: []domain.HeuristicRule{
+				{Name: "pool", Pattern: "(", Summary: "pool exhausted"},
+			}}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name: "heuristic rule confidence out of range",
+			config: domain.AIProviderConfig{Type: domain.AI_PROVIDER_HEURISTIC, Heuristic: domain.HeuristicConfig{Rules: []domain.HeuristicRule{
+				{Name: "pool", Pattern: "pool", Summary: "pool exhausted", Confidence: 2},
+			}}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "negative tokens per minute",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "x", TokensPerMinute: -1},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "negative price",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "x", Pricing: domain.AIPricing{Completion: -1}},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+		{
+			name:          "temperature out of range",
+			config:        domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "x", Temperature: 3},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.config.Validate(), tt.expectedError)
+		})
+	}
+}
+
+func TestAIProviderConfig_Key(t *testing.T) {
+	t.Setenv("LOG_GUARDIAN_TEST_KEY", "from-env")
+
+	file := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+
+	tests := []struct {
+		name          string
+		config        domain.AIProviderConfig
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "inline key first",
+			config:   domain.AIProviderConfig{APIKey: "inline", APIKeyEnv: "LOG_GUARDIAN_TEST_KEY", APIKeyFile: file},
+			expected: "inline",
+		},
+		{
+			name:     "environment before file",
+			config:   domain.AIProviderConfig{APIKeyEnv: "LOG_GUARDIAN_TEST_KEY", APIKeyFile: file},
+			expected: "from-env",
+		},
+		{
+			name:     "file when the variable is empty",
+			config:   domain.AIProviderConfig{APIKeyEnv: "LOG_GUARDIAN_UNSET_KEY", APIKeyFile: file},
+			expected: "from-file",
+		},
+		{
+			name:   "no key",
+			config: domain.AIProviderConfig{},
+		},
+		{
+			name:          "missing file",
+			config:        domain.AIProviderConfig{APIKeyFile: filepath.Join(t.TempDir(), "missing")},
+			expectedError: domain.ErrInvalidAIProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.config.Key()
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, key)
+		})
+	}
+}
+
+func TestAIProviderConfig_ModelFor(t *testing.T) {
+	config := domain.AIProviderConfig{Model: "llama3", Models: []domain.PipelineModel{{Pipeline: "payments", Model: "qwen2.5"}}}
+
+	assert.Equal(t, "qwen2.5", config.ModelFor("payments"))
+	assert.Equal(t, "llama3", config.ModelFor("default"))
+	assert.Equal(t, "llama3", config.ModelFor(""))
+}
+
+func TestCostBudgetConfig_LimitOf(t *testing.T) {
+	config := loadYAML(t, `
+ai:
+  budget:
+    limit: 10
+    pipelines:
+      - pipeline: Payments
+        limit: 2.5
+`)
+
+	require.NoError(t, config.AI.Budget.Validate())
+	assert.Equal(t, 2.5, config.AI.Budget.LimitOf("Payments"), "the pipeline name keeps its case")
+	assert.Zero(t, config.AI.Budget.LimitOf("search"))
+
+	config.AI.Budget.Pipelines = []domain.PipelineBudget{{Limit: 1}}
+	assert.ErrorIs(t, config.AI.Budget.Validate(), domain.ErrInvalidAIProvider)
+}
+
+func TestAIProviderConfig_ModelsFromConfig(t *testing.T) {
+	config := loadYAML(t, `
+ai:
+  providers:
+    - type: ollama
+      model: llama3
+      models:
+        - pipeline: Payments
+          model: qwen2.5
+`)
+
+	provider := config.AI.Providers[0]
+	require.NoError(t, provider.Validate())
+	assert.Equal(t, "qwen2.5", provider.ModelFor("Payments"), "the pipeline name keeps its case")
+}
+
+func TestAIConfig_Validate(t *testing.T) {
+	valid := domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, Model: "gpt-4o-mini"}
+	local := domain.AIProviderConfig{Name: "local", Type: domain.AI_PROVIDER_OPENAI, Model: "llama3"}
+
+	assert.NoError(t, domain.AIConfig{}.Validate())
+	assert.NoError(t, domain.AIConfig{Providers: []domain.AIProviderConfig{valid, local}}.Validate())
+	assert.ErrorIs(t, domain.AIConfig{Providers: []domain.AIProviderConfig{valid, valid}}.Validate(), domain.ErrInvalidAIProvider)
+	assert.ErrorIs(t, domain.AIConfig{Providers: []domain.AIProviderConfig{{Type: "unknown"}}}.Validate(), domain.ErrInvalidAIProvider)
+	assert.ErrorIs(t, domain.AIConfig{Cache: domain.AnalysisCacheConfig{TTL: -time.Hour}}.Validate(), domain.ErrInvalidAIProvider)
+	assert.ErrorIs(t, domain.AIConfig{Budget: domain.CostBudgetConfig{Limit: -1}}.Validate(), domain.ErrInvalidAIProvider)
+	assert.ErrorIs(t, domain.AIConfig{Budget: domain.CostBudgetConfig{Pipelines: []domain.PipelineBudget{{Pipeline: "payments", Limit: -1}}}}.Validate(), domain.ErrInvalidAIProvider)
+}
+
+func TestAIPricing(t *testing.T) {
+	pricing := domain.AIPricing{Prompt: 2.5, Completion: 10}
+
+	assert.True(t, pricing.Paid())
+	assert.False(t, domain.AIPricing{}.Paid())
+	assert.InDelta(t, 0.0035, pricing.Cost(domain.TokenUsage{Prompt: 1000, Completion: 100}), 1e-12)
+}
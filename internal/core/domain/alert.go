@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AlertState string
+
+const (
+	ALERT_STATE_FIRING   AlertState = "firing"
+	ALERT_STATE_RESOLVED AlertState = "resolved"
+)
+
+// Alert is raised by an alert rule for one group of events, it is sent when it fires and again
+// when it resolves
+type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
+	Rule        string            `json:"rule"`
+	State       AlertState        `json:"state"`
+	Severity    LogLevel          `json:"severity"`
+	Summary     string            `json:"summary"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Count       int               `json:"count"`
+	StartsAt    time.Time         `json:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at,omitempty"`
+	Samples     []LogEvent        `json:"samples,omitempty"`
+}
+
+// Pipelines returns the pipelines the samples of the alert were routed to
+func (a Alert) Pipelines() []string {
+	var pipelines []string
+	for _, sample := range a.Samples {
+		if name, ok := sample.Metadata[METADATA_PIPELINE].(string); ok && name != "" && !slices.Contains(pipelines, name) {
+			pipelines = append(pipelines, name)
+		}
+	}
+
+	return pipelines
+}
+
+// AlertFingerprint identifies the alert of a rule and a group, the labels order doesn't matter
+func AlertFingerprint(rule string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(rule)
+	for _, key := range keys {
+		sb.WriteString("\x00" + key + "=" + labels[key])
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+
+	return hex.EncodeToString(sum[:])[:16]
+}
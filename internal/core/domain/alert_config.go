@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+type AlertingConfig struct {
+	Rules []AlertRuleConfig `yaml:"rules"`
+}
+
+// AlertRuleConfig fires when more than Threshold events matching Condition were seen within
+// Window, for each group of GroupBy values. The alert only fires once the threshold was exceeded
+// for the For duration, and resolves when the count stayed at or below ResolveThreshold, Threshold
+// by default, for ResolveAfter. Summary is a text/template over .Rule, .Count, .Threshold, .Window
+// and .Labels
+type AlertRuleConfig struct {
+	Name             string        `yaml:"name"`
+	Condition        string        `yaml:"condition"`
+	Threshold        int           `yaml:"threshold"`
+	Window           time.Duration `yaml:"window"`
+	GroupBy          []string      `yaml:"group_by" mapstructure:"group_by"`
+	For              time.Duration `yaml:"for"`
+	ResolveThreshold *int          `yaml:"resolve_threshold" mapstructure:"resolve_threshold"`
+	ResolveAfter     time.Duration `yaml:"resolve_after" mapstructure:"resolve_after"`
+	Severity         LogLevel      `yaml:"severity"`
+	Summary          string        `yaml:"summary"`
+}
+
+// Validate checks the rules settings, conditions and summaries are checked when the rules are built
+func (a AlertingConfig) Validate() error {
+	names := make(map[string]bool, len(a.Rules))
+
+	for i, rule := range a.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%w: rule %d: %w", ErrInvalidAlertRule, i, err)
+		}
+
+		if names[rule.Name] {
+			return fmt.Errorf("%w: duplicated name %s", ErrInvalidAlertRule, rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	return nil
+}
+
+func (r AlertRuleConfig) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	if r.Threshold < 0 || r.Window < 0 || r.For < 0 || r.ResolveAfter < 0 {
+		return fmt.Errorf("threshold and durations must be positive")
+	}
+
+	if r.ResolveThreshold != nil && (*r.ResolveThreshold < 0 || *r.ResolveThreshold > r.Threshold) {
+		return fmt.Errorf("resolve threshold must be between 0 and the threshold")
+	}
+
+	for _, field := range r.GroupBy {
+		if !IsEventField(field) {
+			return fmt.Errorf("unknown group by field %q", field)
+		}
+	}
+
+	if r.Severity != "" && !r.Severity.IsValid() {
+		return fmt.Errorf("unknown severity %s", r.Severity)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertingConfig_Validate(t *testing.T) {
+	negative := -1
+	above := 30
+
+	tests := []struct {
+		name    string
+		rules   []domain.AlertRuleConfig
+		wantErr bool
+	}{
+		{
+			name: "valid rules",
+			rules: []domain.AlertRuleConfig{
+				{Name: "prod-errors", Condition: `severity >= ERROR`, Threshold: 20, Window: 5 * time.Minute, GroupBy: []string{"metadata.namespace"}},
+				{Name: "any-fatal", Condition: `severity == FATAL`, Severity: domain.LOG_LEVEL_FATAL},
+			},
+		},
+		{name: "missing name", rules: []domain.AlertRuleConfig{{}}, wantErr: true},
+		{name: "duplicated name", rules: []domain.AlertRuleConfig{{Name: "a"}, {Name: "a"}}, wantErr: true},
+		{name: "negative threshold", rules: []domain.AlertRuleConfig{{Name: "a", Threshold: -1}}, wantErr: true},
+		{name: "negative resolve threshold", rules: []domain.AlertRuleConfig{{Name: "a", ResolveThreshold: &negative}}, wantErr: true},
+		{name: "resolve threshold above threshold", rules: []domain.AlertRuleConfig{{Name: "a", Threshold: 20, ResolveThreshold: &above}}, wantErr: true},
+		{name: "unknown group by field", rules: []domain.AlertRuleConfig{{Name: "a", GroupBy: []string{"pod"}}}, wantErr: true},
+		{name: "unknown severity", rules: []domain.AlertRuleConfig{{Name: "a", Severity: "LOUD"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.AlertingConfig{Rules: tt.rules}.Validate()
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidAlertRule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAlertFingerprint(t *testing.T) {
+	a := domain.AlertFingerprint("rule", map[string]string{"a": "1", "b": "2"})
+	b := domain.AlertFingerprint("rule", map[string]string{"b": "2", "a": "1"})
+	c := domain.AlertFingerprint("other", map[string]string{"a": "1", "b": "2"})
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 16)
+}
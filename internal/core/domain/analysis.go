@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAnalysisFailed is returned when the provider rejects the request, retrying won't help
+	ErrAnalysisFailed = errors.New("analysis failed")
+	// ErrProviderUnavailable is returned when the provider can't be reached, times out or fails
+	ErrProviderUnavailable = errors.New("ai provider unavailable")
+	// ErrRateLimited is returned when the provider keeps rate limiting the requests
+	ErrRateLimited = errors.New("ai provider rate limited")
+	// ErrInvalidAnalysis is returned when the answer of the model isn't a valid analysis
+	ErrInvalidAnalysis = errors.New("invalid analysis")
+	// ErrContentBlocked is returned when the provider refuses the prompt or the answer for safety
+	ErrContentBlocked = errors.New("content blocked by the ai provider")
+	// ErrBudgetExhausted is returned when the budget of the analyses is spent
+	ErrBudgetExhausted = errors.New("ai budget exhausted")
+)
+
+// AnalysisRequest is what the model is asked about, the event with the events surrounding it, and
+// the incident it belongs to when there is one. Pipeline names the pipeline asking for it, Prompt
+// is the prompt rendered by the pipeline, the providers describe the event themselves without it
+type AnalysisRequest struct {
+	Pipeline string
+	Prompt   string
+	Event    LogEvent
+	Context  []LogEvent
+	Incident *Incident
+}
+
+// Analysis is the structured answer of a model about an event or an incident. Fingerprint identifies
+// the events sharing the analysis, Cached is set when it was answered for a previous occurrence. Cost
+// is estimated from the pricing of the provider. Heuristic is set when it comes from the known
+// failure signatures instead of a model, such an analysis is not cached so the models are asked again
+type Analysis struct {
+	Summary       string     `json:"summary"`
+	ProbableCause string     `json:"probable_cause"`
+	SuggestedFix  string     `json:"suggested_fix"`
+	Confidence    float64    `json:"confidence"`
+	Fingerprint   string     `json:"fingerprint,omitempty"`
+	Cached        bool       `json:"cached,omitempty"`
+	Provider      string     `json:"provider,omitempty"`
+	Model         string     `json:"model,omitempty"`
+	Usage         TokenUsage `json:"usage"`
+	Cost          float64    `json:"cost,omitempty"`
+	Heuristic     bool       `json:"heuristic,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TokenUsage counts the tokens of the prompt and of the answer
+type TokenUsage struct {
+	Prompt     int `json:"prompt"`
+	Completion int `json:"completion"`
+}
+
+func (u TokenUsage) Total() int {
+	return u.Prompt + u.Completion
+}
+
+// AIUsage sums the analyses made by a provider for a pipeline
+type AIUsage struct {
+	Pipeline string
+	Provider string
+	Requests int
+	Tokens   TokenUsage
+	Cost     float64
+}
+
+// CachedAnalysis is an analysis kept for the next occurrences of the events with the fingerprint
+type CachedAnalysis struct {
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	Analysis    Analysis  `json:"analysis"`
+	CachedAt    time.Time `json:"cached_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Hits        int       `json:"hits"`
+}
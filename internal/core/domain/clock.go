@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+type Clock interface {
+	Now() time.Time
+}
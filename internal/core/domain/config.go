@@ -18,8 +18,15 @@ var (
 )
 
 type RuntimeConfig struct {
-	ShutdownTimeout int     `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
-	Ingests         Ingests `yaml:"ingests" mapstructure:"ingests"`
+	ShutdownTimeout int              `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
+	Ingests         Ingests          `yaml:"ingests" mapstructure:"ingests"`
+	Pipelines       []PipelineConfig `yaml:"pipelines" mapstructure:"pipelines"`
+	Priority        PriorityConfig   `yaml:"priority" mapstructure:"priority"`
+	Routing         RoutingConfig    `yaml:"routing" mapstructure:"routing"`
+	Alerting        AlertingConfig   `yaml:"alerting" mapstructure:"alerting"`
+	Incidents       IncidentConfig   `yaml:"incidents" mapstructure:"incidents"`
+	AI              AIConfig         `yaml:"ai" mapstructure:"ai"`
+	Notify          NotifyConfig     `yaml:"notify" mapstructure:"notify"`
 }
 
 type Ingests struct {
@@ -29,13 +36,15 @@ type Ingests struct {
 }
 
 type StdinConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled bool         `yaml:"enabled"`
+	Enrich  EnrichConfig `yaml:"enrich"`
 }
 
 type FileConfig struct {
 	Enabled bool `yaml:"enabled"`
 
 	Folders []FolderConfig `yaml:"folders"`
+	Enrich  EnrichConfig   `yaml:"enrich"`
 }
 
 type FolderConfig struct {
@@ -47,6 +56,7 @@ type UnixConfig struct {
 	Enabled bool `yaml:"enabled"`
 
 	Sockets []UnixSocket `yaml:"sockets"`
+	Enrich  EnrichConfig `yaml:"enrich"`
 }
 
 type UnixSocket struct {
@@ -54,6 +64,12 @@ type UnixSocket struct {
 	Timeout int64  `yaml:"timeout"`
 }
 
+// EnrichConfig controls which metadata is attached to the events of an input
+type EnrichConfig struct {
+	Host        bool `yaml:"host"`
+	ContainerID bool `yaml:"container_id" mapstructure:"container_id"`
+}
+
 func (c *RuntimeConfig) Validate() error {
 	if c.ShutdownTimeout <= 0 {
 		return ErrInvalidShutdownTimeout
@@ -68,6 +84,48 @@ func (c *RuntimeConfig) Validate() error {
 		}
 	}
 
+	if err := c.Priority.Validate(); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(c.Pipelines))
+	for _, pipeline := range c.Pipelines {
+		if err := pipeline.Validate(); err != nil {
+			return err
+		}
+
+		if names[pipeline.Name] {
+			return fmt.Errorf("%w: duplicated name %s", ErrInvalidPipeline, pipeline.Name)
+		}
+		names[pipeline.Name] = true
+	}
+
+	if err := c.Routing.Validate(c.Pipelines); err != nil {
+		return err
+	}
+
+	if err := c.Alerting.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Incidents.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.AI.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Notify.Validate(); err != nil {
+		return err
+	}
+
+	for _, notifier := range c.Notify.Notifiers {
+		if err := checkPipelines(notifier.Pipelines, names); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidNotifier, notifier.NotifierName(), err)
+		}
+	}
+
 	return nil
 }
 
@@ -87,6 +145,12 @@ func setupViper(c *RuntimeConfig) (err error) {
 
 	v.SetDefault("ingests.file.folders", []FolderConfig{})
 	v.SetDefault("ingests.unix.sockets", []UnixSocket{})
+	v.SetDefault("ingests.stdin.enrich.host", false)
+	v.SetDefault("ingests.file.enrich.host", false)
+	v.SetDefault("ingests.unix.enrich.host", false)
+
+	v.SetDefault("incidents.enabled", false)
+	v.SetDefault("incidents.state_path", "incidents.json")
 
 	// Load config from file
 	v.SetConfigFile("./config.yaml")
@@ -4,12 +4,28 @@ import (
 	"log-guardian/internal/core/domain"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// loadYAML reads the config through viper as the config file is, viper lowercases the keys of the maps
+func loadYAML(t *testing.T, content string) domain.RuntimeConfig {
+	t.Helper()
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(content)))
+
+	var config domain.RuntimeConfig
+	require.NoError(t, v.Unmarshal(&config))
+
+	return config
+}
+
 func TestRuntimeConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FIELD_ID        = "id"
+	FIELD_TIMESTAMP = "timestamp"
+	FIELD_SOURCE    = "source"
+	FIELD_SEVERITY  = "severity"
+	FIELD_MESSAGE   = "message"
+	FIELD_METADATA  = "metadata."
+)
+
+var (
+	ErrUnknownField    = errors.New("unknown field")
+	ErrInvalidSeverity = errors.New("invalid severity")
+)
+
+// IsEventField reports whether the path addresses a field of the event: id, timestamp, source,
+// severity, message or metadata.<key>
+func IsEventField(path string) bool {
+	switch path {
+	case FIELD_ID, FIELD_TIMESTAMP, FIELD_SOURCE, FIELD_SEVERITY, FIELD_MESSAGE:
+		return true
+	}
+
+	return strings.HasPrefix(path, FIELD_METADATA) && len(path) > len(FIELD_METADATA)
+}
+
+// Field returns the value at the path
+func (le LogEvent) Field(path string) (any, bool) {
+	switch path {
+	case FIELD_ID:
+		return le.ID, true
+	case FIELD_TIMESTAMP:
+		return le.Timestamp, true
+	case FIELD_SOURCE:
+		return le.Source, true
+	case FIELD_SEVERITY:
+		return string(le.Severity), true
+	case FIELD_MESSAGE:
+		return le.Message, true
+	}
+
+	if key, ok := strings.CutPrefix(path, FIELD_METADATA); ok {
+		return le.GetMetadata(key)
+	}
+
+	return nil, false
+}
+
+// SetField writes the value at the path. The metadata map is copied before the write, so events
+// sharing the map are not affected
+func (le *LogEvent) SetField(path string, value any) error {
+	switch path {
+	case FIELD_ID:
+		le.ID = fmt.Sprint(value)
+	case FIELD_SOURCE:
+		le.Source = fmt.Sprint(value)
+	case FIELD_MESSAGE:
+		le.Message = fmt.Sprint(value)
+	case FIELD_SEVERITY:
+		level, ok := LogLevelOf(fmt.Sprint(value))
+		if !ok {
+			return fmt.Errorf("%w: %v", ErrInvalidSeverity, value)
+		}
+		le.Severity = level
+	case FIELD_TIMESTAMP:
+		switch ts := value.(type) {
+		case time.Time:
+			le.Timestamp = ts
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return err
+			}
+			le.Timestamp = parsed
+		default:
+			return fmt.Errorf("invalid timestamp: %v", value)
+		}
+	default:
+		key, ok := strings.CutPrefix(path, FIELD_METADATA)
+		if !ok || key == "" {
+			return fmt.Errorf("%w: %s", ErrUnknownField, path)
+		}
+
+		le.Metadata = copyMetadata(le.Metadata, 1)
+		le.Metadata[key] = value
+	}
+
+	return nil
+}
+
+// DeleteField removes a metadata key, the event fields can't be deleted
+func (le *LogEvent) DeleteField(path string) error {
+	key, ok := strings.CutPrefix(path, FIELD_METADATA)
+	if !ok || key == "" {
+		return fmt.Errorf("%w: %s can't be deleted", ErrUnknownField, path)
+	}
+
+	if _, exists := le.Metadata[key]; exists {
+		le.Metadata = copyMetadata(le.Metadata, 0)
+		delete(le.Metadata, key)
+	}
+
+	return nil
+}
+
+func copyMetadata(metadata map[string]interface{}, extra int) map[string]interface{} {
+	copied := make(map[string]interface{}, len(metadata)+extra)
+	for key, value := range metadata {
+		copied[key] = value
+	}
+
+	return copied
+}
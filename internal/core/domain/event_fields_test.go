@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogEvent_Field(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := domain.LogEvent{
+		ID:        "id",
+		Timestamp: ts,
+		Source:    domain.SOURCE_FILE,
+		Severity:  domain.LOG_LEVEL_ERROR,
+		Message:   "boom",
+		Metadata:  map[string]interface{}{"status": 500},
+	}
+
+	tests := []struct {
+		path     string
+		expected any
+		ok       bool
+	}{
+		{path: "id", expected: "id", ok: true},
+		{path: "timestamp", expected: ts, ok: true},
+		{path: "source", expected: domain.SOURCE_FILE, ok: true},
+		{path: "severity", expected: "ERROR", ok: true},
+		{path: "message", expected: "boom", ok: true},
+		{path: "metadata.status", expected: 500, ok: true},
+		{path: "metadata.missing"},
+		{path: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, ok := event.Field(tt.path)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestLogEvent_SetField(t *testing.T) {
+	metadata := map[string]interface{}{"lvl": "warn"}
+	event := domain.LogEvent{Metadata: metadata}
+
+	require.NoError(t, event.SetField("severity", "warn"))
+	require.NoError(t, event.SetField("message", 42))
+	require.NoError(t, event.SetField("timestamp", "2025-01-02T03:04:05Z"))
+	require.NoError(t, event.SetField("metadata.team", "payments"))
+
+	assert.Equal(t, domain.LOG_LEVEL_WARNING, event.Severity)
+	assert.Equal(t, "42", event.Message)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), event.Timestamp)
+	assert.Equal(t, "payments", event.Metadata["team"])
+	assert.NotContains(t, metadata, "team", "the original metadata should not be modified")
+
+	assert.ErrorIs(t, event.SetField("severity", "verbose"), domain.ErrInvalidSeverity)
+	assert.ErrorIs(t, event.SetField("unknown", "x"), domain.ErrUnknownField)
+	assert.Error(t, event.SetField("timestamp", "yesterday"))
+}
+
+func TestLogEvent_DeleteField(t *testing.T) {
+	metadata := map[string]interface{}{"payload": "big"}
+	event := domain.LogEvent{Message: "hello", Metadata: metadata}
+
+	require.NoError(t, event.DeleteField("metadata.payload"))
+	require.NoError(t, event.DeleteField("metadata.missing"))
+
+	assert.NotContains(t, event.Metadata, "payload")
+	assert.Contains(t, metadata, "payload", "the original metadata should not be modified")
+	assert.ErrorIs(t, event.DeleteField("message"), domain.ErrUnknownField)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	ipv6Group = `[0-9A-Fa-f]{1,4}`
+	ipv4Octet = `(?:25[0-5]|2[0-4]\d|1?\d?\d)`
+	ipv4      = `(?:` + ipv4Octet + `\.){3}` + ipv4Octet
+)
+
+// IPV6_PATTERN matches the IPv6 addresses in the RFC 4291 forms, compressed with "::" anywhere
+// and ending with an IPv4 address. The first matching alternative wins, so the forms keeping
+// more groups come first
+const IPV6_PATTERN = `(?:` +
+	`(?:` + ipv6Group + `:){6}` + ipv4 +
+	`|(?:` + ipv6Group + `:){1,5}(?::` + ipv6Group + `){0,4}:` + ipv4 +
+	`|::(?:[Ff]{4}(?::0{1,4})?:)?` + ipv4 +
+	`|(?:` + ipv6Group + `:){7}` + ipv6Group +
+	`|` + ipv6Group + `:(?::` + ipv6Group + `){1,6}` +
+	`|(?:` + ipv6Group + `:){2}(?::` + ipv6Group + `){1,5}` +
+	`|(?:` + ipv6Group + `:){3}(?::` + ipv6Group + `){1,4}` +
+	`|(?:` + ipv6Group + `:){4}(?::` + ipv6Group + `){1,3}` +
+	`|(?:` + ipv6Group + `:){5}(?::` + ipv6Group + `){1,2}` +
+	`|(?:` + ipv6Group + `:){6}:` + ipv6Group +
+	`|(?:` + ipv6Group + `:){1,7}:` +
+	`|:(?::` + ipv6Group + `){1,7}` +
+	`|::)`
+
+// maskers are applied in order, the most specific patterns come first so a UUID
+// is not partially masked as numbers before it is recognised
+var maskers = []struct {
+	re          *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<uuid>"},
+	// the IPv6 addresses go first as they may end with an IPv4 one, "::" can't follow \b
+	{regexp.MustCompile(`(^|[^\w:])` + IPV6_PATTERN), "${1}<ip>"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*\d[0-9a-fA-F]*\b|\b[0-9a-fA-F]*\d[0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(?:\.\d+)?`), "<num>"},
+}
+
+// NormalizeMessage masks the variable parts of a message (UUIDs, IPs, hex IDs and numbers)
+// so repeated occurrences of the same log line share the same text
+func NormalizeMessage(message string) string {
+	for _, m := range maskers {
+		message = m.re.ReplaceAllString(message, m.placeholder)
+	}
+
+	return strings.TrimSpace(message)
+}
+
+// Fingerprint identifies the normalised message of the event along with the given metadata fields
+func (le LogEvent) Fingerprint(fields ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(NormalizeMessage(le.Message)))
+
+	for _, field := range fields {
+		value, _ := le.GetMetadata(field)
+		fmt.Fprintf(hash, "\x00%s=%v", field, value)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
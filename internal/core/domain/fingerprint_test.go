@@ -0,0 +1,73 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "numbers",
+			message:  "user 42 failed after 3.5s",
+			expected: "user <num> failed after <num>s",
+		},
+		{
+			name:     "uuid and ipv4 with port",
+			message:  "request 3f2b1c4e-1a2b-4c3d-8e9f-0a1b2c3d4e5f from 10.0.0.12:8080",
+			expected: "request <uuid> from <ip>",
+		},
+		{
+			name:     "ipv6",
+			message:  "dial fe80::1 and 2001:db8:85a3:0:0:8a2e:370:7334",
+			expected: "dial <ip> and <ip>",
+		},
+		{
+			name:     "compressed ipv6",
+			message:  "client 2001:db8::8a2e:370:7334 from fe80::1ff:fe23:4567:890a via ::1",
+			expected: "client <ip> from <ip> via <ip>",
+		},
+		{
+			name:     "ipv6 ending with ipv4",
+			message:  "peer ::ffff:192.0.2.128 closed",
+			expected: "peer <ip> closed",
+		},
+		{
+			name:     "hex ids",
+			message:  "ptr 0xdeadbeef at abc123def456",
+			expected: "ptr <hex> at <hex>",
+		},
+		{
+			name:     "plain words are kept",
+			message:  "ERROR in handler: bad face",
+			expected: "ERROR in handler: bad face",
+		},
+		{
+			name:     "surrounding spaces",
+			message:  "  trimmed  ",
+			expected: "trimmed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, domain.NormalizeMessage(tt.message))
+		})
+	}
+}
+
+func TestLogEvent_Fingerprint(t *testing.T) {
+	first := domain.LogEvent{Message: "timeout after 30s", Metadata: map[string]interface{}{"pod": "a"}}
+	second := domain.LogEvent{Message: "timeout after 45s", Metadata: map[string]interface{}{"pod": "b"}}
+
+	assert.Equal(t, first.Fingerprint(), second.Fingerprint())
+	assert.NotEqual(t, first.Fingerprint("pod"), second.Fingerprint("pod"))
+	assert.NotEqual(t, first.Fingerprint(), domain.LogEvent{Message: "disk full"}.Fingerprint())
+	assert.Len(t, first.Fingerprint(), 16)
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type IncidentStatus string
+
+const (
+	INCIDENT_STATUS_OPEN         IncidentStatus = "open"
+	INCIDENT_STATUS_ACKNOWLEDGED IncidentStatus = "acknowledged"
+	INCIDENT_STATUS_RESOLVED     IncidentStatus = "resolved"
+	INCIDENT_STATUS_REOPENED     IncidentStatus = "reopened"
+
+	TIMELINE_EVENT  = "event"
+	TIMELINE_ALERT  = "alert"
+	TIMELINE_STATUS = "status"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid incident transition")
+	ErrIncidentNotFound  = errors.New("incident not found")
+)
+
+// transitions lists the statuses reachable from each status
+var transitions = map[IncidentStatus][]IncidentStatus{
+	INCIDENT_STATUS_OPEN:         {INCIDENT_STATUS_ACKNOWLEDGED, INCIDENT_STATUS_RESOLVED},
+	INCIDENT_STATUS_ACKNOWLEDGED: {INCIDENT_STATUS_RESOLVED},
+	INCIDENT_STATUS_RESOLVED:     {INCIDENT_STATUS_REOPENED},
+	INCIDENT_STATUS_REOPENED:     {INCIDENT_STATUS_ACKNOWLEDGED, INCIDENT_STATUS_RESOLVED},
+}
+
+// Incident groups the related events and alerts of a service. Analysis is the answer of the AI
+// analyzer about the incident when it opened, it is only attached to the notifications
+type Incident struct {
+	ID             string          `json:"id"`
+	Service        string          `json:"service"`
+	Title          string          `json:"title"`
+	Status         IncidentStatus  `json:"status"`
+	Severity       LogLevel        `json:"severity"`
+	Fingerprints   []string        `json:"fingerprints"`
+	Pipelines      []string        `json:"pipelines,omitempty"`
+	EventCount     int             `json:"event_count"`
+	AlertCount     int             `json:"alert_count"`
+	OpenedAt       time.Time       `json:"opened_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	AcknowledgedAt time.Time       `json:"acknowledged_at,omitempty"`
+	ResolvedAt     time.Time       `json:"resolved_at,omitempty"`
+	Timeline       []TimelineEntry `json:"timeline"`
+	Samples        []LogEvent      `json:"samples,omitempty"`
+	Analysis       *Analysis       `json:"analysis,omitempty"`
+}
+
+// AnalysisRequest asks about the latest sample of the incident, with the earlier samples as its
+// context. The title stands for the event when no sample was kept
+func (i Incident) AnalysisRequest() AnalysisRequest {
+	request := AnalysisRequest{Incident: &i}
+	if len(i.Pipelines) > 0 {
+		request.Pipeline = i.Pipelines[0]
+	}
+
+	if n := len(i.Samples); n > 0 {
+		request.Event = i.Samples[n-1]
+		request.Context = i.Samples[:n-1]
+	} else {
+		request.Event = LogEvent{Timestamp: i.UpdatedAt, Severity: i.Severity, Message: i.Title}
+	}
+
+	return request
+}
+
+// TimelineEntry is a member event, alert or status change of an incident
+type TimelineEntry struct {
+	At          time.Time `json:"at"`
+	Kind        string    `json:"kind"`
+	Severity    LogLevel  `json:"severity,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Message     string    `json:"message"`
+}
+
+// IsActive reports whether the incident still accepts members
+func (i Incident) IsActive() bool {
+	return i.Status != INCIDENT_STATUS_RESOLVED
+}
+
+// HasFingerprint reports whether a member with the fingerprint already joined the incident
+func (i Incident) HasFingerprint(fingerprint string) bool {
+	for _, f := range i.Fingerprints {
+		if f == fingerprint {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transition changes the status, recording the change in the timeline
+func (i *Incident) Transition(status IncidentStatus, now time.Time) error {
+	allowed := false
+	for _, next := range transitions[i.Status] {
+		allowed = allowed || next == status
+	}
+
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, i.Status, status)
+	}
+
+	switch status {
+	case INCIDENT_STATUS_ACKNOWLEDGED:
+		i.AcknowledgedAt = now
+	case INCIDENT_STATUS_RESOLVED:
+		i.ResolvedAt = now
+	case INCIDENT_STATUS_REOPENED:
+		i.ResolvedAt = time.Time{}
+		i.AcknowledgedAt = time.Time{}
+	}
+
+	i.Timeline = append(i.Timeline, TimelineEntry{
+		At:      now,
+		Kind:    TIMELINE_STATUS,
+		Message: fmt.Sprintf("%s -> %s", i.Status, status),
+	})
+	i.Status = status
+	i.UpdatedAt = now
+
+	return nil
+}
+
+// AddMember records an event or an alert, raising the severity of the incident to the member's
+func (i *Incident) AddMember(entry TimelineEntry, maxTimeline int) {
+	switch entry.Kind {
+	case TIMELINE_ALERT:
+		i.AlertCount++
+	default:
+		i.EventCount++
+	}
+
+	if entry.Fingerprint != "" && !i.HasFingerprint(entry.Fingerprint) {
+		i.Fingerprints = append(i.Fingerprints, entry.Fingerprint)
+	}
+
+	if entry.Severity.Rank() > i.Severity.Rank() {
+		i.Severity = entry.Severity
+	}
+
+	i.Timeline = append(i.Timeline, entry)
+	if maxTimeline > 0 && len(i.Timeline) > maxTimeline {
+		i.Timeline = i.Timeline[len(i.Timeline)-maxTimeline:]
+	}
+
+	if entry.At.After(i.UpdatedAt) {
+		i.UpdatedAt = entry.At
+	}
+}
+
+// AddSamples keeps the latest max member events of the incident
+func (i *Incident) AddSamples(max int, samples ...LogEvent) {
+	i.Samples = append(i.Samples, samples...)
+	if len(i.Samples) > max {
+		i.Samples = append([]LogEvent(nil), i.Samples[len(i.Samples)-max:]...)
+	}
+}
+
+// AddPipelines records the pipelines the members of the incident were routed to
+func (i *Incident) AddPipelines(pipelines ...string) {
+	for _, pipeline := range pipelines {
+		if pipeline != "" && !slices.Contains(i.Pipelines, pipeline) {
+			i.Pipelines = append(i.Pipelines, pipeline)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidIncidents = errors.New("invalid incidents")
+
+// IncidentConfig groups the events with at least MinSeverity and the alerts into incidents. Members
+// sharing the service, read from ServiceField, and the fingerprint join the same incident, as do the
+// members of the same service arriving within Proximity of its last update. Incidents resolve after
+// QuietPeriod without members and reopen when a member arrives within ReopenWindow of the resolution.
+// The state is saved to StatePath, resolved incidents are kept for Retention. Analyze asks the AI
+// providers about the incidents opening or reopening, their notifications carry the analysis
+type IncidentConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	MinSeverity  LogLevel      `yaml:"min_severity" mapstructure:"min_severity"`
+	ServiceField string        `yaml:"service_field" mapstructure:"service_field"`
+	Proximity    time.Duration `yaml:"proximity"`
+	QuietPeriod  time.Duration `yaml:"quiet_period" mapstructure:"quiet_period"`
+	ReopenWindow time.Duration `yaml:"reopen_window" mapstructure:"reopen_window"`
+	Retention    time.Duration `yaml:"retention"`
+	MaxTimeline  int           `yaml:"max_timeline" mapstructure:"max_timeline"`
+	StatePath    string        `yaml:"state_path" mapstructure:"state_path"`
+	Analyze      bool          `yaml:"analyze"`
+}
+
+func (c IncidentConfig) Validate() error {
+	if c.MinSeverity != "" && !c.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %s", ErrInvalidIncidents, c.MinSeverity)
+	}
+
+	if c.ServiceField != "" && !IsEventField(c.ServiceField) {
+		return fmt.Errorf("%w: unknown service field %q", ErrInvalidIncidents, c.ServiceField)
+	}
+
+	if c.Proximity < 0 || c.QuietPeriod < 0 || c.ReopenWindow < 0 || c.Retention < 0 || c.MaxTimeline < 0 {
+		return fmt.Errorf("%w: durations and limits must be positive", ErrInvalidIncidents)
+	}
+
+	return nil
+}
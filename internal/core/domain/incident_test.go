@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncident_Transition(t *testing.T) {
+	tests := []struct {
+		from    domain.IncidentStatus
+		to      domain.IncidentStatus
+		allowed bool
+	}{
+		{from: domain.INCIDENT_STATUS_OPEN, to: domain.INCIDENT_STATUS_ACKNOWLEDGED, allowed: true},
+		{from: domain.INCIDENT_STATUS_OPEN, to: domain.INCIDENT_STATUS_RESOLVED, allowed: true},
+		{from: domain.INCIDENT_STATUS_OPEN, to: domain.INCIDENT_STATUS_REOPENED},
+		{from: domain.INCIDENT_STATUS_ACKNOWLEDGED, to: domain.INCIDENT_STATUS_RESOLVED, allowed: true},
+		{from: domain.INCIDENT_STATUS_ACKNOWLEDGED, to: domain.INCIDENT_STATUS_OPEN},
+		{from: domain.INCIDENT_STATUS_RESOLVED, to: domain.INCIDENT_STATUS_REOPENED, allowed: true},
+		{from: domain.INCIDENT_STATUS_RESOLVED, to: domain.INCIDENT_STATUS_ACKNOWLEDGED},
+		{from: domain.INCIDENT_STATUS_REOPENED, to: domain.INCIDENT_STATUS_ACKNOWLEDGED, allowed: true},
+		{from: domain.INCIDENT_STATUS_REOPENED, to: domain.INCIDENT_STATUS_RESOLVED, allowed: true},
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			incident := domain.Incident{Status: tt.from}
+
+			err := incident.Transition(tt.to, now)
+
+			if !tt.allowed {
+				assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+				assert.Equal(t, tt.from, incident.Status)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, incident.Status)
+			assert.Equal(t, now, incident.UpdatedAt)
+			require.Len(t, incident.Timeline, 1)
+			assert.Equal(t, domain.TIMELINE_STATUS, incident.Timeline[0].Kind)
+		})
+	}
+}
+
+func TestIncident_ReopenClearsResolution(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	incident := domain.Incident{Status: domain.INCIDENT_STATUS_OPEN}
+
+	require.NoError(t, incident.Transition(domain.INCIDENT_STATUS_RESOLVED, now))
+	assert.Equal(t, now, incident.ResolvedAt)
+	assert.False(t, incident.IsActive())
+
+	require.NoError(t, incident.Transition(domain.INCIDENT_STATUS_REOPENED, now.Add(time.Minute)))
+	assert.True(t, incident.ResolvedAt.IsZero())
+	assert.True(t, incident.IsActive())
+}
+
+func TestIncident_AddMember(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	incident := domain.Incident{Severity: domain.LOG_LEVEL_WARNING}
+
+	incident.AddMember(domain.TimelineEntry{At: now, Kind: domain.TIMELINE_EVENT, Severity: domain.LOG_LEVEL_ERROR, Fingerprint: "a"}, 2)
+	incident.AddMember(domain.TimelineEntry{At: now, Kind: domain.TIMELINE_EVENT, Severity: domain.LOG_LEVEL_INFO, Fingerprint: "a"}, 2)
+	incident.AddMember(domain.TimelineEntry{At: now.Add(time.Second), Kind: domain.TIMELINE_ALERT, Severity: domain.LOG_LEVEL_FATAL, Fingerprint: "b"}, 2)
+
+	assert.Equal(t, domain.LOG_LEVEL_FATAL, incident.Severity)
+	assert.Equal(t, 2, incident.EventCount)
+	assert.Equal(t, 1, incident.AlertCount)
+	assert.Equal(t, []string{"a", "b"}, incident.Fingerprints)
+	assert.Len(t, incident.Timeline, 2, "the timeline keeps the latest entries")
+	assert.Equal(t, now.Add(time.Second), incident.UpdatedAt)
+}
+
+func TestIncident_AnalysisRequest(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := domain.LogEvent{Message: "slow upstream"}
+	latest := domain.LogEvent{Message: "payment failed"}
+	incident := domain.Incident{
+		ID:        "inc-1",
+		Title:     "payments errors",
+		Severity:  domain.LOG_LEVEL_ERROR,
+		Pipelines: []string{"payments", "default"},
+		UpdatedAt: now,
+		Samples:   []domain.LogEvent{first, latest},
+	}
+
+	request := incident.AnalysisRequest()
+	assert.Equal(t, "payments", request.Pipeline)
+	assert.Equal(t, latest, request.Event)
+	assert.Equal(t, []domain.LogEvent{first}, request.Context)
+	require.NotNil(t, request.Incident)
+	assert.Equal(t, "inc-1", request.Incident.ID)
+
+	incident.Samples = nil
+	request = incident.AnalysisRequest()
+	assert.Equal(t, domain.LogEvent{Timestamp: now, Severity: domain.LOG_LEVEL_ERROR, Message: "payments errors"}, request.Event,
+		"the title stands for the event without samples")
+	assert.Empty(t, request.Context)
+}
+
+func TestIncidentConfig_Validate(t *testing.T) {
+	assert.NoError(t, domain.IncidentConfig{ServiceField: "metadata.app", MinSeverity: domain.LOG_LEVEL_WARNING}.Validate())
+	assert.ErrorIs(t, domain.IncidentConfig{ServiceField: "app"}.Validate(), domain.ErrInvalidIncidents)
+	assert.ErrorIs(t, domain.IncidentConfig{MinSeverity: "LOUD"}.Validate(), domain.ErrInvalidIncidents)
+	assert.ErrorIs(t, domain.IncidentConfig{QuietPeriod: -time.Second}.Validate(), domain.ErrInvalidIncidents)
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,17 @@ const (
 	SOURCE_FILE  = "file"
 	SOURCE_UNIX  = "unix"
 
+	// METADATA_INPUT identifies the file or socket the event was read from
+	METADATA_INPUT = "input"
+	// METADATA_PID is the process that wrote the event, when the input knows it
+	METADATA_PID = "pid"
+	// METADATA_ANALYSIS holds the Analysis of the event made by an analyze stage
+	METADATA_ANALYSIS = "analysis"
+	// METADATA_PIPELINE names the pipeline the event was routed to
+	METADATA_PIPELINE = "pipeline"
+	// METADATA_TEMPLATE_ID identifies the template mined from the message by a templates stage
+	METADATA_TEMPLATE_ID = "template_id"
+
 	LOG_LEVEL_DEBUG   LogLevel = "DEBUG"
 	LOG_LEVEL_INFO    LogLevel = "INFO"
 	LOG_LEVEL_WARNING LogLevel = "WARNING"
@@ -26,7 +38,35 @@ const (
 
 type LogLevel string
 
-var regexLogLevel = regexp.MustCompile(`\b(DEBUG|INFO|WARNING|ERROR|FATAL)\b`)
+// regexLogLevel finds the level written in a line, as a level or severity key ("level=warn",
+// "\"severity\":\"error\"") or as an upper case word ("ERROR", "[WARN]")
+var regexLogLevel = regexp.MustCompile(`\b(?i:level|severity|lvl)"?\s*[:=]\s*"?([A-Za-z]+)|\b([A-Z]{3,8})\b`)
+
+// logLevelPrefix bounds the start of the line searched for the level, it is written before the message
+const logLevelPrefix = 256
+
+var logLevelAliases = map[string]LogLevel{
+	"TRACE":    LOG_LEVEL_DEBUG,
+	"DEBUG":    LOG_LEVEL_DEBUG,
+	"INFO":     LOG_LEVEL_INFO,
+	"NOTICE":   LOG_LEVEL_INFO,
+	"WARN":     LOG_LEVEL_WARNING,
+	"WARNING":  LOG_LEVEL_WARNING,
+	"ERR":      LOG_LEVEL_ERROR,
+	"ERROR":    LOG_LEVEL_ERROR,
+	"CRIT":     LOG_LEVEL_FATAL,
+	"CRITICAL": LOG_LEVEL_FATAL,
+	"FATAL":    LOG_LEVEL_FATAL,
+	"PANIC":    LOG_LEVEL_FATAL,
+}
+
+var logLevelRanks = map[LogLevel]int{
+	LOG_LEVEL_DEBUG:   1,
+	LOG_LEVEL_INFO:    2,
+	LOG_LEVEL_WARNING: 3,
+	LOG_LEVEL_ERROR:   4,
+	LOG_LEVEL_FATAL:   5,
+}
 
 type LogEvent struct {
 	ID        string                 `json:"id"`
@@ -53,17 +93,42 @@ func NewLogEvent(source string, message string, severity LogLevel, metadata map[
 	}, nil
 }
 
-// ParseLogLevel parses the log level from the message
+// ParseLogLevel parses the log level from the start of the message, accepting the common spellings
+// of a level such as "warn" or "err"
 func ParseLogLevel(message string) *LogLevel {
-	matches := regexLogLevel.FindStringSubmatch(message)
-	if len(matches) > 1 {
-		level := LogLevel(matches[0])
-		return &level
+	if len(message) > logLevelPrefix {
+		message = message[:logLevelPrefix]
+	}
+
+	for _, matches := range regexLogLevel.FindAllStringSubmatch(message, -1) {
+		name := matches[1]
+		if name == "" {
+			name = matches[2]
+		}
+
+		if level, ok := LogLevelOf(name); ok {
+			return &level
+		}
 	}
 
 	return nil
 }
 
+// MessageLogLevel returns the level parsed from the message, INFO when none is written
+func MessageLogLevel(message string) LogLevel {
+	if level := ParseLogLevel(message); level != nil {
+		return *level
+	}
+
+	return LOG_LEVEL_INFO
+}
+
+// LogLevelOf converts a level name, in any of its common spellings, to a LogLevel
+func LogLevelOf(name string) (LogLevel, bool) {
+	level, ok := logLevelAliases[strings.ToUpper(strings.TrimSpace(name))]
+	return level, ok
+}
+
 // AddMetadata add metadata in the log
 func (le *LogEvent) AddMetadata(key, value string) {
 	if le.Metadata == nil {
@@ -88,6 +153,22 @@ func (le *LogEvent) FromJSON(data []byte) error {
 	return json.Unmarshal(data, le)
 }
 
+// LogLevels returns the known log levels from the most to the least severe
+func LogLevels() []LogLevel {
+	return []LogLevel{LOG_LEVEL_FATAL, LOG_LEVEL_ERROR, LOG_LEVEL_WARNING, LOG_LEVEL_INFO, LOG_LEVEL_DEBUG}
+}
+
+// Rank returns the order of the log level, unknown levels rank below DEBUG
+func (ll LogLevel) Rank() int {
+	return logLevelRanks[ll]
+}
+
+// IsValid reports whether the log level is one of the known levels
+func (ll LogLevel) IsValid() bool {
+	_, ok := logLevelRanks[ll]
+	return ok
+}
+
 // Pointer returns a pointer to the log level
 func (ll LogLevel) Pointer() *LogLevel {
 	return &ll
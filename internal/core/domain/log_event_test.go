@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log-guardian/internal/core/domain"
+	"strings"
 	"testing"
 	"time"
 
@@ -166,6 +167,31 @@ func TestParseLogLevel(t *testing.T) {
 			message:  "",
 			expected: nil,
 		},
+		{
+			name:     "alias in brackets",
+			message:  "[WARN] disk at 91%",
+			expected: domain.LOG_LEVEL_WARNING.Pointer(),
+		},
+		{
+			name:     "level key",
+			message:  `time=10:00 level=fatal msg="out of memory"`,
+			expected: domain.LOG_LEVEL_FATAL.Pointer(),
+		},
+		{
+			name:     "severity JSON key",
+			message:  `{"severity": "Error", "msg": "timeout"}`,
+			expected: domain.LOG_LEVEL_ERROR.Pointer(),
+		},
+		{
+			name:     "lower case word",
+			message:  "no error in lower case",
+			expected: nil,
+		},
+		{
+			name:     "log level after the start of the line",
+			message:  strings.Repeat("x", 300) + " ERROR",
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -182,6 +208,34 @@ func TestParseLogLevel(t *testing.T) {
 	}
 }
 
+func TestLogLevelOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected domain.LogLevel
+		ok       bool
+	}{
+		{name: "warn", expected: domain.LOG_LEVEL_WARNING, ok: true},
+		{name: "ERR", expected: domain.LOG_LEVEL_ERROR, ok: true},
+		{name: " trace ", expected: domain.LOG_LEVEL_DEBUG, ok: true},
+		{name: "Critical", expected: domain.LOG_LEVEL_FATAL, ok: true},
+		{name: "verbose"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, ok := domain.LogLevelOf(tt.name)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestMessageLogLevel(t *testing.T) {
+	assert.Equal(t, domain.LOG_LEVEL_ERROR, domain.MessageLogLevel("ERROR payment failed"))
+	assert.Equal(t, domain.LOG_LEVEL_INFO, domain.MessageLogLevel("user logged in"))
+}
+
 func TestLogEvent_AddMetadata(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -496,3 +550,22 @@ func TestLogEvent_WithNilMetadata(t *testing.T) {
 	assert.NotNil(t, event.Metadata)
 	assert.Len(t, event.Metadata, 1)
 }
+
+func TestLogLevel_Rank(t *testing.T) {
+	levels := []domain.LogLevel{
+		domain.LOG_LEVEL_DEBUG,
+		domain.LOG_LEVEL_INFO,
+		domain.LOG_LEVEL_WARNING,
+		domain.LOG_LEVEL_ERROR,
+		domain.LOG_LEVEL_FATAL,
+	}
+
+	for i := 1; i < len(levels); i++ {
+		assert.Greater(t, levels[i].Rank(), levels[i-1].Rank())
+		assert.True(t, levels[i].IsValid())
+	}
+
+	unknown := domain.LogLevel("TRACE")
+	assert.False(t, unknown.IsValid())
+	assert.Less(t, unknown.Rank(), domain.LOG_LEVEL_DEBUG.Rank())
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	NOTIFIER_SLACK   = "slack"
+	NOTIFIER_DISCORD = "discord"
+)
+
+var (
+	ErrInvalidNotifier = errors.New("invalid notifier")
+	// ErrNotificationFailed is returned when a notification couldn't be delivered
+	ErrNotificationFailed = errors.New("notification failed")
+)
+
+// NotifyConfig lists the channels the alerts and the incidents are delivered to
+type NotifyConfig struct {
+	Notifiers []NotifierConfig `yaml:"notifiers"`
+}
+
+func (c NotifyConfig) Validate() error {
+	names := make(map[string]bool, len(c.Notifiers))
+
+	for _, notifier := range c.Notifiers {
+		if err := notifier.Validate(); err != nil {
+			return err
+		}
+
+		if names[notifier.NotifierName()] {
+			return fmt.Errorf("%w: duplicated name %s", ErrInvalidNotifier, notifier.NotifierName())
+		}
+		names[notifier.NotifierName()] = true
+	}
+
+	return nil
+}
+
+// NotifierConfig sets a webhook the alerts and the incidents with at least MinSeverity are posted to.
+// With Pipelines, only the alerts and the incidents of the events routed to these pipelines are
+// posted. The webhook URL is read from WebhookURLEnv when WebhookURL is empty. The requests taking
+// longer than Timeout are cancelled, the rate limited or failing ones are retried MaxRetries times
+type NotifierConfig struct {
+	Name          string        `yaml:"name"`
+	Type          string        `yaml:"type"`
+	WebhookURL    string        `yaml:"webhook_url" mapstructure:"webhook_url"`
+	WebhookURLEnv string        `yaml:"webhook_url_env" mapstructure:"webhook_url_env"`
+	MinSeverity   LogLevel      `yaml:"min_severity" mapstructure:"min_severity"`
+	Pipelines     []string      `yaml:"pipelines"`
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxRetries    int           `yaml:"max_retries" mapstructure:"max_retries"`
+	Slack         SlackConfig   `yaml:"slack"`
+	Discord       DiscordConfig `yaml:"discord"`
+}
+
+// SlackConfig overrides the channel, the name and the icon set on the Slack webhook
+type SlackConfig struct {
+	Channel   string `yaml:"channel"`
+	Username  string `yaml:"username"`
+	IconEmoji string `yaml:"icon_emoji" mapstructure:"icon_emoji"`
+}
+
+// DiscordConfig overrides the name and the avatar set on the Discord webhook
+type DiscordConfig struct {
+	Username  string `yaml:"username"`
+	AvatarURL string `yaml:"avatar_url" mapstructure:"avatar_url"`
+}
+
+func (c NotifierConfig) Validate() error {
+	switch c.Type {
+	case NOTIFIER_SLACK, NOTIFIER_DISCORD:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidNotifier, c.Type)
+	}
+
+	if c.WebhookURL == "" && c.WebhookURLEnv == "" {
+		return fmt.Errorf("%w: %s: webhook_url or webhook_url_env is required", ErrInvalidNotifier, c.NotifierName())
+	}
+
+	if c.WebhookURL != "" {
+		if _, err := url.ParseRequestURI(c.WebhookURL); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidNotifier, c.NotifierName(), err)
+		}
+	}
+
+	if c.MinSeverity != "" && !c.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: %s: unknown severity %s", ErrInvalidNotifier, c.NotifierName(), c.MinSeverity)
+	}
+
+	if c.Timeout < 0 || c.MaxRetries < 0 {
+		return fmt.Errorf("%w: %s: timeout and max_retries must be positive", ErrInvalidNotifier, c.NotifierName())
+	}
+
+	return nil
+}
+
+// NotifierName returns the name of the notifier, its type when it has no name
+func (c NotifierConfig) NotifierName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.Type
+}
+
+// URL returns the webhook URL, set in the config or read from the environment
+func (c NotifierConfig) URL() (string, error) {
+	webhook := c.WebhookURL
+	if webhook == "" {
+		webhook = os.Getenv(c.WebhookURLEnv)
+	}
+
+	if webhook == "" {
+		return "", fmt.Errorf("%w: %s: %s is not set", ErrInvalidNotifier, c.NotifierName(), c.WebhookURLEnv)
+	}
+
+	return webhook, nil
+}
+
+// Accepts reports whether the severity reaches MinSeverity, everything is accepted without it
+func (c NotifierConfig) Accepts(severity LogLevel) bool {
+	return c.MinSeverity == "" || severity.Rank() >= c.MinSeverity.Rank()
+}
+
+// AcceptsAlert reports whether the alert reaches MinSeverity and comes from one of the Pipelines
+func (c NotifierConfig) AcceptsAlert(alert Alert) bool {
+	return c.Accepts(alert.Severity) && c.fromPipelines(alert.Pipelines())
+}
+
+// AcceptsIncident reports whether the incident reaches MinSeverity and comes from one of the Pipelines
+func (c NotifierConfig) AcceptsIncident(incident Incident) bool {
+	return c.Accepts(incident.Severity) && c.fromPipelines(incident.Pipelines)
+}
+
+func (c NotifierConfig) fromPipelines(pipelines []string) bool {
+	if len(c.Pipelines) == 0 {
+		return true
+	}
+
+	for _, pipeline := range pipelines {
+		if slices.Contains(c.Pipelines, pipeline) {
+			return true
+		}
+	}
+
+	return false
+}
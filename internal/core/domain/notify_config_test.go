@@ -0,0 +1,133 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        domain.NotifierConfig
+		expectedError error
+	}{
+		{
+			name:   "valid slack notifier",
+			config: domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURL: "https://hooks.slack.com/services/T/B/X", MinSeverity: domain.LOG_LEVEL_ERROR},
+		},
+		{
+			name:   "valid discord notifier",
+			config: domain.NotifierConfig{Type: domain.NOTIFIER_DISCORD, WebhookURL: "https://discord.com/api/webhooks/1/x", Discord: domain.DiscordConfig{Username: "guardian"}},
+		},
+		{
+			name:   "webhook from the environment",
+			config: domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURLEnv: "SLACK_WEBHOOK", Timeout: time.Second},
+		},
+		{
+			name:          "unknown type",
+			config:        domain.NotifierConfig{Type: "pager", WebhookURL: "https://example.com"},
+			expectedError: domain.ErrInvalidNotifier,
+		},
+		{
+			name:          "missing webhook",
+			config:        domain.NotifierConfig{Type: domain.NOTIFIER_SLACK},
+			expectedError: domain.ErrInvalidNotifier,
+		},
+		{
+			name:          "invalid webhook",
+			config:        domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURL: "hooks.slack.com"},
+			expectedError: domain.ErrInvalidNotifier,
+		},
+		{
+			name:          "unknown severity",
+			config:        domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURL: "https://example.com", MinSeverity: "CRITICAL"},
+			expectedError: domain.ErrInvalidNotifier,
+		},
+		{
+			name:          "negative retries",
+			config:        domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURL: "https://example.com", MaxRetries: -1},
+			expectedError: domain.ErrInvalidNotifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.config.Validate(), tt.expectedError)
+		})
+	}
+}
+
+func TestNotifyConfig_Validate(t *testing.T) {
+	slack := domain.NotifierConfig{Type: domain.NOTIFIER_SLACK, WebhookURL: "https://example.com"}
+	oncall := domain.NotifierConfig{Name: "oncall", Type: domain.NOTIFIER_SLACK, WebhookURL: "https://example.com"}
+
+	assert.NoError(t, domain.NotifyConfig{}.Validate())
+	assert.NoError(t, domain.NotifyConfig{Notifiers: []domain.NotifierConfig{slack, oncall}}.Validate())
+	assert.ErrorIs(t, domain.NotifyConfig{Notifiers: []domain.NotifierConfig{slack, slack}}.Validate(), domain.ErrInvalidNotifier)
+}
+
+func TestNotifierConfig_URL(t *testing.T) {
+	t.Setenv("LOG_GUARDIAN_TEST_WEBHOOK", "https://example.com/from-env")
+
+	url, err := domain.NotifierConfig{WebhookURL: "https://example.com/inline", WebhookURLEnv: "LOG_GUARDIAN_TEST_WEBHOOK"}.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/inline", url)
+
+	url, err = domain.NotifierConfig{WebhookURLEnv: "LOG_GUARDIAN_TEST_WEBHOOK"}.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/from-env", url)
+
+	_, err = domain.NotifierConfig{WebhookURLEnv: "LOG_GUARDIAN_UNSET_WEBHOOK"}.URL()
+	assert.ErrorIs(t, err, domain.ErrInvalidNotifier)
+}
+
+func TestNotifierConfig_Accepts(t *testing.T) {
+	config := domain.NotifierConfig{MinSeverity: domain.LOG_LEVEL_ERROR}
+
+	assert.True(t, config.Accepts(domain.LOG_LEVEL_FATAL))
+	assert.True(t, config.Accepts(domain.LOG_LEVEL_ERROR))
+	assert.False(t, config.Accepts(domain.LOG_LEVEL_WARNING))
+	assert.True(t, domain.NotifierConfig{}.Accepts(domain.LOG_LEVEL_DEBUG))
+}
+
+func TestNotifierConfig_AcceptsPipelines(t *testing.T) {
+	config := domain.NotifierConfig{MinSeverity: domain.LOG_LEVEL_ERROR, Pipelines: []string{"payments"}}
+
+	routed := func(pipeline string) []domain.LogEvent {
+		return []domain.LogEvent{{Metadata: map[string]interface{}{domain.METADATA_PIPELINE: pipeline}}}
+	}
+
+	assert.True(t, config.AcceptsAlert(domain.Alert{Severity: domain.LOG_LEVEL_ERROR, Samples: routed("payments")}))
+	assert.False(t, config.AcceptsAlert(domain.Alert{Severity: domain.LOG_LEVEL_ERROR, Samples: routed("platform")}))
+	assert.False(t, config.AcceptsAlert(domain.Alert{Severity: domain.LOG_LEVEL_WARNING, Samples: routed("payments")}))
+	assert.False(t, config.AcceptsAlert(domain.Alert{Severity: domain.LOG_LEVEL_ERROR}))
+
+	assert.True(t, config.AcceptsIncident(domain.Incident{Severity: domain.LOG_LEVEL_FATAL, Pipelines: []string{"platform", "payments"}}))
+	assert.False(t, config.AcceptsIncident(domain.Incident{Severity: domain.LOG_LEVEL_FATAL, Pipelines: []string{"platform"}}))
+
+	everything := domain.NotifierConfig{}
+	assert.True(t, everything.AcceptsAlert(domain.Alert{Samples: routed("platform")}))
+	assert.True(t, everything.AcceptsIncident(domain.Incident{}))
+}
+
+func TestRuntimeConfig_ValidateNotifierPipelines(t *testing.T) {
+	config := loadYAML(t, `
+shutdown_timeout: 5
+pipelines:
+  - name: payments
+notify:
+  notifiers:
+    - type: slack
+      webhook_url: https://hooks.slack.com/services/T/B/X
+      pipelines: [payments]
+`)
+	require.NoError(t, config.Validate())
+	assert.Equal(t, []string{"payments"}, config.Notify.Notifiers[0].Pipelines)
+
+	config.Notify.Notifiers[0].Pipelines = []string{"search"}
+	assert.ErrorIs(t, config.Validate(), domain.ErrInvalidNotifier)
+}
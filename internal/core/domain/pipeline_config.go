@@ -0,0 +1,609 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	STAGE_FILTER    = "filter"
+	STAGE_DEDUP     = "dedup"
+	STAGE_SAMPLE    = "sample"
+	STAGE_LIMIT     = "rate_limit"
+	STAGE_REDACT    = "redact"
+	STAGE_GROK      = "grok"
+	STAGE_TRANSFORM = "transform"
+	STAGE_TEMPLATES = "templates"
+	STAGE_ANOMALY   = "anomaly"
+	STAGE_CORRELATE = "correlate"
+	STAGE_ANALYZE   = "analyze"
+
+	OUTPUT_FILE = "file"
+
+	SAMPLE_MODE_FIXED     = "fixed"
+	SAMPLE_MODE_RESERVOIR = "reservoir"
+	SAMPLE_MODE_FIRST_N   = "first_n"
+
+	LIMIT_KEY_SOURCE   = "source"
+	LIMIT_KEY_INPUT    = "input"
+	LIMIT_KEY_METADATA = "metadata."
+
+	REDACT_MODE_MASK   = "mask"
+	REDACT_MODE_HASH   = "hash"
+	REDACT_MODE_REMOVE = "remove"
+
+	TRANSFORM_OP_SET       = "set"
+	TRANSFORM_OP_RENAME    = "rename"
+	TRANSFORM_OP_DELETE    = "delete"
+	TRANSFORM_OP_COPY      = "copy"
+	TRANSFORM_OP_TEMPLATE  = "template"
+	TRANSFORM_OP_CAST      = "cast"
+	TRANSFORM_OP_LOWERCASE = "lowercase"
+	TRANSFORM_OP_UPPERCASE = "uppercase"
+
+	CAST_TYPE_INT    = "int"
+	CAST_TYPE_FLOAT  = "float"
+	CAST_TYPE_BOOL   = "bool"
+	CAST_TYPE_STRING = "string"
+)
+
+var (
+	ErrInvalidPipeline = errors.New("invalid pipeline")
+	ErrInvalidStage    = errors.New("invalid stage")
+	ErrInvalidPriority = errors.New("invalid priority")
+	ErrInvalidOutput   = errors.New("invalid output")
+)
+
+// regexGrokName matches the names a grok pattern can refer to
+var regexGrokName = regexp.MustCompile(`^\w+$`)
+
+// PipelineConfig runs the events through the Stages in order. The results of a pipeline with
+// Outputs are only written to them, the others are collected and handed to the alerting, the
+// incidents and the notifiers
+type PipelineConfig struct {
+	Name    string         `yaml:"name"`
+	Stages  []StageConfig  `yaml:"stages"`
+	Outputs []OutputConfig `yaml:"outputs"`
+}
+
+// OutputConfig is a sink of the results of a pipeline, a file output appends them to Path as JSON
+// lines
+type OutputConfig struct {
+	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+}
+
+// StageConfig holds the settings of a single stage, only the block matching Type is used
+type StageConfig struct {
+	Type      string           `yaml:"type"`
+	Filter    *FilterConfig    `yaml:"filter"`
+	Dedup     *DedupConfig     `yaml:"dedup"`
+	Sample    *SampleConfig    `yaml:"sample"`
+	Limit     *LimitConfig     `yaml:"rate_limit" mapstructure:"rate_limit"`
+	Redact    *RedactConfig    `yaml:"redact"`
+	Grok      *GrokConfig      `yaml:"grok"`
+	Transform *TransformConfig `yaml:"transform"`
+	Templates *TemplateConfig  `yaml:"templates"`
+	Anomaly   *AnomalyConfig   `yaml:"anomaly"`
+	Correlate *CorrelateConfig `yaml:"correlate"`
+	Analyze   *AnalyzeConfig   `yaml:"analyze"`
+}
+
+// FilterConfig keeps the events with at least MinSeverity that match any Include rule
+// (all of them when there are none) and no Exclude rule
+type FilterConfig struct {
+	MinSeverity LogLevel     `yaml:"min_severity" mapstructure:"min_severity"`
+	Include     []FilterRule `yaml:"include"`
+	Exclude     []FilterRule `yaml:"exclude"`
+}
+
+// FilterRule matches an event when all the configured conditions match. Condition is an expression
+// such as `severity >= ERROR && metadata.namespace == "prod"`. Metadata is a list since the config
+// loader lowercases the keys of maps
+type FilterRule struct {
+	Name      string          `yaml:"name"`
+	Message   string          `yaml:"message"`
+	Source    string          `yaml:"source"`
+	Metadata  []MetadataMatch `yaml:"metadata"`
+	Condition string          `yaml:"condition"`
+}
+
+// MetadataMatch matches the events whose metadata Key has a value matching the Pattern regexp
+type MetadataMatch struct {
+	Key     string `yaml:"key"`
+	Pattern string `yaml:"pattern"`
+}
+
+// DedupConfig suppresses the repetitions of an event within the window. Events are compared by
+// their normalised message and the values of Fields
+type DedupConfig struct {
+	Window  time.Duration `yaml:"window"`
+	Fields  []string      `yaml:"fields"`
+	MaxKeys int           `yaml:"max_keys" mapstructure:"max_keys"`
+}
+
+// SampleConfig keeps a fraction of the events below ERROR, the errors are never sampled.
+//   - fixed keeps each event with the probability Rate
+//   - reservoir keeps Size random events per key and Window, released when the window closes
+//   - first_n keeps the first Size events per key and Window
+//
+// The key is the normalised message along with the values of Fields
+type SampleConfig struct {
+	Mode    string        `yaml:"mode"`
+	Rate    float64       `yaml:"rate"`
+	Size    int           `yaml:"size"`
+	Window  time.Duration `yaml:"window"`
+	Fields  []string      `yaml:"fields"`
+	MaxKeys int           `yaml:"max_keys" mapstructure:"max_keys"`
+}
+
+// LimitConfig allows Rate events per second with bursts of Burst events for each key. KeyBy is
+// "source", "input" or "metadata.<field>". The suppressed events of a key are reported with a
+// summary event once per SummaryInterval
+type LimitConfig struct {
+	KeyBy           string        `yaml:"key_by" mapstructure:"key_by"`
+	Rate            float64       `yaml:"rate"`
+	Burst           int           `yaml:"burst"`
+	SummaryInterval time.Duration `yaml:"summary_interval" mapstructure:"summary_interval"`
+	MaxKeys         int           `yaml:"max_keys" mapstructure:"max_keys"`
+}
+
+// RedactConfig replaces the sensitive values found in the message and the string metadata.
+// Detectors selects the built-in detectors, all of them when empty, and Custom adds named patterns
+type RedactConfig struct {
+	Mode      string          `yaml:"mode"`
+	Detectors []string        `yaml:"detectors"`
+	Custom    []RedactPattern `yaml:"custom"`
+}
+
+type RedactPattern struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// GrokConfig parses the message with the first matching pattern, adding the captured fields to the
+// metadata. Patterns use the %{NAME:field:type} syntax, with type int, float or bool, and may refer
+// to the built-in library or to the user Definitions. Definitions are a list since the config
+// loader lowercases the keys of maps
+type GrokConfig struct {
+	Patterns    []string         `yaml:"patterns"`
+	Definitions []GrokDefinition `yaml:"definitions"`
+}
+
+type GrokDefinition struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// TransformConfig applies the rules in order to each event
+type TransformConfig struct {
+	Rules []TransformRule `yaml:"rules"`
+}
+
+// TransformRule is a single operation on Field, applied only when the event matches When.
+//   - set writes Value
+//   - rename and copy move or duplicate the value of From
+//   - delete removes a metadata field
+//   - template renders Template, a text/template over message, severity, source and metadata
+//   - cast converts the value to Type: int, float, bool or string
+//   - lowercase and uppercase change the case of the value
+//
+// Fields are id, timestamp, source, severity, message or metadata.<key>
+type TransformRule struct {
+	When     *FilterRule `yaml:"when"`
+	Op       string      `yaml:"op"`
+	Field    string      `yaml:"field"`
+	From     string      `yaml:"from"`
+	Value    interface{} `yaml:"value"`
+	Template string      `yaml:"template"`
+	Type     string      `yaml:"type"`
+}
+
+// TemplateConfig groups the messages into templates with the Drain algorithm. Messages are routed
+// through a tree of Depth levels by their length and first tokens, then joined to the most similar
+// template of the leaf when at least Similarity of the tokens match. Counts are kept per Window
+type TemplateConfig struct {
+	Depth       int           `yaml:"depth"`
+	Similarity  float64       `yaml:"similarity"`
+	MaxChildren int           `yaml:"max_children" mapstructure:"max_children"`
+	MaxClusters int           `yaml:"max_clusters" mapstructure:"max_clusters"`
+	Window      time.Duration `yaml:"window"`
+}
+
+// AnomalyConfig learns the number of events per key and Window with an exponentially weighted mean
+// and variance, smoothed by Alpha, and reports the windows whose z-score reaches Threshold once
+// MinSamples windows were seen. The window slides by Window / Buckets, a single bucket makes it
+// tumble. KeyBy lists the fields forming the key, such as source, metadata.pod or
+// metadata.template_id, and only the events with at least MinSeverity are counted. Silence also
+// reports the keys going quiet
+type AnomalyConfig struct {
+	KeyBy       []string      `yaml:"key_by" mapstructure:"key_by"`
+	MinSeverity LogLevel      `yaml:"min_severity" mapstructure:"min_severity"`
+	Window      time.Duration `yaml:"window"`
+	Buckets     int           `yaml:"buckets"`
+	Alpha       float64       `yaml:"alpha"`
+	Threshold   float64       `yaml:"threshold"`
+	MinSamples  int           `yaml:"min_samples" mapstructure:"min_samples"`
+	Silence     bool          `yaml:"silence"`
+	MaxKeys     int           `yaml:"max_keys" mapstructure:"max_keys"`
+}
+
+// CorrelateConfig extracts the trace, span and request IDs of the events and remembers the events
+// of each ID for Window. The events with at least MinSeverity get the last Context events sharing
+// their IDs. Fields adds metadata names holding request IDs to the common ones
+type CorrelateConfig struct {
+	Fields      []string      `yaml:"fields"`
+	Window      time.Duration `yaml:"window"`
+	MinSeverity LogLevel      `yaml:"min_severity" mapstructure:"min_severity"`
+	Context     int           `yaml:"context"`
+	MaxEvents   int           `yaml:"max_events" mapstructure:"max_events"`
+	MaxIDs      int           `yaml:"max_ids" mapstructure:"max_ids"`
+}
+
+// AnalyzeConfig asks the AI provider about the events with at least MinSeverity. The prompt is rendered
+// from Template, or the template in TemplateFile, with the Context events preceding the event, and
+// trimmed to MaxPromptTokens. At most MaxConcurrent analyses run at once, each one for Timeout
+type AnalyzeConfig struct {
+	MinSeverity     LogLevel      `yaml:"min_severity" mapstructure:"min_severity"`
+	Template        string        `yaml:"template"`
+	TemplateFile    string        `yaml:"template_file" mapstructure:"template_file"`
+	MaxPromptTokens int           `yaml:"max_prompt_tokens" mapstructure:"max_prompt_tokens"`
+	Context         int           `yaml:"context"`
+	MaxConcurrent   int           `yaml:"max_concurrent" mapstructure:"max_concurrent"`
+	Timeout         time.Duration `yaml:"timeout"`
+}
+
+// PriorityConfig sets up the severity queues between the inputs and the pipelines. Each level has its
+// own queue of QueueSize events and is drained proportionally to its weight. When Capacity events
+// are queued, twice QueueSize by default, the oldest events of the lower levels are shed to make
+// room for the higher ones. The events read as INFO by the inputs are queued with the level written
+// in their line
+type PriorityConfig struct {
+	Enabled   bool             `yaml:"enabled"`
+	QueueSize int              `yaml:"queue_size" mapstructure:"queue_size"`
+	Capacity  int              `yaml:"capacity"`
+	Weights   map[LogLevel]int `yaml:"weights"`
+}
+
+// Validate checks the pipeline structure, stage specific settings are checked when the stage is built
+func (p PipelineConfig) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidPipeline)
+	}
+
+	for i, stage := range p.Stages {
+		if err := stage.Validate(); err != nil {
+			return fmt.Errorf("%w: %s stage %d: %w", ErrInvalidPipeline, p.Name, i, err)
+		}
+	}
+
+	for i, output := range p.Outputs {
+		if err := output.Validate(); err != nil {
+			return fmt.Errorf("%w: %s output %d: %w", ErrInvalidPipeline, p.Name, i, err)
+		}
+	}
+
+	return nil
+}
+
+func (o OutputConfig) Validate() error {
+	if o.Type != OUTPUT_FILE {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidOutput, o.Type)
+	}
+
+	if o.Path == "" {
+		return fmt.Errorf("%w: missing path", ErrInvalidOutput)
+	}
+
+	return nil
+}
+
+func (p PriorityConfig) Validate() error {
+	if p.QueueSize < 0 || p.Capacity < 0 {
+		return fmt.Errorf("%w: negative queue size", ErrInvalidPriority)
+	}
+
+	for level, weight := range p.Weights {
+		if !upperLevel(level).IsValid() {
+			return fmt.Errorf("%w: unknown severity %s", ErrInvalidPriority, level)
+		}
+		if weight <= 0 {
+			return fmt.Errorf("%w: weight of %s must be positive", ErrInvalidPriority, level)
+		}
+	}
+
+	return nil
+}
+
+// LevelWeights returns the weights by level. The keys are upper cased since viper lowercases the
+// keys of the maps, "fatal" is read as FATAL
+func (p PriorityConfig) LevelWeights() map[LogLevel]int {
+	weights := make(map[LogLevel]int, len(p.Weights))
+	for level, weight := range p.Weights {
+		weights[upperLevel(level)] = weight
+	}
+
+	return weights
+}
+
+func upperLevel(level LogLevel) LogLevel {
+	return LogLevel(strings.ToUpper(string(level)))
+}
+
+func (s StageConfig) Validate() error {
+	switch s.Type {
+	case STAGE_FILTER:
+		if s.Filter == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Filter.Validate()
+	case STAGE_DEDUP:
+		if s.Dedup == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Dedup.Validate()
+	case STAGE_SAMPLE:
+		if s.Sample == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Sample.Validate()
+	case STAGE_LIMIT:
+		if s.Limit == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Limit.Validate()
+	case STAGE_REDACT:
+		if s.Redact == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Redact.Validate()
+	case STAGE_GROK:
+		if s.Grok == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Grok.Validate()
+	case STAGE_TRANSFORM:
+		if s.Transform == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Transform.Validate()
+	case STAGE_TEMPLATES:
+		if s.Templates == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Templates.Validate()
+	case STAGE_ANOMALY:
+		if s.Anomaly == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Anomaly.Validate()
+	case STAGE_CORRELATE:
+		if s.Correlate == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Correlate.Validate()
+	case STAGE_ANALYZE:
+		if s.Analyze == nil {
+			return missingSettings(s.Type)
+		}
+		return s.Analyze.Validate()
+	}
+
+	return fmt.Errorf("%w: unknown type %q", ErrInvalidStage, s.Type)
+}
+
+func missingSettings(stageType string) error {
+	return fmt.Errorf("%w: missing %s settings", ErrInvalidStage, stageType)
+}
+
+func (f FilterConfig) Validate() error {
+	if f.MinSeverity != "" && !f.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %s", ErrInvalidStage, f.MinSeverity)
+	}
+
+	return nil
+}
+
+func (d DedupConfig) Validate() error {
+	if d.Window <= 0 {
+		return fmt.Errorf("%w: dedup window must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
+
+func (s SampleConfig) Validate() error {
+	switch s.Mode {
+	case SAMPLE_MODE_FIXED:
+		if s.Rate <= 0 || s.Rate > 1 {
+			return fmt.Errorf("%w: sample rate must be in (0, 1]", ErrInvalidStage)
+		}
+	case SAMPLE_MODE_RESERVOIR, SAMPLE_MODE_FIRST_N:
+		if s.Size <= 0 {
+			return fmt.Errorf("%w: sample size must be positive", ErrInvalidStage)
+		}
+		if s.Window < 0 {
+			return fmt.Errorf("%w: sample window can't be negative", ErrInvalidStage)
+		}
+	default:
+		return fmt.Errorf("%w: unknown sample mode %q", ErrInvalidStage, s.Mode)
+	}
+
+	return nil
+}
+
+func (l LimitConfig) Validate() error {
+	switch {
+	case l.KeyBy == LIMIT_KEY_SOURCE, l.KeyBy == LIMIT_KEY_INPUT:
+	case strings.HasPrefix(l.KeyBy, LIMIT_KEY_METADATA) && len(l.KeyBy) > len(LIMIT_KEY_METADATA):
+	default:
+		return fmt.Errorf("%w: unknown rate limit key %q", ErrInvalidStage, l.KeyBy)
+	}
+
+	if l.Rate <= 0 || l.Burst <= 0 {
+		return fmt.Errorf("%w: rate limit rate and burst must be positive", ErrInvalidStage)
+	}
+
+	if l.SummaryInterval < 0 {
+		return fmt.Errorf("%w: rate limit summary interval must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
+
+func (r RedactConfig) Validate() error {
+	switch r.Mode {
+	case "", REDACT_MODE_MASK, REDACT_MODE_HASH, REDACT_MODE_REMOVE:
+	default:
+		return fmt.Errorf("%w: unknown redact mode %q", ErrInvalidStage, r.Mode)
+	}
+
+	for _, custom := range r.Custom {
+		if custom.Name == "" || custom.Pattern == "" {
+			return fmt.Errorf("%w: custom redact patterns need a name and a pattern", ErrInvalidStage)
+		}
+	}
+
+	return nil
+}
+
+func (g GrokConfig) Validate() error {
+	if len(g.Patterns) == 0 {
+		return fmt.Errorf("%w: grok needs at least one pattern", ErrInvalidStage)
+	}
+
+	for _, definition := range g.Definitions {
+		if !regexGrokName.MatchString(definition.Name) || definition.Pattern == "" {
+			return fmt.Errorf("%w: grok definitions need a name made of word characters and a pattern", ErrInvalidStage)
+		}
+	}
+
+	return nil
+}
+
+func (t TransformConfig) Validate() error {
+	if len(t.Rules) == 0 {
+		return fmt.Errorf("%w: transform needs at least one rule", ErrInvalidStage)
+	}
+
+	for i, rule := range t.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%w: transform rule %d: %w", ErrInvalidStage, i, err)
+		}
+	}
+
+	return nil
+}
+
+func (r TransformRule) Validate() error {
+	if !IsEventField(r.Field) {
+		return fmt.Errorf("unknown field %q", r.Field)
+	}
+
+	isMetadata := strings.HasPrefix(r.Field, FIELD_METADATA)
+
+	switch r.Op {
+	case TRANSFORM_OP_SET:
+		if r.Value == nil {
+			return fmt.Errorf("set needs a value")
+		}
+	case TRANSFORM_OP_RENAME, TRANSFORM_OP_COPY:
+		if !IsEventField(r.From) {
+			return fmt.Errorf("unknown source field %q", r.From)
+		}
+		if r.Op == TRANSFORM_OP_RENAME && !strings.HasPrefix(r.From, FIELD_METADATA) {
+			return fmt.Errorf("only metadata fields can be renamed")
+		}
+	case TRANSFORM_OP_DELETE:
+		if !isMetadata {
+			return fmt.Errorf("only metadata fields can be deleted")
+		}
+	case TRANSFORM_OP_TEMPLATE:
+		if r.Template == "" {
+			return fmt.Errorf("template needs a template")
+		}
+	case TRANSFORM_OP_CAST:
+		switch r.Type {
+		case CAST_TYPE_INT, CAST_TYPE_FLOAT, CAST_TYPE_BOOL, CAST_TYPE_STRING:
+		default:
+			return fmt.Errorf("unknown cast type %q", r.Type)
+		}
+		if !isMetadata {
+			return fmt.Errorf("only metadata fields can be cast")
+		}
+	case TRANSFORM_OP_LOWERCASE, TRANSFORM_OP_UPPERCASE:
+	default:
+		return fmt.Errorf("unknown op %q", r.Op)
+	}
+
+	return nil
+}
+
+func (t TemplateConfig) Validate() error {
+	if t.Depth != 0 && t.Depth < 3 {
+		return fmt.Errorf("%w: template depth must be at least 3", ErrInvalidStage)
+	}
+
+	if t.Similarity < 0 || t.Similarity > 1 {
+		return fmt.Errorf("%w: template similarity must be in [0, 1]", ErrInvalidStage)
+	}
+
+	if t.MaxChildren < 0 || t.MaxClusters < 0 || t.Window < 0 {
+		return fmt.Errorf("%w: template limits must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
+
+func (a AnomalyConfig) Validate() error {
+	for _, field := range a.KeyBy {
+		if !IsEventField(field) {
+			return fmt.Errorf("%w: unknown anomaly key %q", ErrInvalidStage, field)
+		}
+	}
+
+	if a.MinSeverity != "" && !a.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %s", ErrInvalidStage, a.MinSeverity)
+	}
+
+	if a.Alpha < 0 || a.Alpha > 1 {
+		return fmt.Errorf("%w: anomaly alpha must be in [0, 1]", ErrInvalidStage)
+	}
+
+	if a.Window < 0 || a.Buckets < 0 || a.Threshold < 0 || a.MinSamples < 0 || a.MaxKeys < 0 {
+		return fmt.Errorf("%w: anomaly settings must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
+
+func (c CorrelateConfig) Validate() error {
+	if c.MinSeverity != "" && !c.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %s", ErrInvalidStage, c.MinSeverity)
+	}
+
+	if c.Window < 0 || c.Context < 0 || c.MaxEvents < 0 || c.MaxIDs < 0 {
+		return fmt.Errorf("%w: correlate settings must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
+
+func (c AnalyzeConfig) Validate() error {
+	if c.MinSeverity != "" && !c.MinSeverity.IsValid() {
+		return fmt.Errorf("%w: unknown severity %s", ErrInvalidStage, c.MinSeverity)
+	}
+
+	if c.Template != "" && c.TemplateFile != "" {
+		return fmt.Errorf("%w: analyze template and template_file are exclusive", ErrInvalidStage)
+	}
+
+	if c.MaxPromptTokens < 0 || c.Context < 0 || c.MaxConcurrent < 0 || c.Timeout < 0 {
+		return fmt.Errorf("%w: analyze settings must be positive", ErrInvalidStage)
+	}
+
+	return nil
+}
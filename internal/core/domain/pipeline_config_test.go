@@ -0,0 +1,434 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        domain.PipelineConfig
+		expectedError error
+	}{
+		{
+			name: "valid pipeline",
+			config: domain.PipelineConfig{
+				Name: "default",
+				Stages: []domain.StageConfig{
+					{Type: domain.STAGE_FILTER, Filter: &domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_ERROR}},
+				},
+			},
+		},
+		{
+			name:          "missing name",
+			config:        domain.PipelineConfig{},
+			expectedError: domain.ErrInvalidPipeline,
+		},
+		{
+			name: "file output",
+			config: domain.PipelineConfig{
+				Name:    "platform-debug",
+				Outputs: []domain.OutputConfig{{Type: domain.OUTPUT_FILE, Path: "/var/log/platform-debug.log"}},
+			},
+		},
+		{
+			name: "unknown output type",
+			config: domain.PipelineConfig{
+				Name:    "platform-debug",
+				Outputs: []domain.OutputConfig{{Type: "kafka", Path: "debug"}},
+			},
+			expectedError: domain.ErrInvalidOutput,
+		},
+		{
+			name: "file output without path",
+			config: domain.PipelineConfig{
+				Name:    "platform-debug",
+				Outputs: []domain.OutputConfig{{Type: domain.OUTPUT_FILE}},
+			},
+			expectedError: domain.ErrInvalidOutput,
+		},
+		{
+			name: "unknown stage type",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: "unknown"}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "missing stage settings",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_FILTER}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "non positive dedup window",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_DEDUP, Dedup: &domain.DedupConfig{}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "sample rate out of range",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_SAMPLE, Sample: &domain.SampleConfig{Mode: domain.SAMPLE_MODE_FIXED, Rate: 1.5}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "sample size missing",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_SAMPLE, Sample: &domain.SampleConfig{Mode: domain.SAMPLE_MODE_RESERVOIR}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "unknown sample mode",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_SAMPLE, Sample: &domain.SampleConfig{Mode: "random"}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "valid sample",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_SAMPLE, Sample: &domain.SampleConfig{Mode: domain.SAMPLE_MODE_FIRST_N, Size: 10}}},
+			},
+		},
+		{
+			name: "rate limit by metadata",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_LIMIT, Limit: &domain.LimitConfig{KeyBy: "metadata.pod", Rate: 10, Burst: 20}}},
+			},
+		},
+		{
+			name: "rate limit by unknown key",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_LIMIT, Limit: &domain.LimitConfig{KeyBy: "metadata.", Rate: 10, Burst: 20}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "rate limit without rate",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_LIMIT, Limit: &domain.LimitConfig{KeyBy: domain.LIMIT_KEY_SOURCE, Burst: 20}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "unknown redact mode",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_REDACT, Redact: &domain.RedactConfig{Mode: "encrypt"}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "custom redact pattern without name",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_REDACT, Redact: &domain.RedactConfig{Custom: []domain.RedactPattern{{Pattern: "x"}}}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "grok without patterns",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_GROK, Grok: &domain.GrokConfig{}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "transform without rules",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_TRANSFORM, Transform: &domain.TransformConfig{}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "transform of an unknown field",
+			config: domain.PipelineConfig{
+				Name: "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_TRANSFORM, Transform: &domain.TransformConfig{
+					Rules: []domain.TransformRule{{Op: domain.TRANSFORM_OP_SET, Field: "team", Value: "payments"}},
+				}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "template depth too small",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_TEMPLATES, Templates: &domain.TemplateConfig{Depth: 2}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "template similarity out of range",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_TEMPLATES, Templates: &domain.TemplateConfig{Similarity: 1.5}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "anomaly key of an unknown field",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_ANOMALY, Anomaly: &domain.AnomalyConfig{KeyBy: []string{"pod"}}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "anomaly alpha out of range",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_ANOMALY, Anomaly: &domain.AnomalyConfig{Alpha: 2}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "exclusive analyze templates",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_ANALYZE, Analyze: &domain.AnalyzeConfig{Template: "{{.Event}}", TemplateFile: "prompt.tmpl"}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "negative analyze timeout",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_ANALYZE, Analyze: &domain.AnalyzeConfig{Timeout: -time.Second}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "missing correlate settings",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_CORRELATE}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "negative correlate window",
+			config: domain.PipelineConfig{
+				Name:   "default",
+				Stages: []domain.StageConfig{{Type: domain.STAGE_CORRELATE, Correlate: &domain.CorrelateConfig{Window: -time.Second}}},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+		{
+			name: "unknown severity",
+			config: domain.PipelineConfig{
+				Name: "default",
+				Stages: []domain.StageConfig{
+					{Type: domain.STAGE_FILTER, Filter: &domain.FilterConfig{MinSeverity: "CRITICAL"}},
+				},
+			},
+			expectedError: domain.ErrInvalidStage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRuntimeConfig_ValidatePipelines(t *testing.T) {
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Pipelines: []domain.PipelineConfig{
+			{Name: "default"},
+			{Name: "default"},
+		},
+	}
+
+	assert.ErrorIs(t, config.Validate(), domain.ErrInvalidPipeline)
+
+	config.Pipelines[1].Name = "errors"
+	assert.NoError(t, config.Validate())
+
+	config.Pipelines[1].Stages = []domain.StageConfig{{Type: "unknown"}}
+	assert.ErrorIs(t, config.Validate(), domain.ErrInvalidStage)
+}
+
+func TestPriorityConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        domain.PriorityConfig
+		expectedError error
+	}{
+		{
+			name:   "defaults",
+			config: domain.PriorityConfig{Enabled: true},
+		},
+		{
+			name: "custom weights",
+			config: domain.PriorityConfig{
+				QueueSize: 100,
+				Weights:   map[domain.LogLevel]int{domain.LOG_LEVEL_FATAL: 10},
+			},
+		},
+		{
+			name:          "negative queue size",
+			config:        domain.PriorityConfig{QueueSize: -1},
+			expectedError: domain.ErrInvalidPriority,
+		},
+		{
+			name:          "unknown level",
+			config:        domain.PriorityConfig{Weights: map[domain.LogLevel]int{"TRACE": 1}},
+			expectedError: domain.ErrInvalidPriority,
+		},
+		{
+			name:          "zero weight",
+			config:        domain.PriorityConfig{Weights: map[domain.LogLevel]int{domain.LOG_LEVEL_INFO: 0}},
+			expectedError: domain.ErrInvalidPriority,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPriorityConfig_LevelWeights(t *testing.T) {
+	config := loadYAML(t, `
+priority:
+  weights:
+    FATAL: 10
+    Error: 5
+`)
+
+	require.NoError(t, config.Priority.Validate())
+	assert.Equal(t, map[domain.LogLevel]int{
+		domain.LOG_LEVEL_FATAL: 10,
+		domain.LOG_LEVEL_ERROR: 5,
+	}, config.Priority.LevelWeights())
+}
+
+func TestFilterRule_Metadata(t *testing.T) {
+	config := loadYAML(t, `
+pipelines:
+  - name: api
+    stages:
+      - type: filter
+        filter:
+          include:
+            - metadata:
+                - key: requestId
+                  pattern: ^req-
+`)
+
+	filter := config.Pipelines[0].Stages[0].Filter
+	require.NotNil(t, filter)
+	assert.Equal(t, []domain.MetadataMatch{{Key: "requestId", Pattern: "^req-"}}, filter.Include[0].Metadata, "the key keeps its case")
+}
+
+func TestPipelineConfig_Outputs(t *testing.T) {
+	config := loadYAML(t, `
+pipelines:
+  - name: platform-debug
+    stages:
+      - type: filter
+        filter:
+          min_severity: DEBUG
+    outputs:
+      - type: file
+        path: /var/log/platform-debug.log
+`)
+
+	require.NoError(t, config.Pipelines[0].Validate())
+	assert.Equal(t, []domain.OutputConfig{{Type: domain.OUTPUT_FILE, Path: "/var/log/platform-debug.log"}}, config.Pipelines[0].Outputs)
+}
+
+func TestGrokConfig_Definitions(t *testing.T) {
+	config := loadYAML(t, `
+pipelines:
+  - name: orders
+    stages:
+      - type: grok
+        grok:
+          patterns: ["%{ORDER:order}"]
+          definitions:
+            - name: ORDER
+              pattern: ORD-%{INT}
+`)
+
+	grok := config.Pipelines[0].Stages[0].Grok
+	require.NotNil(t, grok)
+	require.NoError(t, grok.Validate())
+	assert.Equal(t, []domain.GrokDefinition{{Name: "ORDER", Pattern: "ORD-%{INT}"}}, grok.Definitions, "the name keeps its case")
+
+	grok.Definitions = []domain.GrokDefinition{{Name: "MY-PAT", Pattern: "x"}}
+	assert.ErrorIs(t, grok.Validate(), domain.ErrInvalidStage)
+
+	grok.Definitions = []domain.GrokDefinition{{Name: "MYPAT"}}
+	assert.ErrorIs(t, grok.Validate(), domain.ErrInvalidStage)
+}
+
+func TestTransformRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    domain.TransformRule
+		wantErr bool
+	}{
+		{name: "set", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_SET, Field: "metadata.team", Value: "payments"}},
+		{name: "set without value", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_SET, Field: "metadata.team"}, wantErr: true},
+		{name: "rename to severity", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_RENAME, Field: "severity", From: "metadata.lvl"}},
+		{name: "rename of an event field", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_RENAME, Field: "metadata.msg", From: "message"}, wantErr: true},
+		{name: "copy of an event field", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_COPY, Field: "metadata.msg", From: "message"}},
+		{name: "delete of an event field", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_DELETE, Field: "message"}, wantErr: true},
+		{name: "template without template", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_TEMPLATE, Field: "message"}, wantErr: true},
+		{name: "cast", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_CAST, Field: "metadata.status", Type: domain.CAST_TYPE_INT}},
+		{name: "cast to unknown type", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_CAST, Field: "metadata.status", Type: "date"}, wantErr: true},
+		{name: "lowercase", rule: domain.TransformRule{Op: domain.TRANSFORM_OP_LOWERCASE, Field: "source"}},
+		{name: "unknown op", rule: domain.TransformRule{Op: "append", Field: "message"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
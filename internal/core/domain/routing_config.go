@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidRoute = errors.New("invalid route")
+
+// DEFAULT_ROUTE names the route of the unmatched events, a route can't be named after it
+const DEFAULT_ROUTE = "default"
+
+// RoutingConfig assigns the events to the pipelines. Routes are evaluated in order and the first
+// matching route wins, unless it sets Continue. The unmatched events go to the Default pipelines.
+// Without routes nor default every pipeline receives every event
+type RoutingConfig struct {
+	Routes  []RouteConfig `yaml:"routes"`
+	Default []string      `yaml:"default"`
+}
+
+// RouteConfig sends the events matching Condition, an expression over the event, to Pipelines.
+// A route without condition matches every event
+type RouteConfig struct {
+	Name      string   `yaml:"name"`
+	Condition string   `yaml:"condition"`
+	Pipelines []string `yaml:"pipelines"`
+	Continue  bool     `yaml:"continue"`
+}
+
+// Validate checks the routes refer to known pipelines, conditions are checked when the router is built
+func (r RoutingConfig) Validate(pipelines []PipelineConfig) error {
+	known := make(map[string]bool, len(pipelines))
+	for _, pipeline := range pipelines {
+		known[pipeline.Name] = true
+	}
+
+	names := make(map[string]bool, len(r.Routes))
+	for i, route := range r.Routes {
+		if route.Name == DEFAULT_ROUTE {
+			return fmt.Errorf("%w: %s is the name of the default route", ErrInvalidRoute, route.Name)
+		}
+
+		if route.Name != "" {
+			if names[route.Name] {
+				return fmt.Errorf("%w: duplicated name %s", ErrInvalidRoute, route.Name)
+			}
+			names[route.Name] = true
+		}
+
+		if len(route.Pipelines) == 0 {
+			return fmt.Errorf("%w: route %d has no pipelines", ErrInvalidRoute, i)
+		}
+
+		if err := checkPipelines(route.Pipelines, known); err != nil {
+			return fmt.Errorf("%w: route %d: %w", ErrInvalidRoute, i, err)
+		}
+	}
+
+	if err := checkPipelines(r.Default, known); err != nil {
+		return fmt.Errorf("%w: default route: %w", ErrInvalidRoute, err)
+	}
+
+	return nil
+}
+
+func checkPipelines(names []string, known map[string]bool) error {
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("unknown pipeline %s", name)
+		}
+	}
+
+	return nil
+}
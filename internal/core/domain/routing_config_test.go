@@ -0,0 +1,91 @@
+package domain_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingConfig_Validate(t *testing.T) {
+	pipelines := []domain.PipelineConfig{{Name: "payments"}, {Name: "platform"}}
+
+	tests := []struct {
+		name          string
+		config        domain.RoutingConfig
+		expectedError error
+	}{
+		{
+			name:   "no routes",
+			config: domain.RoutingConfig{},
+		},
+		{
+			name: "valid routes",
+			config: domain.RoutingConfig{
+				Routes: []domain.RouteConfig{
+					{Name: "payments", Condition: `metadata.team == "payments"`, Pipelines: []string{"payments"}},
+					{Name: "platform", Pipelines: []string{"platform", "payments"}},
+				},
+				Default: []string{"platform"},
+			},
+		},
+		{
+			name: "route without pipelines",
+			config: domain.RoutingConfig{
+				Routes: []domain.RouteConfig{{Name: "payments"}},
+			},
+			expectedError: domain.ErrInvalidRoute,
+		},
+		{
+			name: "unknown pipeline",
+			config: domain.RoutingConfig{
+				Routes: []domain.RouteConfig{{Pipelines: []string{"search"}}},
+			},
+			expectedError: domain.ErrInvalidRoute,
+		},
+		{
+			name:          "unknown default pipeline",
+			config:        domain.RoutingConfig{Default: []string{"search"}},
+			expectedError: domain.ErrInvalidRoute,
+		},
+		{
+			name: "duplicated route name",
+			config: domain.RoutingConfig{
+				Routes: []domain.RouteConfig{
+					{Name: "payments", Pipelines: []string{"payments"}},
+					{Name: "payments", Pipelines: []string{"platform"}},
+				},
+			},
+			expectedError: domain.ErrInvalidRoute,
+		},
+		{
+			name: "route named after the default route",
+			config: domain.RoutingConfig{
+				Routes: []domain.RouteConfig{{Name: domain.DEFAULT_ROUTE, Pipelines: []string{"payments"}}},
+			},
+			expectedError: domain.ErrInvalidRoute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate(pipelines)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRuntimeConfig_ValidateRouting(t *testing.T) {
+	config := &domain.RuntimeConfig{
+		ShutdownTimeout: 5,
+		Pipelines:       []domain.PipelineConfig{{Name: "default"}},
+		Routing:         domain.RoutingConfig{Default: []string{"missing"}},
+	}
+
+	assert.ErrorIs(t, config.Validate(), domain.ErrInvalidRoute)
+}
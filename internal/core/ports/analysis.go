@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+// AIAnalyzer asks a model for the probable cause of an event and how to fix it. The errors wrap
+// domain.ErrProviderUnavailable or domain.ErrRateLimited when another attempt may succeed
+type AIAnalyzer interface {
+	Name() string
+	Analyze(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error)
+}
+
+// AnalysisCache keeps the analyses by key, the fingerprint of the events they were made for is
+// kept to invalidate them
+type AnalysisCache interface {
+	Get(key string) (domain.Analysis, bool)
+	Set(key, fingerprint string, analysis domain.Analysis)
+}
+
+// AnalysisStore persists the cached analyses across restarts. Changed reports whether another
+// process, like invalidate-analysis, saved the analyses since they were last loaded or saved
+type AnalysisStore interface {
+	Load() ([]domain.CachedAnalysis, error)
+	Save(analyses []domain.CachedAnalysis) error
+	Changed() (bool, error)
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "log-guardian/internal/core/domain"
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+type Enricher interface {
+	Enrich(event *domain.LogEvent)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"log-guardian/internal/core/domain"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+// IncidentTracker groups the processed events and the alerts into incidents. Tick resolves the
+// quiet incidents and returns the incidents opened, reopened or resolved since the previous tick
+type IncidentTracker interface {
+	ObserveEvent(event domain.LogEvent)
+	ObserveAlert(alert domain.Alert)
+	Tick(now time.Time) []domain.Incident
+	Close() error
+}
+
+// IncidentStore persists the incidents across restarts. Changed reports whether another process,
+// like ack-incident, saved the incidents since they were last loaded or saved
+type IncidentStore interface {
+	Load() ([]domain.Incident, error)
+	Save(incidents []domain.Incident) error
+	Changed() (bool, error)
+}
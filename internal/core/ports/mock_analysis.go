@@ -0,0 +1,190 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: analysis.go
+//
+// Generated by this command:
+//
+//	mockgen -source=analysis.go -destination=mock_analysis.go -package=ports
+//
+
+// Package ports is a generated GoMock package.
+package ports
+
+import (
+	context "context"
+	domain "log-guardian/internal/core/domain"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAIAnalyzer is a mock of AIAnalyzer interface.
+type MockAIAnalyzer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAIAnalyzerMockRecorder
+	isgomock struct{}
+}
+
+// MockAIAnalyzerMockRecorder is the mock recorder for MockAIAnalyzer.
+type MockAIAnalyzerMockRecorder struct {
+	mock *MockAIAnalyzer
+}
+
+// NewMockAIAnalyzer creates a new mock instance.
+func NewMockAIAnalyzer(ctrl *gomock.Controller) *MockAIAnalyzer {
+	mock := &MockAIAnalyzer{ctrl: ctrl}
+	mock.recorder = &MockAIAnalyzerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAIAnalyzer) EXPECT() *MockAIAnalyzerMockRecorder {
+	return m.recorder
+}
+
+// Analyze mocks base method.
+func (m *MockAIAnalyzer) Analyze(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Analyze", ctx, request)
+	ret0, _ := ret[0].(domain.Analysis)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Analyze indicates an expected call of Analyze.
+func (mr *MockAIAnalyzerMockRecorder) Analyze(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Analyze", reflect.TypeOf((*MockAIAnalyzer)(nil).Analyze), ctx, request)
+}
+
+// Name mocks base method.
+func (m *MockAIAnalyzer) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockAIAnalyzerMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockAIAnalyzer)(nil).Name))
+}
+
+// MockAnalysisCache is a mock of AnalysisCache interface.
+type MockAnalysisCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalysisCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockAnalysisCacheMockRecorder is the mock recorder for MockAnalysisCache.
+type MockAnalysisCacheMockRecorder struct {
+	mock *MockAnalysisCache
+}
+
+// NewMockAnalysisCache creates a new mock instance.
+func NewMockAnalysisCache(ctrl *gomock.Controller) *MockAnalysisCache {
+	mock := &MockAnalysisCache{ctrl: ctrl}
+	mock.recorder = &MockAnalysisCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalysisCache) EXPECT() *MockAnalysisCacheMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockAnalysisCache) Get(key string) (domain.Analysis, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].(domain.Analysis)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAnalysisCacheMockRecorder) Get(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAnalysisCache)(nil).Get), key)
+}
+
+// Set mocks base method.
+func (m *MockAnalysisCache) Set(key, fingerprint string, analysis domain.Analysis) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Set", key, fingerprint, analysis)
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockAnalysisCacheMockRecorder) Set(key, fingerprint, analysis any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockAnalysisCache)(nil).Set), key, fingerprint, analysis)
+}
+
+// MockAnalysisStore is a mock of AnalysisStore interface.
+type MockAnalysisStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalysisStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockAnalysisStoreMockRecorder is the mock recorder for MockAnalysisStore.
+type MockAnalysisStoreMockRecorder struct {
+	mock *MockAnalysisStore
+}
+
+// NewMockAnalysisStore creates a new mock instance.
+func NewMockAnalysisStore(ctrl *gomock.Controller) *MockAnalysisStore {
+	mock := &MockAnalysisStore{ctrl: ctrl}
+	mock.recorder = &MockAnalysisStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalysisStore) EXPECT() *MockAnalysisStoreMockRecorder {
+	return m.recorder
+}
+
+// Changed mocks base method.
+func (m *MockAnalysisStore) Changed() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changed")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changed indicates an expected call of Changed.
+func (mr *MockAnalysisStoreMockRecorder) Changed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changed", reflect.TypeOf((*MockAnalysisStore)(nil).Changed))
+}
+
+// Load mocks base method.
+func (m *MockAnalysisStore) Load() ([]domain.CachedAnalysis, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].([]domain.CachedAnalysis)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockAnalysisStoreMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockAnalysisStore)(nil).Load))
+}
+
+// Save mocks base method.
+func (m *MockAnalysisStore) Save(analyses []domain.CachedAnalysis) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", analyses)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAnalysisStoreMockRecorder) Save(analyses any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAnalysisStore)(nil).Save), analyses)
+}
@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: enrichment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=enrichment.go -destination=mock_enrichment.go -package=ports
+//
+
+// Package ports is a generated GoMock package.
+package ports
+
+import (
+	domain "log-guardian/internal/core/domain"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEnricher is a mock of Enricher interface.
+type MockEnricher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnricherMockRecorder
+	isgomock struct{}
+}
+
+// MockEnricherMockRecorder is the mock recorder for MockEnricher.
+type MockEnricherMockRecorder struct {
+	mock *MockEnricher
+}
+
+// NewMockEnricher creates a new mock instance.
+func NewMockEnricher(ctrl *gomock.Controller) *MockEnricher {
+	mock := &MockEnricher{ctrl: ctrl}
+	mock.recorder = &MockEnricherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnricher) EXPECT() *MockEnricherMockRecorder {
+	return m.recorder
+}
+
+// Enrich mocks base method.
+func (m *MockEnricher) Enrich(event *domain.LogEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Enrich", event)
+}
+
+// Enrich indicates an expected call of Enrich.
+func (mr *MockEnricherMockRecorder) Enrich(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enrich", reflect.TypeOf((*MockEnricher)(nil).Enrich), event)
+}
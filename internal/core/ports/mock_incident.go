@@ -0,0 +1,162 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: incident.go
+//
+// Generated by this command:
+//
+//	mockgen -source=incident.go -destination=mock_incident.go -package=ports
+//
+
+// Package ports is a generated GoMock package.
+package ports
+
+import (
+	domain "log-guardian/internal/core/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIncidentTracker is a mock of IncidentTracker interface.
+type MockIncidentTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncidentTrackerMockRecorder
+	isgomock struct{}
+}
+
+// MockIncidentTrackerMockRecorder is the mock recorder for MockIncidentTracker.
+type MockIncidentTrackerMockRecorder struct {
+	mock *MockIncidentTracker
+}
+
+// NewMockIncidentTracker creates a new mock instance.
+func NewMockIncidentTracker(ctrl *gomock.Controller) *MockIncidentTracker {
+	mock := &MockIncidentTracker{ctrl: ctrl}
+	mock.recorder = &MockIncidentTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncidentTracker) EXPECT() *MockIncidentTrackerMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockIncidentTracker) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockIncidentTrackerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockIncidentTracker)(nil).Close))
+}
+
+// ObserveAlert mocks base method.
+func (m *MockIncidentTracker) ObserveAlert(alert domain.Alert) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveAlert", alert)
+}
+
+// ObserveAlert indicates an expected call of ObserveAlert.
+func (mr *MockIncidentTrackerMockRecorder) ObserveAlert(alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveAlert", reflect.TypeOf((*MockIncidentTracker)(nil).ObserveAlert), alert)
+}
+
+// ObserveEvent mocks base method.
+func (m *MockIncidentTracker) ObserveEvent(event domain.LogEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveEvent", event)
+}
+
+// ObserveEvent indicates an expected call of ObserveEvent.
+func (mr *MockIncidentTrackerMockRecorder) ObserveEvent(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveEvent", reflect.TypeOf((*MockIncidentTracker)(nil).ObserveEvent), event)
+}
+
+// Tick mocks base method.
+func (m *MockIncidentTracker) Tick(now time.Time) []domain.Incident {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tick", now)
+	ret0, _ := ret[0].([]domain.Incident)
+	return ret0
+}
+
+// Tick indicates an expected call of Tick.
+func (mr *MockIncidentTrackerMockRecorder) Tick(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tick", reflect.TypeOf((*MockIncidentTracker)(nil).Tick), now)
+}
+
+// MockIncidentStore is a mock of IncidentStore interface.
+type MockIncidentStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncidentStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockIncidentStoreMockRecorder is the mock recorder for MockIncidentStore.
+type MockIncidentStoreMockRecorder struct {
+	mock *MockIncidentStore
+}
+
+// NewMockIncidentStore creates a new mock instance.
+func NewMockIncidentStore(ctrl *gomock.Controller) *MockIncidentStore {
+	mock := &MockIncidentStore{ctrl: ctrl}
+	mock.recorder = &MockIncidentStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncidentStore) EXPECT() *MockIncidentStoreMockRecorder {
+	return m.recorder
+}
+
+// Changed mocks base method.
+func (m *MockIncidentStore) Changed() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changed")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changed indicates an expected call of Changed.
+func (mr *MockIncidentStoreMockRecorder) Changed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changed", reflect.TypeOf((*MockIncidentStore)(nil).Changed))
+}
+
+// Load mocks base method.
+func (m *MockIncidentStore) Load() ([]domain.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].([]domain.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockIncidentStoreMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockIncidentStore)(nil).Load))
+}
+
+// Save mocks base method.
+func (m *MockIncidentStore) Save(incidents []domain.Incident) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", incidents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIncidentStoreMockRecorder) Save(incidents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIncidentStore)(nil).Save), incidents)
+}
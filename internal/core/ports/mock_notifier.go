@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notifier.go
+//
+// Generated by this command:
+//
+//	mockgen -source=notifier.go -destination=mock_notifier.go -package=ports
+//
+
+// Package ports is a generated GoMock package.
+package ports
+
+import (
+	context "context"
+	domain "log-guardian/internal/core/domain"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+	isgomock struct{}
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method.
+func (m *MockNotifier) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockNotifierMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockNotifier)(nil).Name))
+}
+
+// NotifyAlert mocks base method.
+func (m *MockNotifier) NotifyAlert(ctx context.Context, alert domain.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAlert", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyAlert indicates an expected call of NotifyAlert.
+func (mr *MockNotifierMockRecorder) NotifyAlert(ctx, alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAlert", reflect.TypeOf((*MockNotifier)(nil).NotifyAlert), ctx, alert)
+}
+
+// NotifyIncident mocks base method.
+func (m *MockNotifier) NotifyIncident(ctx context.Context, incident domain.Incident) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyIncident", ctx, incident)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyIncident indicates an expected call of NotifyIncident.
+func (mr *MockNotifierMockRecorder) NotifyIncident(ctx, incident any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyIncident", reflect.TypeOf((*MockNotifier)(nil).NotifyIncident), ctx, incident)
+}
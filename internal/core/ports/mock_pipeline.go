@@ -0,0 +1,299 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pipeline.go
+//
+// Generated by this command:
+//
+//	mockgen -source=pipeline.go -destination=mock_pipeline.go -package=ports
+//
+
+// Package ports is a generated GoMock package.
+package ports
+
+import (
+	context "context"
+	domain "log-guardian/internal/core/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStage is a mock of Stage interface.
+type MockStage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStageMockRecorder
+	isgomock struct{}
+}
+
+// MockStageMockRecorder is the mock recorder for MockStage.
+type MockStageMockRecorder struct {
+	mock *MockStage
+}
+
+// NewMockStage creates a new mock instance.
+func NewMockStage(ctrl *gomock.Controller) *MockStage {
+	mock := &MockStage{ctrl: ctrl}
+	mock.recorder = &MockStageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStage) EXPECT() *MockStageMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method.
+func (m *MockStage) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockStageMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockStage)(nil).Name))
+}
+
+// Process mocks base method.
+func (m *MockStage) Process(event domain.LogEvent) []domain.LogEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", event)
+	ret0, _ := ret[0].([]domain.LogEvent)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockStageMockRecorder) Process(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockStage)(nil).Process), event)
+}
+
+// MockStatsProvider is a mock of StatsProvider interface.
+type MockStatsProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockStatsProviderMockRecorder is the mock recorder for MockStatsProvider.
+type MockStatsProviderMockRecorder struct {
+	mock *MockStatsProvider
+}
+
+// NewMockStatsProvider creates a new mock instance.
+func NewMockStatsProvider(ctrl *gomock.Controller) *MockStatsProvider {
+	mock := &MockStatsProvider{ctrl: ctrl}
+	mock.recorder = &MockStatsProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsProvider) EXPECT() *MockStatsProviderMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockStatsProvider) Stats() map[string]uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(map[string]uint64)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStatsProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStatsProvider)(nil).Stats))
+}
+
+// MockFlusher is a mock of Flusher interface.
+type MockFlusher struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlusherMockRecorder
+	isgomock struct{}
+}
+
+// MockFlusherMockRecorder is the mock recorder for MockFlusher.
+type MockFlusherMockRecorder struct {
+	mock *MockFlusher
+}
+
+// NewMockFlusher creates a new mock instance.
+func NewMockFlusher(ctrl *gomock.Controller) *MockFlusher {
+	mock := &MockFlusher{ctrl: ctrl}
+	mock.recorder = &MockFlusherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlusher) EXPECT() *MockFlusherMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockFlusher) Flush(now time.Time, final bool) []domain.LogEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", now, final)
+	ret0, _ := ret[0].([]domain.LogEvent)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockFlusherMockRecorder) Flush(now, final any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockFlusher)(nil).Flush), now, final)
+}
+
+// MockDispatcher is a mock of Dispatcher interface.
+type MockDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockDispatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockDispatcherMockRecorder is the mock recorder for MockDispatcher.
+type MockDispatcherMockRecorder struct {
+	mock *MockDispatcher
+}
+
+// NewMockDispatcher creates a new mock instance.
+func NewMockDispatcher(ctrl *gomock.Controller) *MockDispatcher {
+	mock := &MockDispatcher{ctrl: ctrl}
+	mock.recorder = &MockDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDispatcher) EXPECT() *MockDispatcherMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockDispatcher) Run(ctx context.Context, input <-chan domain.LogEvent, output chan<- domain.LogEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx, input, output)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockDispatcherMockRecorder) Run(ctx, input, output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockDispatcher)(nil).Run), ctx, input, output)
+}
+
+// MockAlerter is a mock of Alerter interface.
+type MockAlerter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlerterMockRecorder
+	isgomock struct{}
+}
+
+// MockAlerterMockRecorder is the mock recorder for MockAlerter.
+type MockAlerterMockRecorder struct {
+	mock *MockAlerter
+}
+
+// NewMockAlerter creates a new mock instance.
+func NewMockAlerter(ctrl *gomock.Controller) *MockAlerter {
+	mock := &MockAlerter{ctrl: ctrl}
+	mock.recorder = &MockAlerterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlerter) EXPECT() *MockAlerterMockRecorder {
+	return m.recorder
+}
+
+// Evaluate mocks base method.
+func (m *MockAlerter) Evaluate(now time.Time) []domain.Alert {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", now)
+	ret0, _ := ret[0].([]domain.Alert)
+	return ret0
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockAlerterMockRecorder) Evaluate(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockAlerter)(nil).Evaluate), now)
+}
+
+// Observe mocks base method.
+func (m *MockAlerter) Observe(event domain.LogEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Observe", event)
+}
+
+// Observe indicates an expected call of Observe.
+func (mr *MockAlerterMockRecorder) Observe(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Observe", reflect.TypeOf((*MockAlerter)(nil).Observe), event)
+}
+
+// MockOutput is a mock of Output interface.
+type MockOutput struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutputMockRecorder
+	isgomock struct{}
+}
+
+// MockOutputMockRecorder is the mock recorder for MockOutput.
+type MockOutputMockRecorder struct {
+	mock *MockOutput
+}
+
+// NewMockOutput creates a new mock instance.
+func NewMockOutput(ctrl *gomock.Controller) *MockOutput {
+	mock := &MockOutput{ctrl: ctrl}
+	mock.recorder = &MockOutputMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutput) EXPECT() *MockOutputMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockOutput) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockOutputMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockOutput)(nil).Close))
+}
+
+// Name mocks base method.
+func (m *MockOutput) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockOutputMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockOutput)(nil).Name))
+}
+
+// Write mocks base method.
+func (m *MockOutput) Write(events []domain.LogEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockOutputMockRecorder) Write(events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockOutput)(nil).Write), events)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+// Notifier delivers the alerts that fired or resolved and the incidents whose status changed to a
+// channel. The notifications below the minimum severity of the channel are skipped without error
+type Notifier interface {
+	Name() string
+	NotifyAlert(ctx context.Context, alert domain.Alert) error
+	NotifyIncident(ctx context.Context, incident domain.Incident) error
+}
@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOFILE -package=$GOPACKAGE
+
+// Stage is a processing step, it returns the events passed to the next step, none when the event is dropped
+type Stage interface {
+	Name() string
+	Process(event domain.LogEvent) []domain.LogEvent
+}
+
+// StatsProvider is implemented by the stages that keep counters
+type StatsProvider interface {
+	Stats() map[string]uint64
+}
+
+// Flusher is implemented by the stages that hold events back, Flush releases the events that
+// are due at now, or all of them when final is set on shutdown
+type Flusher interface {
+	Flush(now time.Time, final bool) []domain.LogEvent
+}
+
+// Dispatcher reorders the ingested events before they are processed, Run returns after
+// starting its workers, which stop when the context is done
+type Dispatcher interface {
+	Run(ctx context.Context, input <-chan domain.LogEvent, output chan<- domain.LogEvent)
+}
+
+// Alerter evaluates the alert rules over the processed events. Evaluate returns the alerts that
+// fired or resolved since the previous evaluation
+type Alerter interface {
+	Observe(event domain.LogEvent)
+	Evaluate(now time.Time) []domain.Alert
+}
+
+// Output writes the results of a pipeline to a sink, such as a file
+type Output interface {
+	Name() string
+	Write(events []domain.LogEvent) error
+	Close() error
+}
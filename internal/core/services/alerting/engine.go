@@ -0,0 +1,108 @@
+package alerting
+
+import (
+	"log-guardian/internal/core/domain"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Engine evaluates the threshold alert rules over the processed events
+type Engine struct {
+	clock domain.Clock
+
+	mu    sync.Mutex
+	rules []*rule
+
+	observed atomic.Uint64
+	matched  atomic.Uint64
+	fired    atomic.Uint64
+	resolved atomic.Uint64
+}
+
+// NewEngine compiles the rules, returning an error for invalid conditions or summaries
+func NewEngine(config domain.AlertingConfig, clock domain.Clock) (*Engine, error) {
+	e := &Engine{clock: clock}
+
+	for _, ruleConfig := range config.Rules {
+		r, err := newRule(ruleConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		e.rules = append(e.rules, r)
+	}
+
+	return e, nil
+}
+
+// Observe counts the event in the rules it matches
+func (e *Engine) Observe(event domain.LogEvent) {
+	e.observed.Add(1)
+	now := e.clock.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if r.observe(event, now) {
+			e.matched.Add(1)
+		}
+	}
+}
+
+// Evaluate returns the alerts that fired or resolved, ordered by rule and fingerprint
+func (e *Engine) Evaluate(now time.Time) []domain.Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var alerts []domain.Alert
+	for _, r := range e.rules {
+		alerts = append(alerts, r.evaluate(now)...)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].Fingerprint < alerts[j].Fingerprint
+	})
+
+	for _, alert := range alerts {
+		if alert.State == domain.ALERT_STATE_FIRING {
+			e.fired.Add(1)
+		} else {
+			e.resolved.Add(1)
+		}
+	}
+
+	return alerts
+}
+
+// Firing returns the number of alerts currently firing
+func (e *Engine) Firing() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	firing := 0
+	for _, r := range e.rules {
+		for _, g := range r.groups {
+			if g.status == statusFiring {
+				firing++
+			}
+		}
+	}
+
+	return firing
+}
+
+func (e *Engine) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"observed": e.observed.Load(),
+		"matched":  e.matched.Load(),
+		"fired":    e.fired.Load(),
+		"resolved": e.resolved.Load(),
+		"firing":   uint64(e.Firing()),
+	}
+}
@@ -0,0 +1,206 @@
+package alerting_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/alerting"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newEngine(t *testing.T, clock domain.Clock, rules ...domain.AlertRuleConfig) *alerting.Engine {
+	engine, err := alerting.NewEngine(domain.AlertingConfig{Rules: rules}, clock)
+	require.NoError(t, err)
+
+	return engine
+}
+
+func TestEngine_AnyFatal(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	engine := newEngine(t, clock, domain.AlertRuleConfig{
+		Name:      "any-fatal",
+		Condition: `severity == FATAL`,
+		Severity:  domain.LOG_LEVEL_FATAL,
+	})
+
+	engine.Observe(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Message: "ignored"})
+	assert.Empty(t, engine.Evaluate(now))
+
+	fatal := domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL, Message: "out of memory"}
+	engine.Observe(fatal)
+
+	alerts := engine.Evaluate(now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.Alert{
+		Fingerprint: domain.AlertFingerprint("any-fatal", map[string]string{}),
+		Rule:        "any-fatal",
+		State:       domain.ALERT_STATE_FIRING,
+		Severity:    domain.LOG_LEVEL_FATAL,
+		Summary:     "any-fatal: 1 events in 1m0s",
+		Labels:      map[string]string{},
+		Count:       1,
+		StartsAt:    now,
+		Samples:     []domain.LogEvent{fatal},
+	}, alerts[0])
+
+	assert.Empty(t, engine.Evaluate(now), "a firing alert is only sent once")
+	assert.Equal(t, 1, engine.Firing())
+
+	now = now.Add(time.Minute)
+	alerts = engine.Evaluate(now)
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.ALERT_STATE_RESOLVED, alerts[0].State)
+	assert.Equal(t, now, alerts[0].EndsAt)
+	assert.Equal(t, 0, engine.Firing())
+}
+
+func TestEngine_WindowedCountByGroup(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	engine := newEngine(t, clock, domain.AlertRuleConfig{
+		Name:      "prod-errors",
+		Condition: `severity >= ERROR`,
+		Threshold: 20,
+		Window:    5 * time.Minute,
+		GroupBy:   []string{"metadata.namespace"},
+		Summary:   `{{.Count}} errors in {{index .Labels "metadata.namespace"}}`,
+	})
+
+	observe := func(namespace string, count int) {
+		for i := 0; i < count; i++ {
+			engine.Observe(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{"namespace": namespace}})
+		}
+	}
+
+	observe("prod", 15)
+	observe("staging", 30)
+	now = now.Add(2 * time.Minute)
+	observe("prod", 10)
+
+	alerts := engine.Evaluate(now)
+
+	require.Len(t, alerts, 2)
+	summaries := []string{alerts[0].Summary, alerts[1].Summary}
+	assert.ElementsMatch(t, []string{"25 errors in prod", "30 errors in staging"}, summaries)
+
+	// the first 15 prod errors and the staging ones leave the window
+	now = now.Add(4 * time.Minute)
+	alerts = engine.Evaluate(now)
+
+	require.Len(t, alerts, 2)
+	for _, alert := range alerts {
+		assert.Equal(t, domain.ALERT_STATE_RESOLVED, alert.State)
+	}
+}
+
+func TestEngine_ForDuration(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	engine := newEngine(t, clock, domain.AlertRuleConfig{
+		Name:      "oom",
+		Condition: `message matches "OOMKilled"`,
+		Window:    10 * time.Minute,
+		For:       2 * time.Minute,
+	})
+
+	engine.Observe(domain.LogEvent{Message: "container OOMKilled"})
+
+	assert.Empty(t, engine.Evaluate(now), "the alert is pending")
+
+	now = now.Add(time.Minute)
+	assert.Empty(t, engine.Evaluate(now))
+
+	now = now.Add(time.Minute)
+	alerts := engine.Evaluate(now)
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.ALERT_STATE_FIRING, alerts[0].State)
+	assert.Equal(t, now, alerts[0].StartsAt)
+}
+
+func TestEngine_ResolveConditions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	resolveThreshold := 2
+	engine := newEngine(t, clock, domain.AlertRuleConfig{
+		Name:             "errors",
+		Threshold:        5,
+		Window:           time.Minute,
+		ResolveThreshold: &resolveThreshold,
+		ResolveAfter:     30 * time.Second,
+	})
+
+	observe := func(count int) {
+		for i := 0; i < count; i++ {
+			engine.Observe(domain.LogEvent{})
+		}
+	}
+
+	observe(6)
+	require.Len(t, engine.Evaluate(now), 1)
+
+	// 4 events are below the threshold but above the resolve threshold
+	now = now.Add(61 * time.Second)
+	observe(4)
+	assert.Empty(t, engine.Evaluate(now))
+
+	now = now.Add(61 * time.Second)
+	observe(1)
+	assert.Empty(t, engine.Evaluate(now), "the count must stay low for resolve_after")
+
+	now = now.Add(30 * time.Second)
+	alerts := engine.Evaluate(now)
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.ALERT_STATE_RESOLVED, alerts[0].State)
+}
+
+func TestEngine_Stats(t *testing.T) {
+	now := time.Now()
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	engine := newEngine(t, clock,
+		domain.AlertRuleConfig{Name: "fatal", Condition: `severity == FATAL`},
+		domain.AlertRuleConfig{Name: "all"},
+	)
+
+	engine.Observe(domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL})
+	engine.Observe(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO})
+	engine.Evaluate(now)
+
+	assert.Equal(t, map[string]uint64{
+		"observed": 2,
+		"matched":  3,
+		"fired":    2,
+		"resolved": 0,
+		"firing":   2,
+	}, engine.Stats())
+}
+
+func TestNewEngine_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule domain.AlertRuleConfig
+	}{
+		{name: "invalid condition", rule: domain.AlertRuleConfig{Name: "a", Condition: "severity >="}},
+		{name: "invalid summary", rule: domain.AlertRuleConfig{Name: "a", Summary: "{{.Count"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := alerting.NewEngine(domain.AlertingConfig{Rules: []domain.AlertRuleConfig{tt.rule}}, domain.NewMockClock(gomock.NewController(t)))
+
+			assert.ErrorIs(t, err, domain.ErrInvalidAlertRule)
+			assert.Nil(t, engine)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package alerting
+
+import (
+	"bytes"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/expr"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultWindow   = time.Minute
+	defaultSeverity = domain.LOG_LEVEL_ERROR
+	maxSamples      = 5
+	// bucketsPerWindow sets the granularity of the sliding window counts
+	bucketsPerWindow = 60
+)
+
+const defaultSummary = `{{.Rule}}: {{.Count}} events in {{.Window}}{{range $key, $value := .Labels}} {{$key}}={{$value}}{{end}}`
+
+type alertStatus int
+
+const (
+	statusInactive alertStatus = iota
+	statusPending
+	statusFiring
+)
+
+// rule counts the matching events of each group in a sliding window
+type rule struct {
+	name             string
+	condition        *expr.Expression
+	threshold        int
+	resolveThreshold int
+	window           time.Duration
+	bucket           time.Duration
+	groupBy          []string
+	forDuration      time.Duration
+	resolveAfter     time.Duration
+	severity         domain.LogLevel
+	summary          *template.Template
+
+	groups map[string]*group
+}
+
+// group is the state of the alert of one set of group by values
+type group struct {
+	labels  map[string]string
+	buckets []bucket
+	samples []domain.LogEvent
+
+	status   alertStatus
+	since    time.Time
+	startsAt time.Time
+	// quietSince is when the count of a firing alert went back to the resolve threshold
+	quietSince time.Time
+}
+
+type bucket struct {
+	start time.Time
+	count int
+}
+
+type summaryData struct {
+	Rule      string
+	Count     int
+	Threshold int
+	Window    time.Duration
+	Labels    map[string]string
+}
+
+func newRule(config domain.AlertRuleConfig) (*rule, error) {
+	r := &rule{
+		name:             config.Name,
+		threshold:        config.Threshold,
+		resolveThreshold: config.Threshold,
+		window:           config.Window,
+		groupBy:          config.GroupBy,
+		forDuration:      config.For,
+		resolveAfter:     config.ResolveAfter,
+		severity:         config.Severity,
+		groups:           make(map[string]*group),
+	}
+
+	if config.ResolveThreshold != nil {
+		r.resolveThreshold = *config.ResolveThreshold
+	}
+	if r.window <= 0 {
+		r.window = defaultWindow
+	}
+	if r.severity == "" {
+		r.severity = defaultSeverity
+	}
+
+	r.bucket = max(r.window/bucketsPerWindow, time.Second)
+
+	var err error
+
+	if config.Condition != "" {
+		if r.condition, err = expr.Compile(config.Condition); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", domain.ErrInvalidAlertRule, r.name, err)
+		}
+	}
+
+	summary := config.Summary
+	if summary == "" {
+		summary = defaultSummary
+	}
+
+	if r.summary, err = template.New(r.name).Parse(summary); err != nil {
+		return nil, fmt.Errorf("%w: %s: summary: %w", domain.ErrInvalidAlertRule, r.name, err)
+	}
+
+	return r, nil
+}
+
+// observe counts the event when it matches the condition
+func (r *rule) observe(event domain.LogEvent, now time.Time) bool {
+	if r.condition != nil && !r.condition.Match(event) {
+		return false
+	}
+
+	key, labels := r.groupKey(event)
+
+	g, ok := r.groups[key]
+	if !ok {
+		g = &group{labels: labels}
+		r.groups[key] = g
+	}
+
+	start := now.Truncate(r.bucket)
+	if n := len(g.buckets); n > 0 && g.buckets[n-1].start.Equal(start) {
+		g.buckets[n-1].count++
+	} else {
+		g.buckets = append(g.buckets, bucket{start: start, count: 1})
+	}
+
+	g.samples = append(g.samples, event)
+	if len(g.samples) > maxSamples {
+		g.samples = g.samples[len(g.samples)-maxSamples:]
+	}
+
+	return true
+}
+
+// evaluate moves the groups through inactive, pending and firing, returning the alerts that
+// fired or resolved
+func (r *rule) evaluate(now time.Time) []domain.Alert {
+	var alerts []domain.Alert
+
+	for key, g := range r.groups {
+		count := g.count(now.Add(-r.window))
+
+		switch g.status {
+		case statusInactive, statusPending:
+			if count <= r.threshold {
+				g.status = statusInactive
+				break
+			}
+
+			if g.status == statusInactive {
+				g.status = statusPending
+				g.since = now
+			}
+
+			if now.Sub(g.since) >= r.forDuration {
+				g.status = statusFiring
+				g.startsAt = now
+				g.quietSince = time.Time{}
+				alerts = append(alerts, r.alert(g, domain.ALERT_STATE_FIRING, count, time.Time{}))
+			}
+		case statusFiring:
+			if count > r.resolveThreshold {
+				g.quietSince = time.Time{}
+				break
+			}
+
+			if g.quietSince.IsZero() {
+				g.quietSince = now
+			}
+
+			if now.Sub(g.quietSince) >= r.resolveAfter {
+				g.status = statusInactive
+				alerts = append(alerts, r.alert(g, domain.ALERT_STATE_RESOLVED, count, now))
+			}
+		}
+
+		if g.status == statusInactive && len(g.buckets) == 0 {
+			delete(r.groups, key)
+		}
+	}
+
+	return alerts
+}
+
+func (r *rule) alert(g *group, state domain.AlertState, count int, endsAt time.Time) domain.Alert {
+	return domain.Alert{
+		Fingerprint: domain.AlertFingerprint(r.name, g.labels),
+		Rule:        r.name,
+		State:       state,
+		Severity:    r.severity,
+		Summary:     r.render(g.labels, count),
+		Labels:      g.labels,
+		Count:       count,
+		StartsAt:    g.startsAt,
+		EndsAt:      endsAt,
+		Samples:     append([]domain.LogEvent(nil), g.samples...),
+	}
+}
+
+// render executes the summary template, falling back to the rule name when it fails
+func (r *rule) render(labels map[string]string, count int) string {
+	var buffer bytes.Buffer
+
+	data := summaryData{Rule: r.name, Count: count, Threshold: r.threshold, Window: r.window, Labels: labels}
+	if err := r.summary.Execute(&buffer, data); err != nil {
+		return r.name
+	}
+
+	return buffer.String()
+}
+
+func (r *rule) groupKey(event domain.LogEvent) (string, map[string]string) {
+	labels := make(map[string]string, len(r.groupBy))
+	key := ""
+
+	for _, field := range r.groupBy {
+		value, _ := event.Field(field)
+
+		text := fmt.Sprint(value)
+		if value == nil {
+			text = ""
+		}
+
+		labels[field] = text
+		key += field + "=" + text + "\x00"
+	}
+
+	return key, labels
+}
+
+// count drops the buckets older than since and sums the others
+func (g *group) count(since time.Time) int {
+	first := 0
+	for first < len(g.buckets) && !g.buckets[first].start.After(since) {
+		first++
+	}
+	g.buckets = g.buckets[first:]
+
+	total := 0
+	for _, b := range g.buckets {
+		total += b.count
+	}
+
+	return total
+}
@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"log-guardian/internal/core/domain"
+	"maps"
+	"time"
+)
+
+// charsPerToken is the average length of a token in English text and logs for the common tokenizers
+const charsPerToken = 4
+
+// minMessageLength is the length the message of the event is never trimmed below
+const minMessageLength = 200
+
+// EstimateTokens returns the approximate number of tokens of the text
+func EstimateTokens(text string) int {
+	return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+}
+
+// Budget trims the prompt data until the rendered prompt fits in MaxTokens
+type Budget struct {
+	MaxTokens int
+}
+
+// Fit drops the least important data first: the surrounding events the farthest from the event, then
+// the largest enrichment values, then the template, and finally shortens the message of the event.
+// The last prompt is returned when nothing is left to trim
+func (b Budget) Fit(data PromptData, render func(PromptData) (string, error)) (string, error) {
+	data.Context = append([]domain.LogEvent(nil), data.Context...)
+	data.Enrichment = maps.Clone(data.Enrichment)
+
+	for {
+		prompt, err := render(data)
+		if err != nil {
+			return "", err
+		}
+
+		if EstimateTokens(prompt) <= b.MaxTokens || !b.trim(&data, EstimateTokens(prompt)) {
+			return prompt, nil
+		}
+	}
+}
+
+// trim drops one piece of data, it returns false when nothing is left to drop
+func (b Budget) trim(data *PromptData, tokens int) bool {
+	switch {
+	case len(data.Context) > 0:
+		farthest := farthest(data.Event, data.Context)
+		data.Context = append(data.Context[:farthest], data.Context[farthest+1:]...)
+	case len(data.Enrichment) > 0:
+		delete(data.Enrichment, sortedKeys(data.Enrichment)[0])
+	case data.Template != nil:
+		data.Template = nil
+	default:
+		message := []rune(data.Event.Message)
+		excess := (tokens - b.MaxTokens) * charsPerToken
+		// the ellipsis added by truncate counts as one rune
+		length := max(len(message)-excess-1, minMessageLength)
+		if length+1 >= len(message) {
+			return false
+		}
+		data.Event.Message = truncate(length, data.Event.Message)
+	}
+
+	return true
+}
+
+// farthest returns the index of the event the farthest in time from the event
+func farthest(event domain.LogEvent, events []domain.LogEvent) int {
+	index := 0
+
+	for i := range events {
+		if distance(event, events[i]) > distance(event, events[index]) {
+			index = i
+		}
+	}
+
+	return index
+}
+
+func distance(event, other domain.LogEvent) time.Duration {
+	d := event.Timestamp.Sub(other.Timestamp)
+	if d < 0 {
+		d = -d
+	}
+
+	return d
+}
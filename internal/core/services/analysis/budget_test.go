@@ -0,0 +1,102 @@
+package analysis_test
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/analysis"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, analysis.EstimateTokens(""))
+	assert.Equal(t, 1, analysis.EstimateTokens("abc"))
+	assert.Equal(t, 2, analysis.EstimateTokens("abcde"))
+	assert.Equal(t, 1, analysis.EstimateTokens("éèàù"))
+}
+
+// describe renders everything the budget may trim, the enrichment sorted by key
+func describe(data analysis.PromptData) (string, error) {
+	var parts []string
+
+	for _, event := range data.Context {
+		parts = append(parts, "ctx:"+event.Message)
+	}
+
+	keys := make([]string, 0, len(data.Enrichment))
+	for key := range data.Enrichment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, data.Enrichment[key]))
+	}
+
+	if data.Template != nil {
+		parts = append(parts, "tpl:"+data.Template.Pattern)
+	}
+
+	return strings.Join(append(parts, "msg:"+data.Event.Message), " "), nil
+}
+
+func TestBudget_Fit(t *testing.T) {
+	data := analysis.PromptData{
+		Event: domain.LogEvent{Timestamp: at, Message: strings.Repeat("x", 400)},
+		Context: []domain.LogEvent{
+			{Timestamp: at.Add(-time.Minute), Message: "old"},
+			{Timestamp: at.Add(-time.Second), Message: "close"},
+			{Timestamp: at.Add(2 * time.Second), Message: "after"},
+		},
+		Enrichment: map[string]interface{}{"pod": "api", "snippet": strings.Repeat("s", 40)},
+		Template:   &analysis.TemplateStats{Pattern: "x"},
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		expected  string
+	}{
+		{
+			name:      "ShouldKeepEverythingWithinBudget",
+			maxTokens: 1000,
+			expected:  "ctx:old ctx:close ctx:after pod=api snippet=" + strings.Repeat("s", 40) + " tpl:x msg:" + strings.Repeat("x", 400),
+		},
+		{
+			name:      "ShouldDropFarthestContextFirst",
+			maxTokens: 120,
+			expected:  "ctx:close pod=api snippet=" + strings.Repeat("s", 40) + " tpl:x msg:" + strings.Repeat("x", 400),
+		},
+		{
+			name:      "ShouldDropLargestEnrichmentThen",
+			maxTokens: 106,
+			expected:  "pod=api tpl:x msg:" + strings.Repeat("x", 400),
+		},
+		{
+			name:      "ShouldTruncateMessageLast",
+			maxTokens: 80,
+			expected:  "msg:" + strings.Repeat("x", 315) + "…",
+		},
+		{
+			name:      "ShouldNotTruncateMessageBelowMinimum",
+			maxTokens: 1,
+			expected:  "msg:" + strings.Repeat("x", 200) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, err := analysis.Budget{MaxTokens: tt.maxTokens}.Fit(data, describe)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, prompt)
+		})
+	}
+
+	assert.Len(t, data.Context, 3, "the data of the caller is left untouched")
+	assert.Len(t, data.Enrichment, 2)
+}
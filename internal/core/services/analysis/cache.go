@@ -0,0 +1,308 @@
+package analysis
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// InvalidateAll invalidates every cached analysis
+	InvalidateAll = "all"
+
+	defaultCacheTTL     = 24 * time.Hour
+	defaultCacheEntries = 10000
+	// saveInterval batches the changes saved to the store by Run
+	saveInterval = 5 * time.Second
+)
+
+// Cache keeps the analyses in memory for their TTL. The changes are saved to the store, when there
+// is one, by Run and Flush outside of the lock taken by the lookups, and the analyses invalidated
+// in the store by another process are dropped. The oldest analysis is evicted when the cache is full
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	clock      domain.Clock
+	store      ports.AnalysisStore
+
+	// saving serializes the saves, they don't hold mu while the store is written
+	saving  sync.Mutex
+	mu      sync.Mutex
+	entries map[string]*domain.CachedAnalysis
+	dirty   bool
+	// persisted is when the analyses in the store were cached, by key
+	persisted map[string]time.Time
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	stored      atomic.Uint64
+	expired     atomic.Uint64
+	evicted     atomic.Uint64
+	invalidated atomic.Uint64
+	saveErrors  atomic.Uint64
+}
+
+// NewCache restores the analyses saved by the store, the store is optional
+func NewCache(config domain.AnalysisCacheConfig, clock domain.Clock, store ports.AnalysisStore) (*Cache, error) {
+	c := &Cache{
+		ttl:        config.TTL,
+		maxEntries: config.MaxEntries,
+		clock:      clock,
+		store:      store,
+		entries:    make(map[string]*domain.CachedAnalysis),
+	}
+
+	if c.ttl <= 0 {
+		c.ttl = defaultCacheTTL
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = defaultCacheEntries
+	}
+
+	if store == nil {
+		return c, nil
+	}
+
+	saved, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	for _, entry := range saved {
+		if now.Before(entry.ExpiresAt) {
+			c.entries[entry.Key] = &entry
+		}
+	}
+	c.persisted = cachedAt(saved)
+
+	return c, nil
+}
+
+// Get returns the analysis of the key flagged as cached, unless it expired
+func (c *Cache) Get(key string) (domain.Analysis, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && !c.clock.Now().Before(entry.ExpiresAt) {
+		delete(c.entries, key)
+		c.expired.Add(1)
+		ok = false
+	}
+
+	if !ok {
+		c.misses.Add(1)
+		return domain.Analysis{}, false
+	}
+
+	entry.Hits++
+	c.hits.Add(1)
+
+	analysis := entry.Analysis
+	analysis.Cached = true
+
+	return analysis, true
+}
+
+// Set caches the analysis made for the events with the fingerprint
+func (c *Cache) Set(key, fingerprint string, analysis domain.Analysis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evictOldest()
+	}
+
+	now := c.clock.Now()
+	c.entries[key] = &domain.CachedAnalysis{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Analysis:    analysis,
+		CachedAt:    now,
+		ExpiresAt:   now.Add(c.ttl),
+	}
+	c.stored.Add(1)
+	c.dirty = true
+}
+
+// Invalidate removes the analyses of the key or the fingerprint, or all of them with InvalidateAll,
+// it returns how many were removed
+func (c *Cache) Invalidate(target string) (int, error) {
+	c.mu.Lock()
+	removed := 0
+	for key, entry := range c.entries {
+		if target == InvalidateAll || key == target || entry.Fingerprint == target {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	c.dirty = c.dirty || removed > 0
+	c.mu.Unlock()
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, c.Flush()
+}
+
+// Run saves the changes every saveInterval in the background until the context is done, Flush
+// saves the last ones
+func (c *Cache) Run(ctx context.Context) {
+	if c.store == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(saveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = c.Flush()
+			}
+		}
+	}()
+}
+
+// Flush drops the analyses invalidated by another process, then saves the analyses when they
+// changed since the last save. On failure they stay unsaved so the next flush retries
+func (c *Cache) Flush() error {
+	if c.store == nil {
+		return nil
+	}
+
+	c.saving.Lock()
+	defer c.saving.Unlock()
+
+	if err := c.reload(); err != nil {
+		c.saveErrors.Add(1)
+		return err
+	}
+
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	entries := c.snapshot()
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err := c.store.Save(entries); err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+
+		c.saveErrors.Add(1)
+		return err
+	}
+
+	c.mu.Lock()
+	c.persisted = cachedAt(entries)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// reload drops the saved analyses missing from the store when another process changed it, so
+// invalidate-analysis applies to a running process. The analyses cached since are kept
+func (c *Cache) reload() error {
+	changed, err := c.store.Changed()
+	if err != nil || !changed {
+		return err
+	}
+
+	saved, err := c.store.Load()
+	if err != nil {
+		return err
+	}
+	kept := cachedAt(saved)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, at := range c.persisted {
+		entry, ok := c.entries[key]
+		if _, found := kept[key]; !found && ok && entry.CachedAt.Equal(at) {
+			delete(c.entries, key)
+			c.invalidated.Add(1)
+		}
+	}
+	c.persisted = kept
+
+	return nil
+}
+
+// Entries returns the cached analyses, the most recent first
+func (c *Cache) Entries() []domain.CachedAnalysis {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.snapshot()
+}
+
+func (c *Cache) Stats() map[string]uint64 {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return map[string]uint64{
+		"hits":        c.hits.Load(),
+		"misses":      c.misses.Load(),
+		"stored":      c.stored.Load(),
+		"expired":     c.expired.Load(),
+		"evicted":     c.evicted.Load(),
+		"invalidated": c.invalidated.Load(),
+		"entries":     uint64(entries),
+		"save_errors": c.saveErrors.Load(),
+	}
+}
+
+func (c *Cache) evictOldest() {
+	var oldest *domain.CachedAnalysis
+
+	for _, entry := range c.entries {
+		if oldest == nil || entry.CachedAt.Before(oldest.CachedAt) {
+			oldest = entry
+		}
+	}
+
+	if oldest != nil {
+		delete(c.entries, oldest.Key)
+		c.evicted.Add(1)
+	}
+}
+
+func cachedAt(entries []domain.CachedAnalysis) map[string]time.Time {
+	at := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		at[entry.Key] = entry.CachedAt
+	}
+
+	return at
+}
+
+func (c *Cache) snapshot() []domain.CachedAnalysis {
+	entries := make([]domain.CachedAnalysis, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CachedAt.Equal(entries[j].CachedAt) {
+			return entries[i].CachedAt.After(entries[j].CachedAt)
+		}
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
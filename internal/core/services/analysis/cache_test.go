@@ -0,0 +1,246 @@
+package analysis_test
+
+import (
+	"errors"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/analysis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type memoryStore struct {
+	saved   []domain.CachedAnalysis
+	saves   int
+	changed bool
+	err     error
+}
+
+func (s *memoryStore) Load() ([]domain.CachedAnalysis, error) {
+	return s.saved, s.err
+}
+
+func (s *memoryStore) Save(analyses []domain.CachedAnalysis) error {
+	s.saves++
+	if s.err != nil {
+		return s.err
+	}
+	s.saved = analyses
+
+	return nil
+}
+
+func (s *memoryStore) Changed() (bool, error) {
+	changed := s.changed
+	s.changed = false
+
+	return changed, nil
+}
+
+func TestCache_GetSet(t *testing.T) {
+	now := at
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{TTL: time.Hour}, clock, nil)
+	require.NoError(t, err)
+
+	_, ok := cache.Get("tpl:abc")
+	assert.False(t, ok)
+
+	cache.Set("tpl:abc", "abc", domain.Analysis{Summary: "db down"})
+
+	cached, ok := cache.Get("tpl:abc")
+	require.True(t, ok)
+	assert.Equal(t, domain.Analysis{Summary: "db down", Cached: true}, cached)
+
+	now = at.Add(time.Hour)
+	_, ok = cache.Get("tpl:abc")
+	assert.False(t, ok, "the analysis expires after the ttl")
+
+	assert.Equal(t, map[string]uint64{
+		"hits":        1,
+		"misses":      2,
+		"stored":      1,
+		"expired":     1,
+		"evicted":     0,
+		"invalidated": 0,
+		"entries":     0,
+		"save_errors": 0,
+	}, cache.Stats())
+}
+
+func TestCache_ShouldEvictOldest(t *testing.T) {
+	now := at
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{MaxEntries: 2}, clock, nil)
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, key, domain.Analysis{Summary: key})
+		now = now.Add(time.Second)
+	}
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), cache.Stats()["evicted"])
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		expected     int
+		expectedKeys []string
+	}{
+		{name: "ShouldInvalidateByFingerprint", target: "abc", expected: 2, expectedKeys: []string{"t1:def"}},
+		{name: "ShouldInvalidateByKey", target: "t2:abc", expected: 1, expectedKeys: []string{"t1:abc", "t1:def"}},
+		{name: "ShouldInvalidateAll", target: analysis.InvalidateAll, expected: 3, expectedKeys: []string{}},
+		{name: "ShouldIgnoreUnknown", target: "zzz", expected: 0, expectedKeys: []string{"t1:abc", "t1:def", "t2:abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			clock := domain.NewMockClock(gomock.NewController(t))
+			clock.EXPECT().Now().Return(at).AnyTimes()
+			cache, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, store)
+			require.NoError(t, err)
+
+			cache.Set("t1:abc", "abc", domain.Analysis{})
+			cache.Set("t2:abc", "abc", domain.Analysis{})
+			cache.Set("t1:def", "def", domain.Analysis{})
+			require.NoError(t, cache.Flush())
+
+			removed, err := cache.Invalidate(tt.target)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, removed)
+
+			keys := []string{}
+			for _, entry := range cache.Entries() {
+				keys = append(keys, entry.Key)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+			assert.Len(t, store.saved, len(tt.expectedKeys))
+		})
+	}
+}
+
+func TestCache_Persistence(t *testing.T) {
+	store := &memoryStore{saved: []domain.CachedAnalysis{
+		{Key: "t:live", Fingerprint: "live", Analysis: domain.Analysis{Summary: "kept"}, ExpiresAt: at.Add(time.Minute)},
+		{Key: "t:old", Fingerprint: "old", Analysis: domain.Analysis{Summary: "expired"}, ExpiresAt: at},
+	}}
+
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().Return(at).AnyTimes()
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{TTL: time.Hour}, clock, store)
+	require.NoError(t, err)
+
+	cached, ok := cache.Get("t:live")
+	require.True(t, ok)
+	assert.Equal(t, "kept", cached.Summary)
+
+	_, ok = cache.Get("t:old")
+	assert.False(t, ok)
+
+	cache.Set("t:new", "new", domain.Analysis{Summary: "fresh"})
+	require.NoError(t, cache.Flush())
+
+	require.Len(t, store.saved, 2)
+	assert.Equal(t, domain.CachedAnalysis{
+		Key:         "t:new",
+		Fingerprint: "new",
+		Analysis:    domain.Analysis{Summary: "fresh"},
+		CachedAt:    at,
+		ExpiresAt:   at.Add(time.Hour),
+	}, store.saved[0])
+	assert.Equal(t, 1, store.saved[1].Hits)
+}
+
+func TestCache_StoreErrors(t *testing.T) {
+	broken := errors.New("disk full")
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().Return(at).AnyTimes()
+
+	_, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, &memoryStore{err: broken})
+	assert.ErrorIs(t, err, broken)
+
+	store := &memoryStore{}
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, store)
+	require.NoError(t, err)
+
+	store.err = broken
+	cache.Set("t:abc", "abc", domain.Analysis{})
+	assert.ErrorIs(t, cache.Flush(), broken)
+
+	_, ok := cache.Get("t:abc")
+	assert.True(t, ok, "the analysis is cached in memory even when it can't be saved")
+	assert.Equal(t, uint64(1), cache.Stats()["save_errors"])
+
+	_, err = cache.Invalidate("abc")
+	assert.ErrorIs(t, err, broken)
+}
+
+func TestCache_Flush(t *testing.T) {
+	broken := errors.New("disk full")
+	store := &memoryStore{}
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().Return(at).AnyTimes()
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, store)
+	require.NoError(t, err)
+
+	cache.Set("t:abc", "abc", domain.Analysis{})
+	cache.Set("t:def", "def", domain.Analysis{})
+	assert.Equal(t, 0, store.saves, "the changes are saved on flush")
+
+	store.err = broken
+	assert.ErrorIs(t, cache.Flush(), broken)
+
+	store.err = nil
+	require.NoError(t, cache.Flush())
+	assert.Len(t, store.saved, 2, "the failed save is retried")
+
+	require.NoError(t, cache.Flush())
+	assert.Equal(t, 2, store.saves, "nothing is saved when nothing changed")
+
+	memory, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, nil)
+	require.NoError(t, err)
+	memory.Set("t:abc", "abc", domain.Analysis{})
+	assert.NoError(t, memory.Flush(), "a cache without store has nothing to save")
+}
+
+func TestCache_ShouldDropAnalysesInvalidatedByAnotherProcess(t *testing.T) {
+	now := at
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	store := &memoryStore{}
+	cache, err := analysis.NewCache(domain.AnalysisCacheConfig{}, clock, store)
+	require.NoError(t, err)
+
+	cache.Set("t:abc", "abc", domain.Analysis{})
+	cache.Set("t:def", "def", domain.Analysis{})
+	require.NoError(t, cache.Flush())
+
+	// invalidate-analysis removes t:abc from the store while t:def is analyzed again
+	store.saved = store.saved[1:]
+	store.changed = true
+	now = at.Add(time.Minute)
+	cache.Set("t:def", "def", domain.Analysis{Summary: "again"})
+	cache.Set("t:ghi", "ghi", domain.Analysis{})
+
+	require.NoError(t, cache.Flush())
+
+	_, ok := cache.Get("t:abc")
+	assert.False(t, ok, "the invalidated analysis is dropped")
+	_, ok = cache.Get("t:def")
+	assert.True(t, ok, "the analysis cached since is kept")
+	assert.Len(t, store.saved, 2)
+	assert.Equal(t, uint64(1), cache.Stats()["invalidated"])
+}
@@ -0,0 +1,318 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBudgetPeriod = 24 * time.Hour
+	// rateLimitPause is how long a provider rate limiting the requests is left alone
+	rateLimitPause = time.Minute
+)
+
+// Fallback tries the providers in order until one answers. A provider rate limiting the requests,
+// unavailable, blocking the content or answering an invalid analysis falls back to the next one, a
+// rejected request fails right away. The providers over their requests or tokens per minute are
+// skipped, and so are the paid ones once the budget of the pipeline is spent
+type Fallback struct {
+	providers []*provider
+	budget    domain.CostBudgetConfig
+	period    time.Duration
+	clock     domain.Clock
+
+	mu          sync.Mutex
+	periodStart time.Time
+	spent       map[string]float64
+	total       float64
+	usage       map[usageKey]*domain.AIUsage
+
+	requests   atomic.Uint64
+	fallbacks  atomic.Uint64
+	failed     atomic.Uint64
+	limited    atomic.Uint64
+	overBudget atomic.Uint64
+}
+
+type provider struct {
+	analyzer    ports.AIAnalyzer
+	pricing     domain.AIPricing
+	requests    *limiter
+	tokens      *limiter
+	pausedUntil time.Time
+}
+
+type usageKey struct {
+	pipeline string
+	provider string
+}
+
+// NewFallback pairs the analyzers with the providers of the config by name, they are tried in the
+// order of the config
+func NewFallback(config domain.AIConfig, clock domain.Clock, analyzers ...ports.AIAnalyzer) (*Fallback, error) {
+	byName := make(map[string]ports.AIAnalyzer, len(analyzers))
+	for _, analyzer := range analyzers {
+		byName[analyzer.Name()] = analyzer
+	}
+
+	f := &Fallback{
+		budget: config.Budget,
+		period: config.Budget.Period,
+		clock:  clock,
+		spent:  make(map[string]float64),
+		usage:  make(map[usageKey]*domain.AIUsage),
+	}
+
+	if f.period <= 0 {
+		f.period = defaultBudgetPeriod
+	}
+
+	for _, providerConfig := range config.Providers {
+		analyzer, ok := byName[providerConfig.ProviderName()]
+		if !ok {
+			return nil, fmt.Errorf("%w: no analyzer for %s", domain.ErrInvalidAIProvider, providerConfig.ProviderName())
+		}
+
+		f.providers = append(f.providers, &provider{
+			analyzer: analyzer,
+			pricing:  providerConfig.Pricing,
+			requests: newLimiter(providerConfig.RequestsPerMinute),
+			tokens:   newLimiter(providerConfig.TokensPerMinute),
+		})
+	}
+
+	if len(f.providers) == 0 {
+		return nil, fmt.Errorf("%w: no provider configured", domain.ErrInvalidAIProvider)
+	}
+
+	return f, nil
+}
+
+// Name returns the names of the providers in the order they are tried
+func (f *Fallback) Name() string {
+	names := make([]string, 0, len(f.providers))
+	for _, p := range f.providers {
+		names = append(names, p.analyzer.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Analyze returns the analysis of the first provider answering, the error of each attempt otherwise
+func (f *Fallback) Analyze(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+	f.requests.Add(1)
+	estimate := EstimateTokens(request.Prompt)
+
+	var errs attemptErrors
+	for _, p := range f.providers {
+		if len(errs) > 0 {
+			f.fallbacks.Add(1)
+		}
+
+		if err := f.reserve(p, request.Pipeline, estimate); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.analyzer.Name(), err))
+			continue
+		}
+
+		result, err := p.analyzer.Analyze(ctx, request)
+		if err == nil {
+			return f.record(p, request.Pipeline, estimate, result), nil
+		}
+
+		errs = append(errs, err)
+		if errors.Is(err, domain.ErrRateLimited) {
+			f.pause(p)
+		}
+
+		if ctx.Err() != nil || !fallsBack(err) {
+			break
+		}
+	}
+
+	f.failed.Add(1)
+
+	return domain.Analysis{}, errs
+}
+
+// Usage returns the analyses made by each provider for each pipeline, sorted by pipeline and provider
+func (f *Fallback) Usage() []domain.AIUsage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	usage := make([]domain.AIUsage, 0, len(f.usage))
+	for _, u := range f.usage {
+		usage = append(usage, *u)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Pipeline != usage[j].Pipeline {
+			return usage[i].Pipeline < usage[j].Pipeline
+		}
+		return usage[i].Provider < usage[j].Provider
+	})
+
+	return usage
+}
+
+func (f *Fallback) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"requests":     f.requests.Load(),
+		"fallbacks":    f.fallbacks.Load(),
+		"failed":       f.failed.Load(),
+		"rate_limited": f.limited.Load(),
+		"over_budget":  f.overBudget.Load(),
+	}
+}
+
+// reserve takes a request from the limits of the provider, unless it is paused, over its limits, or
+// paid while the budget of the pipeline is spent
+func (f *Fallback) reserve(p *provider, pipeline string, estimate int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.clock.Now()
+
+	if p.pricing.Paid() && f.exhausted(pipeline, now) {
+		f.overBudget.Add(1)
+		return domain.ErrBudgetExhausted
+	}
+
+	if now.Before(p.pausedUntil) {
+		f.limited.Add(1)
+		return fmt.Errorf("%w: paused until %s", domain.ErrRateLimited, p.pausedUntil.Format(time.RFC3339))
+	}
+
+	if !p.requests.allows(1, now) || !p.tokens.allows(estimate, now) {
+		f.limited.Add(1)
+		return fmt.Errorf("%w: over the limits per minute", domain.ErrRateLimited)
+	}
+
+	p.requests.take(1)
+
+	return nil
+}
+
+// record takes the tokens of the analysis from the limits of the provider, and adds its cost to the
+// spending of the pipeline
+func (f *Fallback) record(p *provider, pipeline string, estimate int, result domain.Analysis) domain.Analysis {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tokens := result.Usage.Total()
+	if tokens == 0 {
+		tokens = estimate
+	}
+	p.tokens.take(tokens)
+
+	result.Cost = p.pricing.Cost(result.Usage)
+	f.exhausted(pipeline, f.clock.Now())
+	f.spent[pipeline] += result.Cost
+	f.total += result.Cost
+
+	key := usageKey{pipeline: pipeline, provider: p.analyzer.Name()}
+	usage, ok := f.usage[key]
+	if !ok {
+		usage = &domain.AIUsage{Pipeline: pipeline, Provider: key.provider}
+		f.usage[key] = usage
+	}
+	usage.Requests++
+	usage.Tokens.Prompt += result.Usage.Prompt
+	usage.Tokens.Completion += result.Usage.Completion
+	usage.Cost += result.Cost
+
+	return result
+}
+
+func (f *Fallback) pause(p *provider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p.pausedUntil = f.clock.Now().Add(rateLimitPause)
+}
+
+// exhausted reports whether the budget of the pipeline, or the total one, is spent. The spending
+// is reset at the start of each period
+func (f *Fallback) exhausted(pipeline string, now time.Time) bool {
+	if start := now.Truncate(f.period); start.After(f.periodStart) {
+		f.periodStart = start
+		f.spent = make(map[string]float64)
+		f.total = 0
+	}
+
+	if f.budget.Limit > 0 && f.total >= f.budget.Limit {
+		return true
+	}
+
+	limit := f.budget.LimitOf(pipeline)
+
+	return limit > 0 && f.spent[pipeline] >= limit
+}
+
+// fallsBack reports whether another provider may answer after the error
+func fallsBack(err error) bool {
+	return errors.Is(err, domain.ErrRateLimited) ||
+		errors.Is(err, domain.ErrProviderUnavailable) ||
+		errors.Is(err, domain.ErrContentBlocked) ||
+		errors.Is(err, domain.ErrInvalidAnalysis)
+}
+
+// attemptErrors holds the error of each provider tried, it matches all of them
+type attemptErrors []error
+
+func (e attemptErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e attemptErrors) Unwrap() []error {
+	return e
+}
+
+// limiter is a bucket refilled with its capacity every minute, a nil limiter allows everything.
+// The taken amount may exceed what is left, the next requests wait for the bucket to refill
+type limiter struct {
+	capacity float64
+	tokens   float64
+	updated  time.Time
+}
+
+func newLimiter(perMinute int) *limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &limiter{capacity: float64(perMinute), tokens: float64(perMinute)}
+}
+
+// allows reports whether the amount is left, the amounts larger than the capacity only need a full bucket
+func (l *limiter) allows(amount int, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	if !l.updated.IsZero() {
+		l.tokens = math.Min(l.capacity, l.tokens+now.Sub(l.updated).Minutes()*l.capacity)
+	}
+	l.updated = now
+
+	return l.tokens >= math.Min(float64(amount), l.capacity)
+}
+
+func (l *limiter) take(amount int) {
+	if l != nil {
+		l.tokens -= float64(amount)
+	}
+}
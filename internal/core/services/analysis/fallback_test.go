@@ -0,0 +1,267 @@
+package analysis_test
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/analysis"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newProvider(ctrl *gomock.Controller, name string) *ports.MockAIAnalyzer {
+	analyzer := ports.NewMockAIAnalyzer(ctrl)
+	analyzer.EXPECT().Name().AnyTimes().Return(name)
+
+	return analyzer
+}
+
+func TestFallback_ErrorClasses(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		fallsBack     bool
+		expectedError error
+	}{
+		{name: "ShouldFallBackWhenRateLimited", err: domain.ErrRateLimited, fallsBack: true},
+		{name: "ShouldFallBackWhenUnavailable", err: domain.ErrProviderUnavailable, fallsBack: true},
+		{name: "ShouldFallBackWhenBlocked", err: domain.ErrContentBlocked, fallsBack: true},
+		{name: "ShouldFallBackOnInvalidAnalysis", err: domain.ErrInvalidAnalysis, fallsBack: true},
+		{name: "ShouldStopWhenRejected", err: domain.ErrAnalysisFailed, expectedError: domain.ErrAnalysisFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			primary := newProvider(ctrl, "openai")
+			secondary := newProvider(ctrl, "ollama")
+
+			primary.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{}, fmt.Errorf("openai: %w", tt.err))
+			if tt.fallsBack {
+				secondary.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{Summary: "local"}, nil)
+			}
+
+			clock := domain.NewMockClock(ctrl)
+			clock.EXPECT().Now().Return(at).AnyTimes()
+
+			fallback, err := analysis.NewFallback(domain.AIConfig{Providers: []domain.AIProviderConfig{
+				{Type: domain.AI_PROVIDER_OPENAI},
+				{Type: domain.AI_PROVIDER_OLLAMA},
+			}}, clock, secondary, primary)
+			require.NoError(t, err)
+
+			result, err := fallback.Analyze(context.Background(), domain.AnalysisRequest{Pipeline: "default"})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.fallsBack {
+				assert.Equal(t, "local", result.Summary)
+			}
+		})
+	}
+}
+
+func TestFallback_ShouldPauseRateLimitedProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	primary := newProvider(ctrl, "openai")
+	secondary := newProvider(ctrl, "ollama")
+	now := at
+	clock := domain.NewMockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	primary.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{}, domain.ErrRateLimited)
+	secondary.EXPECT().Analyze(gomock.Any(), gomock.Any()).Times(2).Return(domain.Analysis{Summary: "local"}, nil)
+
+	fallback, err := analysis.NewFallback(domain.AIConfig{Providers: []domain.AIProviderConfig{
+		{Type: domain.AI_PROVIDER_OPENAI},
+		{Type: domain.AI_PROVIDER_OLLAMA},
+	}}, clock, primary, secondary)
+	require.NoError(t, err)
+
+	_, err = fallback.Analyze(context.Background(), domain.AnalysisRequest{})
+	require.NoError(t, err)
+
+	now = at.Add(30 * time.Second)
+	_, err = fallback.Analyze(context.Background(), domain.AnalysisRequest{})
+	require.NoError(t, err, "the rate limited provider isn't called while paused")
+
+	now = at.Add(time.Minute)
+	primary.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{Summary: "remote"}, nil)
+
+	result, err := fallback.Analyze(context.Background(), domain.AnalysisRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "remote", result.Summary)
+
+	assert.Equal(t, map[string]uint64{
+		"requests":     3,
+		"fallbacks":    2,
+		"failed":       0,
+		"rate_limited": 1,
+		"over_budget":  0,
+	}, fallback.Stats())
+	assert.Equal(t, "openai,ollama", fallback.Name())
+}
+
+func TestFallback_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   domain.AIProviderConfig
+		usage    domain.TokenUsage
+		prompt   string
+		expected []string
+	}{
+		{
+			name:     "ShouldLimitRequestsPerMinute",
+			config:   domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, RequestsPerMinute: 2},
+			expected: []string{"openai", "openai", "ollama", "openai"},
+		},
+		{
+			name:     "ShouldLimitTokensPerMinute",
+			config:   domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, TokensPerMinute: 100},
+			usage:    domain.TokenUsage{Prompt: 80, Completion: 40},
+			expected: []string{"openai", "ollama", "ollama", "openai"},
+		},
+		{
+			name:     "ShouldEstimateTokensWithoutUsage",
+			config:   domain.AIProviderConfig{Type: domain.AI_PROVIDER_OPENAI, TokensPerMinute: 10},
+			prompt:   strings.Repeat("x", 40),
+			expected: []string{"openai", "ollama", "ollama", "openai"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			primary := newProvider(ctrl, "openai")
+			secondary := newProvider(ctrl, "ollama")
+			now := at
+			clock := domain.NewMockClock(ctrl)
+			clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+			primary.EXPECT().Analyze(gomock.Any(), gomock.Any()).AnyTimes().Return(domain.Analysis{Provider: "openai", Usage: tt.usage}, nil)
+			secondary.EXPECT().Analyze(gomock.Any(), gomock.Any()).AnyTimes().Return(domain.Analysis{Provider: "ollama"}, nil)
+
+			fallback, err := analysis.NewFallback(domain.AIConfig{Providers: []domain.AIProviderConfig{
+				tt.config,
+				{Type: domain.AI_PROVIDER_OLLAMA},
+			}}, clock, primary, secondary)
+			require.NoError(t, err)
+
+			var providers []string
+			for i := range tt.expected {
+				if i == len(tt.expected)-1 {
+					now = at.Add(time.Minute)
+				}
+
+				result, err := fallback.Analyze(context.Background(), domain.AnalysisRequest{Prompt: tt.prompt})
+				require.NoError(t, err)
+				providers = append(providers, result.Provider)
+			}
+
+			assert.Equal(t, tt.expected, providers)
+		})
+	}
+}
+
+func TestFallback_Budget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paid := newProvider(ctrl, "openai")
+	free := newProvider(ctrl, "ollama")
+	now := at
+	clock := domain.NewMockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	paid.EXPECT().Analyze(gomock.Any(), gomock.Any()).AnyTimes().Return(domain.Analysis{
+		Provider: "openai",
+		Usage:    domain.TokenUsage{Prompt: 100000, Completion: 10000},
+	}, nil)
+	free.EXPECT().Analyze(gomock.Any(), gomock.Any()).AnyTimes().Return(domain.Analysis{
+		Provider: "ollama",
+		Usage:    domain.TokenUsage{Prompt: 1000, Completion: 100},
+	}, nil)
+
+	fallback, err := analysis.NewFallback(domain.AIConfig{
+		Providers: []domain.AIProviderConfig{
+			{Type: domain.AI_PROVIDER_OPENAI, Pricing: domain.AIPricing{Prompt: 10, Completion: 30}},
+			{Type: domain.AI_PROVIDER_OLLAMA},
+		},
+		Budget: domain.CostBudgetConfig{Limit: 2, Pipelines: []domain.PipelineBudget{{Pipeline: "payments", Limit: 1}}},
+	}, clock, paid, free)
+	require.NoError(t, err)
+
+	analyze := func(pipeline string) domain.Analysis {
+		result, err := fallback.Analyze(context.Background(), domain.AnalysisRequest{Pipeline: pipeline})
+		require.NoError(t, err)
+		return result
+	}
+
+	first := analyze("payments")
+	assert.Equal(t, "openai", first.Provider)
+	assert.InDelta(t, 1.3, first.Cost, 1e-9)
+
+	assert.Equal(t, "ollama", analyze("payments").Provider, "the budget of the pipeline is spent")
+	assert.Equal(t, "openai", analyze("default").Provider)
+	assert.Equal(t, "ollama", analyze("default").Provider, "the total budget is spent")
+
+	now = at.Add(24 * time.Hour)
+	assert.Equal(t, "openai", analyze("payments").Provider, "the budget is reset every period")
+
+	usage := fallback.Usage()
+	require.Len(t, usage, 4)
+	assert.Equal(t, domain.AIUsage{Pipeline: "default", Provider: "ollama", Requests: 1, Tokens: domain.TokenUsage{Prompt: 1000, Completion: 100}}, usage[0])
+	assert.Equal(t, "openai", usage[1].Provider)
+	assert.Equal(t, "ollama", usage[2].Provider)
+	assert.Equal(t, "payments", usage[3].Pipeline)
+	assert.Equal(t, 2, usage[3].Requests)
+	assert.InDelta(t, 2.6, usage[3].Cost, 1e-9)
+	assert.Equal(t, uint64(2), fallback.Stats()["over_budget"])
+}
+
+func TestFallback_ShouldReportEachAttempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	paid := newProvider(ctrl, "openai")
+	local := newProvider(ctrl, "ollama")
+
+	local.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{}, fmt.Errorf("ollama: %w", domain.ErrProviderUnavailable))
+	clock := domain.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(at).AnyTimes()
+
+	fallback, err := analysis.NewFallback(domain.AIConfig{
+		Providers: []domain.AIProviderConfig{
+			{Type: domain.AI_PROVIDER_OPENAI, Pricing: domain.AIPricing{Prompt: 1}},
+			{Type: domain.AI_PROVIDER_OLLAMA},
+		},
+		Budget: domain.CostBudgetConfig{Pipelines: []domain.PipelineBudget{{Pipeline: "payments", Limit: 0.000001}}},
+	}, clock, paid, local)
+	require.NoError(t, err)
+
+	// spend the budget of the pipeline
+	paid.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{Usage: domain.TokenUsage{Prompt: 10}}, nil)
+	_, err = fallback.Analyze(context.Background(), domain.AnalysisRequest{Pipeline: "payments"})
+	require.NoError(t, err)
+
+	_, err = fallback.Analyze(context.Background(), domain.AnalysisRequest{Pipeline: "payments"})
+
+	assert.ErrorIs(t, err, domain.ErrBudgetExhausted)
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+	assert.EqualError(t, err, "openai: ai budget exhausted; ollama: ai provider unavailable")
+	assert.Equal(t, uint64(1), fallback.Stats()["failed"])
+}
+
+func TestNewFallback_ShouldRejectMissingAnalyzer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	_, err := analysis.NewFallback(domain.AIConfig{}, domain.NewMockClock(ctrl))
+	assert.ErrorIs(t, err, domain.ErrInvalidAIProvider)
+
+	_, err = analysis.NewFallback(domain.AIConfig{Providers: []domain.AIProviderConfig{
+		{Type: domain.AI_PROVIDER_OPENAI},
+		{Name: "local", Type: domain.AI_PROVIDER_OLLAMA},
+	}}, domain.NewMockClock(ctrl), newProvider(ctrl, "openai"), newProvider(ctrl, "ollama"))
+	assert.ErrorIs(t, err, domain.ErrInvalidAIProvider)
+}
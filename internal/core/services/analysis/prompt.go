@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var ErrInvalidTemplate = errors.New("invalid prompt template")
+
+// DefaultTemplate describes the incident, the event, its template, its enrichment and the events
+// surrounding it
+const DefaultTemplate = `{{with .Incident}}Incident {{.ID}} on {{.Service}}: {{.Title}} ({{.Severity}}, {{.EventCount}} events, {{.AlertCount}} alerts)
+
+{{end}}Event:
+{{event .Event}}
+{{- with .Template}}
+Seen {{.Count}} times as "{{.Pattern}}"{{if .New}}, for the first time{{end}}
+{{- end}}
+{{- with .Enrichment}}
+
+Enrichment:
+{{- range $key, $value := .}}
+{{$key}}: {{$value}}
+{{- end}}
+{{- end}}
+{{- with .Context}}
+
+Surrounding events:
+{{- range .}}
+{{event .}}
+{{- end}}
+{{- end}}
+`
+
+// PromptData is what the templates can use. Enrichment holds the metadata of the event, such as the
+// pod labels or the source snippets, Template the template of its message when it was mined
+type PromptData struct {
+	Pipeline   string
+	Event      domain.LogEvent
+	Context    []domain.LogEvent
+	Incident   *domain.Incident
+	Enrichment map[string]interface{}
+	Template   *TemplateStats
+}
+
+// TemplateStats describes the template of the message of the event
+type TemplateStats struct {
+	ID      string
+	Pattern string
+	Count   uint64
+	New     bool
+}
+
+// PromptBuilder renders the prompts and trims them to the token budget
+type PromptBuilder struct {
+	template  *template.Template
+	maxTokens int
+}
+
+var funcs = template.FuncMap{
+	"event":    formatEvent,
+	"json":     toJSON,
+	"truncate": truncate,
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+}
+
+// NewPromptBuilder compiles the template, the default one when empty. The prompts aren't trimmed
+// when maxTokens isn't positive
+func NewPromptBuilder(text string, maxTokens int) (*PromptBuilder, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("prompt").Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	return &PromptBuilder{template: tmpl, maxTokens: maxTokens}, nil
+}
+
+// Build renders the prompt, trimming the data with Budget.Fit when it's over the budget
+func (b *PromptBuilder) Build(data PromptData) (string, error) {
+	prompt, err := b.render(data)
+	if err != nil || b.maxTokens <= 0 || EstimateTokens(prompt) <= b.maxTokens {
+		return prompt, err
+	}
+
+	return Budget{MaxTokens: b.maxTokens}.Fit(data, b.render)
+}
+
+func (b *PromptBuilder) render(data PromptData) (string, error) {
+	var sb strings.Builder
+
+	if err := b.template.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	return sb.String(), nil
+}
+
+// formatEvent writes the event on one line with its time, severity and source
+func formatEvent(event domain.LogEvent) string {
+	return fmt.Sprintf("%s [%s] %s: %s", event.Timestamp.Format(time.RFC3339), event.Severity, event.Source, event.Message)
+}
+
+func toJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// truncate shortens the text to length runes
+func truncate(length int, text string) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	return string(runes[:length]) + "…"
+}
+
+// sortedKeys returns the keys of the enrichment, the largest values first
+func sortedKeys(enrichment map[string]interface{}) []string {
+	keys := make([]string, 0, len(enrichment))
+	sizes := make(map[string]int, len(enrichment))
+
+	for key, value := range enrichment {
+		keys = append(keys, key)
+		sizes[key] = len(fmt.Sprint(value))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] > sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
@@ -0,0 +1,110 @@
+package analysis_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/analysis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var at = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestPromptBuilder_Build(t *testing.T) {
+	data := analysis.PromptData{
+		Pipeline: "payments",
+		Event: domain.LogEvent{
+			Timestamp: at,
+			Source:    domain.SOURCE_FILE,
+			Severity:  domain.LOG_LEVEL_ERROR,
+			Message:   "payment 42 declined",
+		},
+		Context: []domain.LogEvent{
+			{Timestamp: at.Add(-time.Second), Source: domain.SOURCE_UNIX, Severity: domain.LOG_LEVEL_WARNING, Message: "gateway slow"},
+		},
+		Enrichment: map[string]interface{}{"pod": "api-1", "labels": map[string]string{"app": "api"}},
+		Template:   &analysis.TemplateStats{ID: "abc", Pattern: "payment <num> declined", Count: 12},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		data     analysis.PromptData
+		expected string
+	}{
+		{
+			name: "ShouldRenderDefaultTemplate",
+			data: data,
+			expected: "Event:\n" +
+				"2025-01-01T12:00:00Z [ERROR] file: payment 42 declined\n" +
+				"Seen 12 times as \"payment <num> declined\"\n" +
+				"\nEnrichment:\n" +
+				"labels: map[app:api]\n" +
+				"pod: api-1\n" +
+				"\nSurrounding events:\n" +
+				"2025-01-01T11:59:59Z [WARNING] unix: gateway slow\n",
+		},
+		{
+			name: "ShouldRenderIncident",
+			data: analysis.PromptData{
+				Event:    domain.LogEvent{Timestamp: at, Severity: domain.LOG_LEVEL_FATAL, Source: domain.SOURCE_STDIN, Message: "panic"},
+				Incident: &domain.Incident{ID: "inc-1", Service: "api", Title: "panic", Severity: domain.LOG_LEVEL_FATAL, EventCount: 2},
+			},
+			expected: "Incident inc-1 on api: panic (FATAL, 2 events, 0 alerts)\n\n" +
+				"Event:\n" +
+				"2025-01-01T12:00:00Z [FATAL] stdin: panic\n",
+		},
+		{
+			name:     "ShouldRenderCustomTemplate",
+			template: `{{.Pipeline | upper}} {{truncate 7 .Event.Message}} {{json .Enrichment.labels}} {{.Enrichment.missing}}`,
+			data:     data,
+			expected: `PAYMENTS payment… {"app":"api"} <no value>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := analysis.NewPromptBuilder(tt.template, 0)
+			require.NoError(t, err)
+
+			prompt, err := builder.Build(tt.data)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, prompt)
+		})
+	}
+}
+
+func TestPromptBuilder_ShouldTrimToBudget(t *testing.T) {
+	builder, err := analysis.NewPromptBuilder(`{{.Event.Message}}{{range .Context}}|{{.Message}}{{end}}`, 8)
+	require.NoError(t, err)
+
+	prompt, err := builder.Build(analysis.PromptData{
+		Event: domain.LogEvent{Timestamp: at, Message: "disk full"},
+		Context: []domain.LogEvent{
+			{Timestamp: at.Add(-time.Hour), Message: "backup started"},
+			{Timestamp: at.Add(-time.Second), Message: "write failed"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "disk full|write failed", prompt)
+}
+
+func TestNewPromptBuilder_InvalidTemplate(t *testing.T) {
+	builder, err := analysis.NewPromptBuilder("{{.Event", 0)
+
+	assert.ErrorIs(t, err, analysis.ErrInvalidTemplate)
+	assert.Nil(t, builder)
+}
+
+func TestPromptBuilder_ExecutionError(t *testing.T) {
+	builder, err := analysis.NewPromptBuilder("{{.Event.Unknown}}", 0)
+	require.NoError(t, err)
+
+	_, err = builder.Build(analysis.PromptData{})
+
+	assert.ErrorIs(t, err, analysis.ErrInvalidTemplate)
+}
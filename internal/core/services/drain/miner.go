@@ -0,0 +1,340 @@
+package drain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log-guardian/internal/core/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Wildcard replaces the tokens that vary between the messages of a template
+const Wildcard = "<*>"
+
+const (
+	defaultDepth       = 4
+	defaultSimilarity  = 0.4
+	defaultMaxChildren = 100
+	defaultMaxClusters = 1000
+	defaultWindow      = time.Minute
+)
+
+// Template is a group of similar messages
+type Template struct {
+	ID      string
+	Pattern string
+	// Count is the number of messages since the template was created
+	Count uint64
+	// WindowCount and PreviousCount are the number of messages in the current and previous window
+	WindowCount   uint64
+	PreviousCount uint64
+	FirstSeen     time.Time
+	LastSeen      time.Time
+}
+
+// Match is the template a message was assigned to
+type Match struct {
+	Template
+	// New is set when the message created the template
+	New bool
+}
+
+// Miner is an online implementation of Drain, a fixed depth parse tree clustering log messages.
+// See "Drain: An Online Log Parsing Approach with Fixed Depth Tree", He et al., ICWS 2017
+type Miner struct {
+	depth       int
+	similarity  float64
+	maxChildren int
+	maxClusters int
+	window      time.Duration
+
+	mu       sync.Mutex
+	root     *node
+	clusters map[string]*cluster
+}
+
+type node struct {
+	children map[string]*node
+	clusters []*cluster
+}
+
+type cluster struct {
+	Template
+	tokens      []string
+	leaf        *node
+	windowStart time.Time
+}
+
+func NewMiner(config domain.TemplateConfig) *Miner {
+	m := &Miner{
+		depth:       config.Depth,
+		similarity:  config.Similarity,
+		maxChildren: config.MaxChildren,
+		maxClusters: config.MaxClusters,
+		window:      config.Window,
+		root:        newNode(),
+		clusters:    make(map[string]*cluster),
+	}
+
+	if m.depth <= 0 {
+		m.depth = defaultDepth
+	}
+	if m.similarity <= 0 {
+		m.similarity = defaultSimilarity
+	}
+	if m.maxChildren <= 0 {
+		m.maxChildren = defaultMaxChildren
+	}
+	if m.maxClusters <= 0 {
+		m.maxClusters = defaultMaxClusters
+	}
+	if m.window <= 0 {
+		m.window = defaultWindow
+	}
+
+	return m
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+// Add assigns the message to the most similar template, creating a new one when none is close enough
+func (m *Miner) Add(message string, now time.Time) Match {
+	tokens := tokenize(message)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	leaf := m.leaf(tokens)
+
+	c := m.closest(leaf, tokens)
+	isNew := c == nil
+	if isNew {
+		c = m.create(leaf, tokens, now)
+	} else {
+		c.merge(tokens)
+	}
+
+	c.count(now, m.window)
+
+	return Match{Template: c.Template, New: isNew}
+}
+
+// Templates returns the known templates with their window counts as of now, the most frequent first
+func (m *Miner) Templates(now time.Time) []Template {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	templates := make([]Template, 0, len(m.clusters))
+	for _, c := range m.clusters {
+		c.roll(now, m.window)
+		templates = append(templates, c.Template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Count != templates[j].Count {
+			return templates[i].Count > templates[j].Count
+		}
+		return templates[i].ID < templates[j].ID
+	})
+
+	return templates
+}
+
+// Len returns the number of templates
+func (m *Miner) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.clusters)
+}
+
+// leaf walks the tree by the token count and the first tokens, creating the missing nodes. Tokens
+// holding digits go to the wildcard branch, as does everything once a node has too many children
+func (m *Miner) leaf(tokens []string) *node {
+	current := m.child(m.root, strconv.Itoa(len(tokens)), true)
+
+	for i := 0; i < m.depth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if isVariable(key) {
+			key = Wildcard
+		}
+
+		current = m.child(current, key, false)
+	}
+
+	return current
+}
+
+func (m *Miner) child(parent *node, key string, unbounded bool) *node {
+	if child, ok := parent.children[key]; ok {
+		return child
+	}
+
+	if !unbounded && len(parent.children) >= m.maxChildren {
+		key = Wildcard
+		if child, ok := parent.children[key]; ok {
+			return child
+		}
+	}
+
+	child := newNode()
+	parent.children[key] = child
+
+	return child
+}
+
+// closest returns the most similar cluster of the leaf, preferring the most general one on ties,
+// or nil when none reaches the similarity threshold
+func (m *Miner) closest(leaf *node, tokens []string) *cluster {
+	var (
+		best          *cluster
+		bestScore     = -1.0
+		bestWildcards = -1
+	)
+
+	for _, c := range leaf.clusters {
+		score, wildcards := similarity(c.tokens, tokens)
+		if score > bestScore || (score == bestScore && wildcards > bestWildcards) {
+			best, bestScore, bestWildcards = c, score, wildcards
+		}
+	}
+
+	if best == nil || bestScore < m.similarity {
+		return nil
+	}
+
+	return best
+}
+
+func (m *Miner) create(leaf *node, tokens []string, now time.Time) *cluster {
+	if len(m.clusters) >= m.maxClusters {
+		m.evict()
+	}
+
+	c := &cluster{
+		tokens:      append([]string(nil), tokens...),
+		leaf:        leaf,
+		windowStart: now,
+	}
+	c.Pattern = strings.Join(c.tokens, " ")
+	c.FirstSeen = now
+	c.ID = m.newID(c.Pattern)
+
+	leaf.clusters = append(leaf.clusters, c)
+	m.clusters[c.ID] = c
+
+	return c
+}
+
+// newID hashes the first pattern of the template so the same messages get the same ID across restarts
+func (m *Miner) newID(pattern string) string {
+	for i := 0; ; i++ {
+		source := pattern
+		if i > 0 {
+			source += "#" + strconv.Itoa(i)
+		}
+
+		sum := sha256.Sum256([]byte(source))
+		id := hex.EncodeToString(sum[:])[:16]
+
+		if _, exists := m.clusters[id]; !exists {
+			return id
+		}
+	}
+}
+
+// evict removes the least recently seen template
+func (m *Miner) evict() {
+	var oldest *cluster
+	for _, c := range m.clusters {
+		if oldest == nil || c.LastSeen.Before(oldest.LastSeen) {
+			oldest = c
+		}
+	}
+
+	if oldest == nil {
+		return
+	}
+
+	delete(m.clusters, oldest.ID)
+
+	siblings := oldest.leaf.clusters
+	for i, c := range siblings {
+		if c == oldest {
+			oldest.leaf.clusters = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+}
+
+// merge replaces the tokens differing from the message by wildcards
+func (c *cluster) merge(tokens []string) {
+	changed := false
+	for i, token := range tokens {
+		if c.tokens[i] != token && c.tokens[i] != Wildcard {
+			c.tokens[i] = Wildcard
+			changed = true
+		}
+	}
+
+	if changed {
+		c.Pattern = strings.Join(c.tokens, " ")
+	}
+}
+
+func (c *cluster) count(now time.Time, window time.Duration) {
+	c.roll(now, window)
+
+	c.Count++
+	c.WindowCount++
+	c.LastSeen = now
+}
+
+// roll starts the window holding now, so the counts of a template getting no messages don't go stale
+func (c *cluster) roll(now time.Time, window time.Duration) {
+	elapsed := now.Sub(c.windowStart)
+	if elapsed < window {
+		return
+	}
+
+	c.PreviousCount = c.WindowCount
+	if elapsed >= 2*window {
+		c.PreviousCount = 0
+	}
+	c.WindowCount = 0
+	c.windowStart = c.windowStart.Add(elapsed / window * window)
+}
+
+// similarity returns the share of identical tokens and the number of wildcards of the template
+func similarity(template, tokens []string) (float64, int) {
+	if len(tokens) == 0 {
+		return 1, 0
+	}
+
+	same, wildcards := 0, 0
+	for i, token := range template {
+		switch {
+		case token == Wildcard:
+			wildcards++
+		case token == tokens[i]:
+			same++
+		}
+	}
+
+	return float64(same) / float64(len(tokens)), wildcards
+}
+
+// tokenize masks the variable parts of the message before splitting it on spaces
+func tokenize(message string) []string {
+	return strings.Fields(domain.NormalizeMessage(message))
+}
+
+// isVariable reports whether the token holds a masked value, such as <num>ms, or digits
+func isVariable(token string) bool {
+	return strings.ContainsAny(token, "<0123456789")
+}
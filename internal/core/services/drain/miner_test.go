@@ -0,0 +1,131 @@
+package drain_test
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/drain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMiner_Add(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{})
+
+	first := miner.Add("connection to db-primary lost after 30s", start)
+	second := miner.Add("connection to db-replica lost after 12s", start)
+	other := miner.Add("user alice logged in", start)
+
+	assert.True(t, first.New)
+	assert.Equal(t, "connection to db-primary lost after <num>s", first.Pattern)
+
+	assert.False(t, second.New)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "connection to <*> lost after <num>s", second.Pattern)
+	assert.Equal(t, uint64(2), second.Count)
+
+	assert.True(t, other.New)
+	assert.NotEqual(t, first.ID, other.ID)
+	assert.Equal(t, 2, miner.Len())
+}
+
+func TestMiner_ShouldSeparateMessagesOfDifferentLength(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{})
+
+	a := miner.Add("request failed", start)
+	b := miner.Add("request failed again", start)
+
+	assert.NotEqual(t, a.ID, b.ID)
+}
+
+func TestMiner_ShouldRespectSimilarityThreshold(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{Similarity: 0.9})
+
+	a := miner.Add("cache miss for key users", start)
+	b := miner.Add("cache miss for key orders", start)
+
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.True(t, b.New)
+}
+
+func TestMiner_ShouldKeepIDsStableAcrossInstances(t *testing.T) {
+	a := drain.NewMiner(domain.TemplateConfig{}).Add("disk full on /var", start)
+	b := drain.NewMiner(domain.TemplateConfig{}).Add("disk full on /var", start)
+
+	assert.Equal(t, a.ID, b.ID)
+	assert.Len(t, a.ID, 16)
+}
+
+func TestMiner_ShouldBoundTheTreeWidth(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{MaxChildren: 2, Similarity: 0.5})
+
+	miner.Add("alpha starts worker", start)
+	miner.Add("beta starts worker", start)
+	gamma := miner.Add("gamma starts worker", start)
+	delta := miner.Add("delta starts worker", start)
+
+	assert.True(t, gamma.New)
+	assert.False(t, delta.New, "the overflowing first tokens share the wildcard branch")
+	assert.Equal(t, "<*> starts worker", delta.Pattern)
+}
+
+func TestMiner_ShouldEvictTheLeastRecentlySeenTemplate(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{MaxClusters: 2})
+
+	old := miner.Add("first kind of message", start)
+	miner.Add("second kind", start.Add(time.Second))
+	miner.Add("the third kind of message here", start.Add(2*time.Second))
+
+	require.Equal(t, 2, miner.Len())
+	for _, template := range miner.Templates(start.Add(2 * time.Second)) {
+		assert.NotEqual(t, old.ID, template.ID)
+	}
+}
+
+func TestMiner_WindowCounts(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{Window: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		miner.Add(fmt.Sprintf("job %d done", i), start)
+	}
+
+	match := miner.Add("job 4 done", start.Add(time.Minute))
+	assert.Equal(t, uint64(4), match.Count)
+	assert.Equal(t, uint64(1), match.WindowCount)
+	assert.Equal(t, uint64(3), match.PreviousCount)
+
+	match = miner.Add("job 5 done", start.Add(5*time.Minute))
+	assert.Equal(t, uint64(1), match.WindowCount)
+	assert.Equal(t, uint64(0), match.PreviousCount, "the previous window had no messages")
+	assert.Equal(t, start, match.FirstSeen)
+	assert.Equal(t, start.Add(5*time.Minute), match.LastSeen)
+
+	templates := miner.Templates(start.Add(6*time.Minute + 30*time.Second))
+	require.Len(t, templates, 1)
+	assert.Equal(t, uint64(0), templates[0].WindowCount, "the window rolls over without new messages")
+	assert.Equal(t, uint64(1), templates[0].PreviousCount)
+
+	templates = miner.Templates(start.Add(time.Hour))
+	assert.Equal(t, uint64(0), templates[0].PreviousCount)
+	assert.Equal(t, uint64(5), templates[0].Count)
+}
+
+func TestMiner_Templates(t *testing.T) {
+	miner := drain.NewMiner(domain.TemplateConfig{})
+
+	miner.Add("rare event", start)
+	for i := 0; i < 3; i++ {
+		miner.Add(fmt.Sprintf("frequent event number %d", i), start)
+	}
+
+	templates := miner.Templates(start)
+
+	require.Len(t, templates, 2)
+	assert.Equal(t, "frequent event number <num>", templates[0].Pattern)
+	assert.Equal(t, uint64(3), templates[0].Count)
+	assert.Equal(t, "rare event", templates[1].Pattern)
+}
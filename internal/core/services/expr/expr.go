@@ -0,0 +1,335 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// Expression is a compiled condition over a LogEvent, for example
+//
+//	severity >= ERROR && metadata.namespace == "prod" && message matches "timeout"
+//
+// Fields are id, timestamp, source, severity, message, metadata.<key> and metadata["<key>"].
+// Severities compare by rank, numbers numerically and the other values as strings. Missing
+// fields are null and only equal to null. The functions are lower, upper, trim, len, contains,
+// starts_with, ends_with and has
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses the source, returning an ErrInvalidExpression error with the position of the
+// problem. The expression is safe for concurrent use
+func Compile(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, syntaxError(1, "empty expression")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, syntaxError(t.pos, "unexpected %s", t)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// Eval returns the value of the expression for the event
+func (e *Expression) Eval(event domain.LogEvent) interface{} {
+	return e.root.eval(event)
+}
+
+// Match evaluates the expression as a condition, null, false, zero and empty strings are false
+func (e *Expression) Match(event domain.LogEvent) bool {
+	return truthy(e.Eval(event))
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+type node interface {
+	eval(event domain.LogEvent) interface{}
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(domain.LogEvent) interface{} {
+	return n.value
+}
+
+type fieldNode struct {
+	path string
+}
+
+func (n fieldNode) eval(event domain.LogEvent) interface{} {
+	if n.path == domain.FIELD_SEVERITY {
+		return event.Severity
+	}
+
+	value, _ := event.Field(n.path)
+	return value
+}
+
+type hasNode struct {
+	path string
+}
+
+func (n hasNode) eval(event domain.LogEvent) interface{} {
+	_, ok := event.Field(n.path)
+	return ok
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n notNode) eval(event domain.LogEvent) interface{} {
+	return !truthy(n.operand.eval(event))
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(event domain.LogEvent) interface{} {
+	return truthy(n.left.eval(event)) && truthy(n.right.eval(event))
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(event domain.LogEvent) interface{} {
+	return truthy(n.left.eval(event)) || truthy(n.right.eval(event))
+}
+
+type matchNode struct {
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n matchNode) eval(event domain.LogEvent) interface{} {
+	value := n.operand.eval(event)
+	return value != nil && n.re.MatchString(toString(value))
+}
+
+type callNode struct {
+	fn   func(args []interface{}) interface{}
+	args []node
+}
+
+func (n callNode) eval(event domain.LogEvent) interface{} {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(event)
+	}
+
+	return n.fn(args)
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(event domain.LogEvent) interface{} {
+	return compare(n.op, n.left.eval(event), n.right.eval(event))
+}
+
+type function struct {
+	arity int
+	call  func(args []interface{}) interface{}
+}
+
+var functions = map[string]function{
+	"lower": {arity: 1, call: func(args []interface{}) interface{} { return strings.ToLower(toString(args[0])) }},
+	"upper": {arity: 1, call: func(args []interface{}) interface{} { return strings.ToUpper(toString(args[0])) }},
+	"trim":  {arity: 1, call: func(args []interface{}) interface{} { return strings.TrimSpace(toString(args[0])) }},
+	"len":   {arity: 1, call: func(args []interface{}) interface{} { return float64(len(toString(args[0]))) }},
+	"contains": {arity: 2, call: func(args []interface{}) interface{} {
+		return strings.Contains(toString(args[0]), toString(args[1]))
+	}},
+	"starts_with": {arity: 2, call: func(args []interface{}) interface{} {
+		return strings.HasPrefix(toString(args[0]), toString(args[1]))
+	}},
+	"ends_with": {arity: 2, call: func(args []interface{}) interface{} {
+		return strings.HasSuffix(toString(args[0]), toString(args[1]))
+	}},
+}
+
+// compare applies the operator, values that can't be compared are only different
+func compare(op string, left, right interface{}) bool {
+	if left == nil || right == nil {
+		return compareResult(op, left == nil && right == nil, 0)
+	}
+
+	_, leftIsLevel := left.(domain.LogLevel)
+	_, rightIsLevel := right.(domain.LogLevel)
+	if leftIsLevel || rightIsLevel {
+		l, lok := toLevel(left)
+		r, rok := toLevel(right)
+		if !lok || !rok {
+			return op == "!="
+		}
+		return compareResult(op, l == r, l.Rank()-r.Rank())
+	}
+
+	if isNumber(left) || isNumber(right) {
+		l, lok := toNumber(left)
+		r, rok := toNumber(right)
+		if !lok || !rok {
+			return op == "!="
+		}
+		return compareResult(op, l == r, sign(l-r))
+	}
+
+	if l, ok := left.(bool); ok {
+		r, ok := right.(bool)
+		if !ok || (op != "==" && op != "!=") {
+			return op == "!="
+		}
+		return compareResult(op, l == r, 0)
+	}
+
+	l, r := toString(left), toString(right)
+	return compareResult(op, l == r, strings.Compare(l, r))
+}
+
+// compareResult turns the equality and the order of two values into the result of the operator
+func compareResult(op string, equal bool, order int) bool {
+	switch op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	case "<":
+		return !equal && order < 0
+	case "<=":
+		return equal || order < 0
+	case ">":
+		return !equal && order > 0
+	case ">=":
+		return equal || order > 0
+	}
+
+	return false
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+
+	if isNumber(value) {
+		n, _ := toNumber(value)
+		return n != 0
+	}
+
+	return true
+}
+
+func toLevel(value interface{}) (domain.LogLevel, bool) {
+	if level, ok := value.(domain.LogLevel); ok {
+		return level, level.IsValid()
+	}
+
+	if text, ok := value.(string); ok {
+		return domain.LogLevelOf(text)
+	}
+
+	return "", false
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+
+	return false
+}
+
+// toNumber converts numbers and numeric strings, metadata captured as text still compares numerically
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case domain.LogLevel:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func sign(n float64) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
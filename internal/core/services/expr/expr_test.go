@@ -0,0 +1,107 @@
+package expr_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/expr"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpression_Match(t *testing.T) {
+	event := domain.LogEvent{
+		Source:   domain.SOURCE_FILE,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Message:  "upstream timeout after 30s",
+		Metadata: map[string]interface{}{
+			"namespace":     "prod",
+			"status":        503,
+			"latency":       "1.5",
+			"retry":         false,
+			"k8s.container": "api",
+		},
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: `severity >= ERROR && metadata.namespace == "prod" && message matches "timeout"`, expected: true},
+		{expression: `severity >= FATAL`, expected: false},
+		{expression: `severity < WARNING`, expected: false},
+		{expression: `severity == "error"`, expected: true},
+		{expression: `severity != INFO`, expected: true},
+		{expression: `metadata.status >= 500 && metadata.status < 600`, expected: true},
+		{expression: `metadata.latency > 1`, expected: true},
+		{expression: `metadata.retry == false`, expected: true},
+		{expression: `metadata.k8s.container == "api"`, expected: true},
+		{expression: `metadata["k8s.container"] == "api"`, expected: true},
+		{expression: `metadata.missing == "x"`, expected: false},
+		{expression: `metadata.missing != "x"`, expected: true},
+		{expression: `metadata.missing == null`, expected: true},
+		{expression: `has(metadata.namespace) && !has(metadata.missing)`, expected: true},
+		{expression: `source == "file" || source == "unix"`, expected: true},
+		{expression: `!(source == "file")`, expected: false},
+		{expression: `message matches "^upstream" && !message matches "refused"`, expected: true},
+		{expression: "message matches `\\d+s$`", expected: true},
+		{expression: `contains(lower(message), "TIMEOUT")`, expected: false},
+		{expression: `contains(upper(message), "TIMEOUT")`, expected: true},
+		{expression: `starts_with(message, "upstream") && ends_with(message, "30s")`, expected: true},
+		{expression: `len(trim(metadata.namespace)) == 4`, expected: true},
+		{expression: `metadata.namespace`, expected: true},
+		{expression: `metadata.missing`, expected: false},
+		{expression: `true && (false || metadata.status == 503)`, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expression, err := expr.Compile(tt.expression)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, expression.Match(event))
+		})
+	}
+}
+
+func TestExpression_Eval(t *testing.T) {
+	expression, err := expr.Compile(`upper(metadata.env)`)
+	require.NoError(t, err)
+
+	value := expression.Eval(domain.LogEvent{Metadata: map[string]interface{}{"env": "prod"}})
+
+	assert.Equal(t, "PROD", value)
+	assert.Equal(t, `upper(metadata.env)`, expression.String())
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		expression string
+		message    string
+	}{
+		{expression: ``, message: "empty expression at position 1"},
+		{expression: `severity >= `, message: "unexpected end of expression at position 13"},
+		{expression: `severity = ERROR`, message: `unexpected "=", did you mean "==" at position 10`},
+		{expression: `severity >= ERROR & true`, message: `unexpected "&", did you mean "&&" at position 19`},
+		{expression: `level == "x"`, message: `unknown identifier "level" at position 1`},
+		{expression: `message == "open`, message: "unterminated string at position 12"},
+		{expression: `message matches "("`, message: "invalid regexp"},
+		{expression: `message matches source`, message: `matches expects a string, found "source" at position 17`},
+		{expression: `(true`, message: `expected ")", found end of expression at position 6`},
+		{expression: `true false`, message: `unexpected "false" at position 6`},
+		{expression: `shout(message)`, message: `unknown function "shout" at position 1`},
+		{expression: `contains(message)`, message: "contains expects 2 arguments, found 1 at position 1"},
+		{expression: `has("x")`, message: "has expects a field at position 1"},
+		{expression: `metadata[1] == 2`, message: `metadata key must be a string, found "1" at position 10`},
+		{expression: `message == #`, message: `unexpected character "#" at position 12`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := expr.Compile(tt.expression)
+
+			require.ErrorIs(t, err, expr.ErrInvalidExpression)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
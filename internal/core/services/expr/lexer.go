@@ -0,0 +1,133 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+
+	return strconv.Quote(t.text)
+}
+
+var punctuations = []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "(", ")", "[", "]", ","}
+
+// lex splits the source into tokens, positions are 1-based
+func lex(source string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(source); {
+		c := source[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(source) && isIdentPart(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start + 1})
+		case isDigit(c) || (c == '-' && i+1 < len(source) && isDigit(source[i+1])):
+			start := i
+			i++
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(source[start:i], 64)
+			if err != nil {
+				return nil, syntaxError(start+1, "invalid number %q", source[start:i])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], value: number, pos: start + 1})
+		case c == '"' || c == '`':
+			end, err := stringEnd(source, i)
+			if err != nil {
+				return nil, err
+			}
+			text, err := strconv.Unquote(source[i:end])
+			if err != nil {
+				return nil, syntaxError(i+1, "invalid string %s", source[i:end])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i:end], value: text, pos: i + 1})
+			i = end
+		default:
+			punct := matchPunct(source[i:])
+			if punct == "" {
+				if c == '=' || c == '&' || c == '|' {
+					return nil, syntaxError(i+1, "unexpected %q, did you mean %q", string(c), strings.Repeat(string(c), 2))
+				}
+				return nil, syntaxError(i+1, "unexpected character %q", string(c))
+			}
+			tokens = append(tokens, token{kind: tokenPunct, text: punct, pos: i + 1})
+			i += len(punct)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(source) + 1}), nil
+}
+
+// stringEnd returns the index after the closing quote of the string starting at start
+func stringEnd(source string, start int) (int, error) {
+	quote := source[start]
+
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i + 1, nil
+		}
+	}
+
+	return 0, syntaxError(start+1, "unterminated string")
+}
+
+func matchPunct(text string) string {
+	for _, punct := range punctuations {
+		if strings.HasPrefix(text, punct) {
+			return punct
+		}
+	}
+
+	return ""
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentPart allows dots so that metadata paths such as metadata.k8s.namespace are a single token
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '.'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func syntaxError(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidExpression, fmt.Sprintf(format, args...), pos)
+}
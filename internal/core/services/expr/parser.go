@@ -0,0 +1,233 @@
+package expr
+
+import (
+	"log-guardian/internal/core/domain"
+	"regexp"
+	"strings"
+)
+
+// parser is a recursive descent parser for the grammar
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | comparison
+//	comparison = primary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) primary | "matches" string ]
+//	primary    = literal | field | call | "(" or ")"
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(punct string) bool {
+	if t := p.peek(); t.kind == tokenPunct && t.text == punct {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.accept(punct) {
+		t := p.peek()
+		return syntaxError(t.pos, "expected %q, found %s", punct, t)
+	}
+
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokenPunct && isComparison(t.text):
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: t.text, left: left, right: right}, nil
+	case t.kind == tokenIdent && t.text == "matches":
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, syntaxError(pattern.pos, "matches expects a string, found %s", pattern)
+		}
+		re, err := regexp.Compile(pattern.value.(string))
+		if err != nil {
+			return nil, syntaxError(pattern.pos, "invalid regexp: %v", err)
+		}
+		return matchNode{operand: left, re: re}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return literalNode{value: t.value}, nil
+	case tokenIdent:
+		return p.parseIdent(t)
+	case tokenPunct:
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		}
+	}
+
+	return nil, syntaxError(t.pos, "unexpected %s", t)
+}
+
+func (p *parser) parseIdent(t token) (node, error) {
+	switch t.text {
+	case "true":
+		return literalNode{value: true}, nil
+	case "false":
+		return literalNode{value: false}, nil
+	case "null":
+		return literalNode{value: nil}, nil
+	case "metadata":
+		if p.accept("[") {
+			key := p.next()
+			if key.kind != tokenString {
+				return nil, syntaxError(key.pos, "metadata key must be a string, found %s", key)
+			}
+			return fieldNode{path: domain.FIELD_METADATA + key.value.(string)}, p.expect("]")
+		}
+	}
+
+	if p.accept("(") {
+		return p.parseCall(t)
+	}
+
+	if domain.IsEventField(t.text) {
+		return fieldNode{path: t.text}, nil
+	}
+
+	if level, ok := domain.LogLevelOf(t.text); ok && t.text == strings.ToUpper(t.text) {
+		return literalNode{value: level}, nil
+	}
+
+	return nil, syntaxError(t.pos, "unknown identifier %q", t.text)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	var args []node
+
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if name.text == "has" {
+		if len(args) != 1 {
+			return nil, syntaxError(name.pos, "has expects 1 argument, found %d", len(args))
+		}
+		field, ok := args[0].(fieldNode)
+		if !ok {
+			return nil, syntaxError(name.pos, "has expects a field")
+		}
+		return hasNode{path: field.path}, nil
+	}
+
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, syntaxError(name.pos, "unknown function %q", name.text)
+	}
+
+	if len(args) != fn.arity {
+		return nil, syntaxError(name.pos, "%s expects %d arguments, found %d", name.text, fn.arity, len(args))
+	}
+
+	return callNode{fn: fn.call, args: args}, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+
+	return false
+}
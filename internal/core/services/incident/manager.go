@@ -0,0 +1,441 @@
+package incident
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMinSeverity  = domain.LOG_LEVEL_ERROR
+	defaultServiceField = "metadata.service"
+	defaultProximity    = 5 * time.Minute
+	defaultQuietPeriod  = 15 * time.Minute
+	defaultReopenWindow = time.Hour
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultMaxTimeline  = 100
+	// maxSamples bounds the member events kept to describe the incident
+	maxSamples = 5
+)
+
+// Manager groups the events and alerts into incidents and drives their lifecycle
+type Manager struct {
+	minSeverity  domain.LogLevel
+	serviceField string
+	proximity    time.Duration
+	quietPeriod  time.Duration
+	reopenWindow time.Duration
+	retention    time.Duration
+	maxTimeline  int
+	clock        domain.Clock
+	idGen        domain.IDGenerator
+	store        ports.IncidentStore
+
+	mu        sync.Mutex
+	incidents []*domain.Incident
+	changed   map[string]*domain.Incident
+	dirty     bool
+
+	opened       atomic.Uint64
+	reopened     atomic.Uint64
+	resolved     atomic.Uint64
+	events       atomic.Uint64
+	alerts       atomic.Uint64
+	saveErrors   atomic.Uint64
+	reloadErrors atomic.Uint64
+}
+
+// NewManager restores the incidents saved by the store
+func NewManager(
+	config domain.IncidentConfig,
+	clock domain.Clock,
+	idGen domain.IDGenerator,
+	store ports.IncidentStore,
+) (*Manager, error) {
+	m := &Manager{
+		minSeverity:  config.MinSeverity,
+		serviceField: config.ServiceField,
+		proximity:    config.Proximity,
+		quietPeriod:  config.QuietPeriod,
+		reopenWindow: config.ReopenWindow,
+		retention:    config.Retention,
+		maxTimeline:  config.MaxTimeline,
+		clock:        clock,
+		idGen:        idGen,
+		store:        store,
+		changed:      make(map[string]*domain.Incident),
+	}
+
+	if m.minSeverity == "" {
+		m.minSeverity = defaultMinSeverity
+	}
+	if m.serviceField == "" {
+		m.serviceField = defaultServiceField
+	}
+	if m.proximity == 0 {
+		m.proximity = defaultProximity
+	}
+	if m.quietPeriod == 0 {
+		m.quietPeriod = defaultQuietPeriod
+	}
+	if m.reopenWindow == 0 {
+		m.reopenWindow = defaultReopenWindow
+	}
+	if m.retention == 0 {
+		m.retention = defaultRetention
+	}
+	if m.maxTimeline == 0 {
+		m.maxTimeline = defaultMaxTimeline
+	}
+
+	saved, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading incidents: %w", err)
+	}
+
+	for i := range saved {
+		m.incidents = append(m.incidents, &saved[i])
+	}
+
+	return m, nil
+}
+
+// ObserveEvent adds the event to an incident when it is severe enough
+func (m *Manager) ObserveEvent(event domain.LogEvent) {
+	if event.Severity.Rank() < m.minSeverity.Rank() {
+		return
+	}
+
+	m.events.Add(1)
+
+	// the template mined by the templates stage groups the messages better than their fingerprint
+	fingerprint := event.Fingerprint()
+	if templateID, ok := event.GetMetadata(domain.METADATA_TEMPLATE_ID); ok {
+		fingerprint = fmt.Sprint(templateID)
+	}
+
+	now := m.clock.Now()
+	entry := domain.TimelineEntry{
+		At:          now,
+		Kind:        domain.TIMELINE_EVENT,
+		Severity:    event.Severity,
+		Fingerprint: fingerprint,
+		Message:     event.Message,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	routed, _ := event.Metadata[domain.METADATA_PIPELINE].(string)
+	m.join(m.service(event), []string{fingerprint}, []string{routed}, []domain.LogEvent{event}, entry, event.Message, true)
+}
+
+// ObserveAlert adds the alert to the incident of its events. A resolved alert is recorded in the
+// timeline but never opens an incident
+func (m *Manager) ObserveAlert(alert domain.Alert) {
+	m.alerts.Add(1)
+
+	service := alert.Labels[m.serviceField]
+	fingerprints := []string{alert.Fingerprint}
+	for _, sample := range alert.Samples {
+		if service == "" {
+			service = m.service(sample)
+		}
+		fingerprints = append(fingerprints, sample.Fingerprint())
+		if templateID, ok := sample.GetMetadata(domain.METADATA_TEMPLATE_ID); ok {
+			fingerprints = append(fingerprints, fmt.Sprint(templateID))
+		}
+	}
+
+	now := m.clock.Now()
+	entry := domain.TimelineEntry{
+		At:          now,
+		Kind:        domain.TIMELINE_ALERT,
+		Severity:    alert.Severity,
+		Fingerprint: alert.Fingerprint,
+		Message:     fmt.Sprintf("[%s] %s", alert.State, alert.Summary),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.join(service, fingerprints, alert.Pipelines(), alert.Samples, entry, alert.Summary, alert.State == domain.ALERT_STATE_FIRING)
+}
+
+// join adds the entry to the matching active incident, reopens a recently resolved one or opens
+// a new incident
+func (m *Manager) join(
+	service string,
+	fingerprints, pipelines []string,
+	samples []domain.LogEvent,
+	entry domain.TimelineEntry,
+	title string,
+	create bool,
+) {
+	now := entry.At
+
+	incident := m.find(func(i *domain.Incident) bool {
+		return i.IsActive() && i.Service == service && hasAny(i, fingerprints)
+	})
+
+	if incident == nil {
+		incident = m.find(func(i *domain.Incident) bool {
+			return i.IsActive() && i.Service == service && now.Sub(i.UpdatedAt) <= m.proximity
+		})
+	}
+
+	if incident == nil && create {
+		incident = m.find(func(i *domain.Incident) bool {
+			return !i.IsActive() && i.Service == service && hasAny(i, fingerprints) &&
+				now.Sub(i.ResolvedAt) <= m.reopenWindow
+		})
+
+		if incident != nil {
+			_ = incident.Transition(domain.INCIDENT_STATUS_REOPENED, now)
+			m.reopened.Add(1)
+			m.changed[incident.ID] = incident
+		}
+	}
+
+	if incident == nil {
+		if !create {
+			return
+		}
+
+		incident = m.open(service, title, now)
+		if incident == nil {
+			return
+		}
+	}
+
+	incident.AddMember(entry, m.maxTimeline)
+	incident.AddPipelines(pipelines...)
+	incident.AddSamples(maxSamples, samples...)
+	m.dirty = true
+}
+
+func (m *Manager) open(service, title string, now time.Time) *domain.Incident {
+	id, err := m.idGen.Generate()
+	if err != nil {
+		return nil
+	}
+
+	incident := &domain.Incident{
+		ID:        id,
+		Service:   service,
+		Title:     title,
+		Status:    domain.INCIDENT_STATUS_OPEN,
+		OpenedAt:  now,
+		UpdatedAt: now,
+		Timeline: []domain.TimelineEntry{{
+			At:      now,
+			Kind:    domain.TIMELINE_STATUS,
+			Message: string(domain.INCIDENT_STATUS_OPEN),
+		}},
+	}
+
+	m.incidents = append(m.incidents, incident)
+	m.changed[incident.ID] = incident
+	m.opened.Add(1)
+
+	return incident
+}
+
+// Acknowledge marks the incident as handled by someone, it still resolves after the quiet period
+func (m *Manager) Acknowledge(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.acknowledge(id, m.clock.Now())
+}
+
+func (m *Manager) acknowledge(id string, now time.Time) error {
+	incident := m.find(func(i *domain.Incident) bool { return i.ID == id })
+	if incident == nil {
+		return fmt.Errorf("%w: %s", domain.ErrIncidentNotFound, id)
+	}
+
+	if err := incident.Transition(domain.INCIDENT_STATUS_ACKNOWLEDGED, now); err != nil {
+		return err
+	}
+
+	m.changed[incident.ID] = incident
+	m.dirty = true
+
+	return nil
+}
+
+// Tick applies the acknowledgements saved by another process, resolves the incidents quiet for the
+// quiet period, forgets the resolved incidents older than the retention and saves the state when
+// it changed
+func (m *Manager) Tick(now time.Time) []domain.Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reload(now)
+
+	kept := m.incidents[:0]
+	for _, incident := range m.incidents {
+		if incident.IsActive() && now.Sub(incident.UpdatedAt) >= m.quietPeriod {
+			_ = incident.Transition(domain.INCIDENT_STATUS_RESOLVED, now)
+			m.resolved.Add(1)
+			m.changed[incident.ID] = incident
+			m.dirty = true
+		}
+
+		if !incident.IsActive() && now.Sub(incident.ResolvedAt) > m.retention {
+			m.dirty = true
+			continue
+		}
+
+		kept = append(kept, incident)
+	}
+	m.incidents = kept
+
+	if m.dirty {
+		m.save()
+	}
+
+	changed := make([]domain.Incident, 0, len(m.changed))
+	for _, incident := range m.changed {
+		changed = append(changed, copyIncident(incident))
+	}
+	sortIncidents(changed)
+	clear(m.changed)
+
+	return changed
+}
+
+// Close saves the state
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.store.Save(m.snapshot())
+}
+
+// Incidents returns a copy of the known incidents, the oldest first
+func (m *Manager) Incidents() []domain.Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.snapshot()
+}
+
+func (m *Manager) Stats() map[string]uint64 {
+	m.mu.Lock()
+	active := 0
+	for _, incident := range m.incidents {
+		if incident.IsActive() {
+			active++
+		}
+	}
+	m.mu.Unlock()
+
+	return map[string]uint64{
+		"events":        m.events.Load(),
+		"alerts":        m.alerts.Load(),
+		"opened":        m.opened.Load(),
+		"reopened":      m.reopened.Load(),
+		"resolved":      m.resolved.Load(),
+		"active":        uint64(active),
+		"save_errors":   m.saveErrors.Load(),
+		"reload_errors": m.reloadErrors.Load(),
+	}
+}
+
+// reload acknowledges the incidents acknowledged in the store by another process, so ack-incident
+// applies to a running process. On failure the next tick retries
+func (m *Manager) reload(now time.Time) {
+	changed, err := m.store.Changed()
+	if err != nil {
+		m.reloadErrors.Add(1)
+		return
+	}
+
+	if !changed {
+		return
+	}
+
+	saved, err := m.store.Load()
+	if err != nil {
+		m.reloadErrors.Add(1)
+		return
+	}
+
+	for _, incident := range saved {
+		if incident.Status == domain.INCIDENT_STATUS_ACKNOWLEDGED {
+			// the incident may have been acknowledged or resolved here in the meantime
+			_ = m.acknowledge(incident.ID, now)
+		}
+	}
+}
+
+// save persists the state, on failure the state stays dirty so the next tick retries
+func (m *Manager) save() {
+	if err := m.store.Save(m.snapshot()); err != nil {
+		m.saveErrors.Add(1)
+		return
+	}
+
+	m.dirty = false
+}
+
+func (m *Manager) snapshot() []domain.Incident {
+	incidents := make([]domain.Incident, 0, len(m.incidents))
+	for _, incident := range m.incidents {
+		incidents = append(incidents, copyIncident(incident))
+	}
+	sortIncidents(incidents)
+
+	return incidents
+}
+
+func (m *Manager) find(match func(*domain.Incident) bool) *domain.Incident {
+	// the most recent incidents are the most likely to match
+	for i := len(m.incidents) - 1; i >= 0; i-- {
+		if match(m.incidents[i]) {
+			return m.incidents[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) service(event domain.LogEvent) string {
+	if value, ok := event.Field(m.serviceField); ok && value != "" {
+		return fmt.Sprint(value)
+	}
+
+	return event.Source
+}
+
+func hasAny(incident *domain.Incident, fingerprints []string) bool {
+	for _, fingerprint := range fingerprints {
+		if incident.HasFingerprint(fingerprint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyIncident(incident *domain.Incident) domain.Incident {
+	c := *incident
+	c.Fingerprints = append([]string(nil), incident.Fingerprints...)
+	c.Pipelines = append([]string(nil), incident.Pipelines...)
+	c.Samples = append([]domain.LogEvent(nil), incident.Samples...)
+	c.Timeline = append([]domain.TimelineEntry(nil), incident.Timeline...)
+
+	return c
+}
+
+func sortIncidents(incidents []domain.Incident) {
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].OpenedAt.Before(incidents[j].OpenedAt)
+	})
+}
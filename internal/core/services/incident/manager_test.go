@@ -0,0 +1,330 @@
+package incident_test
+
+import (
+	"errors"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/incident"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// sequenceGenerator returns increasing IDs
+type sequenceGenerator struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (g *sequenceGenerator) Generate() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.n++
+	return fmt.Sprintf("incident-%d", g.n), nil
+}
+
+// memoryStore keeps the saved incidents
+type memoryStore struct {
+	incidents []domain.Incident
+	saves     int
+	changed   bool
+}
+
+func (s *memoryStore) Load() ([]domain.Incident, error) {
+	return s.incidents, nil
+}
+
+func (s *memoryStore) Save(incidents []domain.Incident) error {
+	s.incidents = incidents
+	s.saves++
+	return nil
+}
+
+func (s *memoryStore) Changed() (bool, error) {
+	changed := s.changed
+	s.changed = false
+
+	return changed, nil
+}
+
+func newManager(t *testing.T, config domain.IncidentConfig, clock domain.Clock, store ports.IncidentStore) *incident.Manager {
+	manager, err := incident.NewManager(config, clock, &sequenceGenerator{}, store)
+	require.NoError(t, err)
+
+	return manager
+}
+
+func errorEvent(service, message string) domain.LogEvent {
+	return domain.LogEvent{
+		Source:   domain.SOURCE_FILE,
+		Severity: domain.LOG_LEVEL_ERROR,
+		Message:  message,
+		Metadata: map[string]interface{}{"service": service},
+	}
+}
+
+func TestManager_GroupsByServiceAndFingerprint(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{Proximity: time.Minute}, clock, &memoryStore{})
+
+	manager.ObserveEvent(errorEvent("payments", "charge 1 failed"))
+	manager.ObserveEvent(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Message: "ignored"})
+
+	now = now.Add(10 * time.Minute)
+	manager.ObserveEvent(errorEvent("payments", "charge 2 failed"))
+	manager.ObserveEvent(errorEvent("search", "index corrupted"))
+
+	// a different error of the same service within the proximity
+	now = now.Add(30 * time.Second)
+	fatal := errorEvent("payments", "database unreachable")
+	fatal.Severity = domain.LOG_LEVEL_FATAL
+	manager.ObserveEvent(fatal)
+
+	incidents := manager.Incidents()
+
+	require.Len(t, incidents, 2)
+	assert.Equal(t, "payments", incidents[0].Service)
+	assert.Equal(t, "charge 1 failed", incidents[0].Title)
+	assert.Equal(t, 3, incidents[0].EventCount)
+	assert.Equal(t, domain.LOG_LEVEL_FATAL, incidents[0].Severity)
+	assert.Len(t, incidents[0].Fingerprints, 2)
+	assert.Equal(t, "search", incidents[1].Service)
+}
+
+func TestManager_JoinsAlertsToTheIncidentOfTheirEvents(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{Proximity: time.Second}, clock, &memoryStore{})
+
+	event := errorEvent("payments", "charge failed")
+	manager.ObserveEvent(event)
+
+	now = now.Add(time.Minute)
+	manager.ObserveAlert(domain.Alert{
+		Fingerprint: "alert-1",
+		Rule:        "payments-errors",
+		State:       domain.ALERT_STATE_FIRING,
+		Severity:    domain.LOG_LEVEL_FATAL,
+		Summary:     "too many errors",
+		Samples:     []domain.LogEvent{event},
+	})
+
+	incidents := manager.Incidents()
+
+	require.Len(t, incidents, 1)
+	assert.Equal(t, 1, incidents[0].AlertCount)
+	assert.Equal(t, domain.LOG_LEVEL_FATAL, incidents[0].Severity)
+	assert.Equal(t, "[firing] too many errors", incidents[0].Timeline[len(incidents[0].Timeline)-1].Message)
+}
+
+func TestManager_RecordsThePipelinesOfTheMembers(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{}, clock, &memoryStore{})
+
+	event := errorEvent("payments", "charge failed")
+	event.Metadata[domain.METADATA_PIPELINE] = "payments"
+	manager.ObserveEvent(event)
+
+	sample := errorEvent("payments", "charge failed")
+	sample.Metadata[domain.METADATA_PIPELINE] = "audit"
+	manager.ObserveAlert(domain.Alert{Fingerprint: "alert-1", State: domain.ALERT_STATE_FIRING, Samples: []domain.LogEvent{sample, event}})
+
+	incidents := manager.Incidents()
+
+	require.Len(t, incidents, 1)
+	assert.Equal(t, []string{"payments", "audit"}, incidents[0].Pipelines)
+	assert.Equal(t, []domain.LogEvent{event, sample, event}, incidents[0].Samples)
+}
+
+func TestManager_KeepsTheLatestSamples(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{}, clock, &memoryStore{})
+
+	for i := range 8 {
+		manager.ObserveEvent(errorEvent("payments", fmt.Sprintf("charge %d failed", i)))
+	}
+
+	incidents := manager.Incidents()
+
+	require.Len(t, incidents, 1)
+	require.Len(t, incidents[0].Samples, 5)
+	assert.Equal(t, "charge 3 failed", incidents[0].Samples[0].Message)
+	assert.Equal(t, "charge 7 failed", incidents[0].Samples[4].Message)
+}
+
+func TestManager_ResolvedAlertsNeverOpenIncidents(t *testing.T) {
+	now := time.Now()
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{}, clock, &memoryStore{})
+
+	manager.ObserveAlert(domain.Alert{Fingerprint: "a", State: domain.ALERT_STATE_RESOLVED})
+
+	assert.Empty(t, manager.Incidents())
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	manager := newManager(t, domain.IncidentConfig{
+		QuietPeriod:  10 * time.Minute,
+		ReopenWindow: time.Hour,
+		Retention:    24 * time.Hour,
+	}, clock, &memoryStore{})
+
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+
+	changed := manager.Tick(now)
+	require.Len(t, changed, 1)
+	assert.Equal(t, domain.INCIDENT_STATUS_OPEN, changed[0].Status)
+	id := changed[0].ID
+
+	require.NoError(t, manager.Acknowledge(id))
+	assert.ErrorIs(t, manager.Acknowledge(id), domain.ErrInvalidTransition)
+	assert.ErrorIs(t, manager.Acknowledge("missing"), domain.ErrIncidentNotFound)
+
+	changed = manager.Tick(now)
+	require.Len(t, changed, 1)
+	assert.Equal(t, domain.INCIDENT_STATUS_ACKNOWLEDGED, changed[0].Status)
+
+	now = now.Add(10 * time.Minute)
+	changed = manager.Tick(now)
+	require.Len(t, changed, 1)
+	assert.Equal(t, domain.INCIDENT_STATUS_RESOLVED, changed[0].Status)
+	assert.Empty(t, manager.Tick(now), "the changes are only returned once")
+
+	now = now.Add(30 * time.Minute)
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+
+	changed = manager.Tick(now)
+	require.Len(t, changed, 1)
+	assert.Equal(t, id, changed[0].ID)
+	assert.Equal(t, domain.INCIDENT_STATUS_REOPENED, changed[0].Status)
+
+	now = now.Add(10 * time.Minute)
+	manager.Tick(now)
+
+	// after the reopen window a new incident is opened
+	now = now.Add(2 * time.Hour)
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+	changed = manager.Tick(now)
+	require.Len(t, changed, 1)
+	assert.NotEqual(t, id, changed[0].ID)
+
+	// the resolved incident is forgotten after the retention
+	now = now.Add(25 * time.Hour)
+	manager.Tick(now)
+	for _, i := range manager.Incidents() {
+		assert.NotEqual(t, id, i.ID)
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"events":        3,
+		"alerts":        0,
+		"opened":        2,
+		"reopened":      1,
+		"resolved":      3,
+		"active":        0,
+		"save_errors":   0,
+		"reload_errors": 0,
+	}, manager.Stats())
+}
+
+func TestManager_Persistence(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	store := &memoryStore{}
+
+	manager := newManager(t, domain.IncidentConfig{}, clock, store)
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+	manager.Tick(now)
+
+	require.Len(t, store.incidents, 1)
+	assert.Equal(t, 1, store.saves)
+
+	manager.Tick(now)
+	assert.Equal(t, 1, store.saves, "the state is only saved when it changed")
+
+	restarted := newManager(t, domain.IncidentConfig{}, clock, store)
+	restarted.ObserveEvent(errorEvent("payments", "charge failed"))
+
+	incidents := restarted.Incidents()
+	require.Len(t, incidents, 1)
+	assert.Equal(t, 2, incidents[0].EventCount, "the restored incident keeps collecting events")
+	require.NoError(t, restarted.Close())
+	assert.Equal(t, 2, store.incidents[0].EventCount)
+}
+
+func TestManager_AppliesAcknowledgementsOfAnotherProcess(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+	store := &memoryStore{}
+
+	manager := newManager(t, domain.IncidentConfig{}, clock, store)
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+	manager.ObserveEvent(errorEvent("search", "index missing"))
+	manager.Tick(now)
+
+	// ack-incident acknowledges the payments incident in the store
+	cli := newManager(t, domain.IncidentConfig{}, clock, store)
+	require.NoError(t, cli.Acknowledge(store.incidents[0].ID))
+	require.NoError(t, cli.Close())
+	store.changed = true
+
+	now = now.Add(time.Minute)
+	changed := manager.Tick(now)
+
+	require.Len(t, changed, 1)
+	assert.Equal(t, "payments", changed[0].Service)
+	assert.Equal(t, domain.INCIDENT_STATUS_ACKNOWLEDGED, changed[0].Status)
+	assert.Equal(t, domain.INCIDENT_STATUS_ACKNOWLEDGED, store.incidents[0].Status, "the running process keeps the acknowledgement")
+	assert.Equal(t, domain.INCIDENT_STATUS_OPEN, store.incidents[1].Status)
+}
+
+func TestManager_RetriesFailedSaves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	now := time.Now()
+	clock := domain.NewMockClock(gomock.NewController(t))
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	store := ports.NewMockIncidentStore(ctrl)
+	store.EXPECT().Load().Return(nil, nil)
+	store.EXPECT().Changed().Return(false, nil).AnyTimes()
+	store.EXPECT().Save(gomock.Any()).Return(errors.New("disk full"))
+	store.EXPECT().Save(gomock.Any()).Return(nil)
+
+	manager := newManager(t, domain.IncidentConfig{}, clock, store)
+	manager.ObserveEvent(errorEvent("payments", "charge failed"))
+
+	manager.Tick(now)
+	manager.Tick(now)
+
+	assert.Equal(t, uint64(1), manager.Stats()["save_errors"])
+}
+
+func TestNewManager_LoadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	store := ports.NewMockIncidentStore(ctrl)
+	store.EXPECT().Load().Return(nil, errors.New("corrupted"))
+
+	_, err := incident.NewManager(domain.IncidentConfig{}, domain.NewMockClock(gomock.NewController(t)), &sequenceGenerator{}, store)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,343 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/analysis"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataAnalysis      = domain.METADATA_ANALYSIS
+	MetadataAnalysisError = "analysis_error"
+
+	defaultAnalyzeSeverity   = domain.LOG_LEVEL_ERROR
+	defaultAnalyzeContext    = 10
+	defaultAnalyzeConcurrent = 2
+	defaultAnalyzeTimeout    = 30 * time.Second
+	defaultPromptTokens      = 3000
+	// maxWaiting bounds the occurrences waiting for the analysis of the same fingerprint
+	maxWaiting = 1000
+)
+
+var errNoAnalyzer = errors.New("no ai provider configured")
+
+// Analyzer asks the AI provider about the severe events. The analyses run in the background: the
+// event is held back and released by Flush with the analysis in its metadata, so a slow provider
+// never blocks the other events. The events arriving while all the analyses are running pass as is.
+// The analyses are cached by prompt template and fingerprint, the next occurrences of an event get
+// the cached analysis, or wait for the running one
+type Analyzer struct {
+	pipeline    string
+	analyzer    ports.AIAnalyzer
+	cache       ports.AnalysisCache
+	prompts     *analysis.PromptBuilder
+	templateKey string
+	minSeverity domain.LogLevel
+	context     int
+	timeout     time.Duration
+	slots       chan struct{}
+	wg          sync.WaitGroup
+
+	mu      sync.Mutex
+	recent  []domain.LogEvent
+	done    []domain.LogEvent
+	waiting map[string][]domain.LogEvent
+
+	received         atomic.Uint64
+	requested        atomic.Uint64
+	analyzed         atomic.Uint64
+	failed           atomic.Uint64
+	skipped          atomic.Uint64
+	cached           atomic.Uint64
+	joined           atomic.Uint64
+	promptTokens     atomic.Uint64
+	completionTokens atomic.Uint64
+}
+
+// NewAnalyzer creates the stage, the cache is optional
+func NewAnalyzer(pipeline string, config domain.AnalyzeConfig, analyzer ports.AIAnalyzer, cache ports.AnalysisCache) (*Analyzer, error) {
+	if analyzer == nil {
+		return nil, fmt.Errorf("%w: analyze: %w", domain.ErrInvalidStage, errNoAnalyzer)
+	}
+
+	text := config.Template
+	if config.TemplateFile != "" {
+		data, err := os.ReadFile(config.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: analyze template: %w", domain.ErrInvalidStage, err)
+		}
+		text = string(data)
+	}
+
+	maxTokens := config.MaxPromptTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultPromptTokens
+	}
+
+	prompts, err := analysis.NewPromptBuilder(text, maxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidStage, err)
+	}
+
+	if text == "" {
+		text = analysis.DefaultTemplate
+	}
+	hash := sha256.Sum256([]byte(text))
+
+	a := &Analyzer{
+		pipeline:    pipeline,
+		analyzer:    analyzer,
+		cache:       cache,
+		prompts:     prompts,
+		templateKey: hex.EncodeToString(hash[:])[:8],
+		minSeverity: config.MinSeverity,
+		context:     config.Context,
+		timeout:     config.Timeout,
+		waiting:     make(map[string][]domain.LogEvent),
+	}
+
+	if a.minSeverity == "" {
+		a.minSeverity = defaultAnalyzeSeverity
+	}
+	if a.context <= 0 {
+		a.context = defaultAnalyzeContext
+	}
+	if a.timeout <= 0 {
+		a.timeout = defaultAnalyzeTimeout
+	}
+
+	concurrent := config.MaxConcurrent
+	if concurrent <= 0 {
+		concurrent = defaultAnalyzeConcurrent
+	}
+	a.slots = make(chan struct{}, concurrent)
+
+	return a, nil
+}
+
+func (a *Analyzer) Name() string {
+	return domain.STAGE_ANALYZE
+}
+
+// Process holds the severe events while they are analyzed, the others pass and are kept as the
+// context of the next analyses
+func (a *Analyzer) Process(event domain.LogEvent) []domain.LogEvent {
+	a.received.Add(1)
+
+	a.mu.Lock()
+	recent := append([]domain.LogEvent(nil), a.recent...)
+	a.recent = append(a.recent, event)
+	if len(a.recent) > a.context {
+		a.recent = a.recent[len(a.recent)-a.context:]
+	}
+	a.mu.Unlock()
+
+	if event.Severity.Rank() < a.minSeverity.Rank() {
+		return []domain.LogEvent{event}
+	}
+
+	fingerprint := analysisFingerprint(event)
+	key := a.templateKey + ":" + fingerprint
+
+	if a.cache != nil {
+		if cached, ok := a.cache.Get(key); ok {
+			a.cached.Add(1)
+			return []domain.LogEvent{withMetadata(event, map[string]interface{}{MetadataAnalysis: cached})}
+		}
+	}
+
+	if a.wait(key, event) {
+		a.joined.Add(1)
+		return nil
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+	default:
+		a.skipped.Add(1)
+		return []domain.LogEvent{event}
+	}
+
+	data := a.promptData(event, recent)
+	prompt, err := a.prompts.Build(data)
+	if err != nil {
+		<-a.slots
+		a.failed.Add(1)
+		return []domain.LogEvent{withMetadata(event, map[string]interface{}{MetadataAnalysisError: err.Error()})}
+	}
+
+	a.mu.Lock()
+	a.waiting[key] = []domain.LogEvent{}
+	a.mu.Unlock()
+
+	a.requested.Add(1)
+	a.wg.Add(1)
+	go a.analyze(key, fingerprint, event, data.Context, prompt)
+
+	return nil
+}
+
+// wait holds the event until the running analysis of the key is done
+func (a *Analyzer) wait(key string, event domain.LogEvent) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	waiting, ok := a.waiting[key]
+	if !ok || len(waiting) >= maxWaiting {
+		return false
+	}
+
+	a.waiting[key] = append(waiting, event)
+
+	return true
+}
+
+// Flush releases the analyzed events, waiting for the running analyses on shutdown
+func (a *Analyzer) Flush(now time.Time, final bool) []domain.LogEvent {
+	if final {
+		a.wg.Wait()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	done := a.done
+	a.done = nil
+
+	return done
+}
+
+func (a *Analyzer) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"received":          a.received.Load(),
+		"requested":         a.requested.Load(),
+		"analyzed":          a.analyzed.Load(),
+		"failed":            a.failed.Load(),
+		"skipped":           a.skipped.Load(),
+		"cached":            a.cached.Load(),
+		"joined":            a.joined.Load(),
+		"tokens.prompt":     a.promptTokens.Load(),
+		"tokens.completion": a.completionTokens.Load(),
+	}
+}
+
+func (a *Analyzer) analyze(key, fingerprint string, event domain.LogEvent, surrounding []domain.LogEvent, prompt string) {
+	defer a.wg.Done()
+	defer func() { <-a.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	result, err := a.analyzer.Analyze(ctx, domain.AnalysisRequest{
+		Pipeline: a.pipeline,
+		Prompt:   prompt,
+		Event:    event,
+		Context:  surrounding,
+	})
+
+	fields := make(map[string]interface{}, 1)
+	shared := make(map[string]interface{}, 1)
+	if err != nil {
+		a.failed.Add(1)
+		fields[MetadataAnalysisError] = err.Error()
+		shared[MetadataAnalysisError] = err.Error()
+	} else {
+		a.analyzed.Add(1)
+		a.promptTokens.Add(uint64(result.Usage.Prompt))
+		a.completionTokens.Add(uint64(result.Usage.Completion))
+
+		// the heuristic answers when the models fail, caching it would keep them from being asked again
+		result.Fingerprint = fingerprint
+		if a.cache != nil && !result.Heuristic {
+			a.cache.Set(key, fingerprint, result)
+		}
+		fields[MetadataAnalysis] = result
+
+		result.Cached = true
+		shared[MetadataAnalysis] = result
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.done = append(a.done, withMetadata(event, fields))
+	for _, waiting := range a.waiting[key] {
+		a.done = append(a.done, withMetadata(waiting, shared))
+	}
+	delete(a.waiting, key)
+}
+
+// analysisFingerprint identifies the occurrences of the same event, by the template of its message
+// when it was mined
+func analysisFingerprint(event domain.LogEvent) string {
+	if id, ok := event.Metadata[MetadataTemplateID].(string); ok && id != "" {
+		return id
+	}
+
+	return event.Fingerprint()
+}
+
+// promptData prefers the events correlated with the event as its context, the metadata set by the
+// templates and correlate stages are moved out of the enrichment
+func (a *Analyzer) promptData(event domain.LogEvent, recent []domain.LogEvent) analysis.PromptData {
+	data := analysis.PromptData{
+		Pipeline:   a.pipeline,
+		Event:      event,
+		Context:    recent,
+		Enrichment: make(map[string]interface{}, len(event.Metadata)),
+	}
+
+	if correlated, ok := event.Metadata[MetadataCorrelated].([]map[string]interface{}); ok && len(correlated) > 0 {
+		data.Context = correlatedEvents(correlated)
+	}
+
+	if id, ok := event.Metadata[MetadataTemplateID].(string); ok {
+		data.Template = &analysis.TemplateStats{ID: id}
+		data.Template.Pattern, _ = event.Metadata[MetadataTemplate].(string)
+		data.Template.Count, _ = event.Metadata[MetadataTemplateCount].(uint64)
+		data.Template.New, _ = event.Metadata[MetadataNewTemplate].(bool)
+	}
+
+	for key, value := range event.Metadata {
+		switch key {
+		case MetadataTemplateID, MetadataTemplate, MetadataTemplateCount, MetadataNewTemplate,
+			MetadataCorrelated, MetadataCorrelatedCount:
+			continue
+		}
+		data.Enrichment[key] = value
+	}
+
+	return data
+}
+
+// correlatedEvents reads back the events summarised by the correlate stage
+func correlatedEvents(summaries []map[string]interface{}) []domain.LogEvent {
+	events := make([]domain.LogEvent, 0, len(summaries))
+
+	for _, summary := range summaries {
+		var event domain.LogEvent
+		event.ID, _ = summary[domain.FIELD_ID].(string)
+		event.Source, _ = summary[domain.FIELD_SOURCE].(string)
+		event.Message, _ = summary[domain.FIELD_MESSAGE].(string)
+
+		severity, _ := summary[domain.FIELD_SEVERITY].(string)
+		event.Severity = domain.LogLevel(severity)
+
+		if timestamp, ok := summary[domain.FIELD_TIMESTAMP].(string); ok {
+			event.Timestamp, _ = time.Parse(time.RFC3339Nano, timestamp)
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,282 @@
+package pipeline_test
+
+import (
+	"context"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/pipeline"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAnalyzer_Process(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	result := domain.Analysis{Summary: "db down", Usage: domain.TokenUsage{Prompt: 50, Completion: 10}}
+
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			assert.Equal(t, "payments", request.Pipeline)
+			assert.Equal(t, "connection refused", request.Event.Message)
+			assert.Equal(t, "ERROR connection refused after 1 events: retrying", request.Prompt)
+			require.Len(t, request.Context, 1)
+
+			return result, nil
+		})
+
+	analyzer, err := pipeline.NewAnalyzer("payments", domain.AnalyzeConfig{
+		Template: `{{.Event.Severity}} {{.Event.Message}} after {{len .Context}} events: {{range .Context}}{{.Message}}{{end}}`,
+	}, provider, nil)
+	require.NoError(t, err)
+
+	info := domain.LogEvent{Timestamp: at, Severity: domain.LOG_LEVEL_INFO, Message: "retrying"}
+	assert.Equal(t, []domain.LogEvent{info}, analyzer.Process(info))
+
+	severe := domain.LogEvent{Timestamp: at.Add(time.Second), Severity: domain.LOG_LEVEL_ERROR, Message: "connection refused"}
+	assert.Empty(t, analyzer.Process(severe), "the severe events are held while analyzed")
+
+	released := analyzer.Flush(at, true)
+
+	require.Len(t, released, 1)
+	assert.Equal(t, severe.Message, released[0].Message)
+	result.Fingerprint = severe.Fingerprint()
+	assert.Equal(t, result, released[0].Metadata[pipeline.MetadataAnalysis])
+	assert.Empty(t, analyzer.Flush(at, true))
+
+	assert.Equal(t, map[string]uint64{
+		"received":          2,
+		"requested":         1,
+		"analyzed":          1,
+		"failed":            0,
+		"skipped":           0,
+		"cached":            0,
+		"joined":            0,
+		"tokens.prompt":     50,
+		"tokens.completion": 10,
+	}, analyzer.Stats())
+	assert.Equal(t, domain.STAGE_ANALYZE, analyzer.Name())
+}
+
+func TestAnalyzer_ShouldReportFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{}, fmt.Errorf("openai: %w", domain.ErrRateLimited))
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{}, provider, nil)
+	require.NoError(t, err)
+
+	analyzer.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL, Message: "panic"})
+	released := analyzer.Flush(time.Now(), true)
+
+	require.Len(t, released, 1)
+	assert.Equal(t, "openai: ai provider rate limited", released[0].Metadata[pipeline.MetadataAnalysisError])
+	assert.NotContains(t, released[0].Metadata, pipeline.MetadataAnalysis)
+	assert.Equal(t, uint64(1), analyzer.Stats()["failed"])
+}
+
+func TestAnalyzer_ShouldPassEventsWhenBusy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+
+	release := make(chan struct{})
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+			<-release
+			return domain.Analysis{Summary: "slow"}, nil
+		})
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{MaxConcurrent: 1}, provider, nil)
+	require.NoError(t, err)
+
+	first := domain.LogEvent{ID: "1", Severity: domain.LOG_LEVEL_ERROR, Message: "payment declined"}
+	second := domain.LogEvent{ID: "2", Severity: domain.LOG_LEVEL_ERROR, Message: "disk full"}
+
+	assert.Empty(t, analyzer.Process(first))
+	assert.Equal(t, []domain.LogEvent{second}, analyzer.Process(second))
+	assert.Empty(t, analyzer.Flush(time.Now(), false), "the running analysis isn't released yet")
+
+	close(release)
+	released := analyzer.Flush(time.Now(), true)
+
+	require.Len(t, released, 1)
+	assert.Equal(t, "1", released[0].ID)
+	assert.Equal(t, uint64(1), analyzer.Stats()["skipped"])
+}
+
+func TestAnalyzer_ShouldUseCorrelatedEventsAndTemplate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+			assert.Equal(t, "[cache miss] pod=api-1 x3 checkout <*> failed", request.Prompt)
+			return domain.Analysis{Summary: "ok"}, nil
+		})
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{
+		Template: `{{range .Context}}[{{.Message}}]{{end}}{{range $k, $v := .Enrichment}} {{$k}}={{$v}}{{end}} x{{.Template.Count}} {{.Template.Pattern}}`,
+	}, provider, nil)
+	require.NoError(t, err)
+
+	analyzer.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Message: "unrelated"})
+	analyzer.Process(domain.LogEvent{
+		Severity: domain.LOG_LEVEL_ERROR,
+		Message:  "checkout 42 failed",
+		Metadata: map[string]interface{}{
+			"pod":                            "api-1",
+			pipeline.MetadataTemplateID:      "abc",
+			pipeline.MetadataTemplate:        "checkout <*> failed",
+			pipeline.MetadataTemplateCount:   uint64(3),
+			pipeline.MetadataCorrelatedCount: 1,
+			pipeline.MetadataCorrelated: []map[string]interface{}{
+				{"id": "0", "timestamp": "2025-01-01T00:00:00Z", "source": "file", "severity": "DEBUG", "message": "cache miss"},
+			},
+		},
+	})
+
+	require.Len(t, analyzer.Flush(time.Now(), true), 1)
+}
+
+func TestNewAnalyzer_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+
+	tests := []struct {
+		name     string
+		config   domain.AnalyzeConfig
+		provider ports.AIAnalyzer
+	}{
+		{
+			name:   "ShouldRequireProvider",
+			config: domain.AnalyzeConfig{},
+		},
+		{
+			name:     "ShouldRejectInvalidTemplate",
+			config:   domain.AnalyzeConfig{Template: "{{.Event"},
+			provider: provider,
+		},
+		{
+			name:     "ShouldRejectMissingTemplateFile",
+			config:   domain.AnalyzeConfig{TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")},
+			provider: provider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer, err := pipeline.NewAnalyzer("default", tt.config, tt.provider, nil)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidStage)
+			assert.Nil(t, analyzer)
+		})
+	}
+}
+
+func TestNewAnalyzer_TemplateFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+			assert.Equal(t, "why: boom", request.Prompt)
+			return domain.Analysis{Summary: "ok"}, nil
+		})
+
+	file := filepath.Join(t.TempDir(), "prompt.tmpl")
+	require.NoError(t, os.WriteFile(file, []byte("why: {{.Event.Message}}"), 0o600))
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{TemplateFile: file}, provider, nil)
+	require.NoError(t, err)
+
+	analyzer.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Message: "boom"})
+	require.Len(t, analyzer.Flush(time.Now(), true), 1)
+}
+
+func TestAnalyzer_ShouldReuseCachedAnalysis(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+	cache := ports.NewMockAnalysisCache(ctrl)
+
+	cached := domain.Analysis{Summary: "db down", Fingerprint: "abc", Cached: true}
+	cache.EXPECT().Get(gomock.Any()).DoAndReturn(func(key string) (domain.Analysis, bool) {
+		assert.Regexp(t, `^[0-9a-f]{8}:abc$`, key)
+		return cached, true
+	})
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{}, provider, cache)
+	require.NoError(t, err)
+
+	result := analyzer.Process(domain.LogEvent{
+		Severity: domain.LOG_LEVEL_ERROR,
+		Message:  "connection refused",
+		Metadata: map[string]interface{}{pipeline.MetadataTemplateID: "abc"},
+	})
+
+	require.Len(t, result, 1, "a cached analysis is attached right away")
+	assert.Equal(t, cached, result[0].Metadata[pipeline.MetadataAnalysis])
+	assert.Equal(t, uint64(1), analyzer.Stats()["cached"])
+}
+
+func TestAnalyzer_ShouldShareRunningAnalysis(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+	cache := ports.NewMockAnalysisCache(ctrl)
+
+	first := domain.LogEvent{ID: "1", Severity: domain.LOG_LEVEL_ERROR, Message: "payment 42 declined"}
+	second := domain.LogEvent{ID: "2", Severity: domain.LOG_LEVEL_ERROR, Message: "payment 43 declined"}
+
+	release := make(chan struct{})
+	cache.EXPECT().Get(gomock.Any()).Times(2).Return(domain.Analysis{}, false)
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request domain.AnalysisRequest) (domain.Analysis, error) {
+			<-release
+			return domain.Analysis{Summary: "card issuer down"}, nil
+		})
+	cache.EXPECT().Set(gomock.Any(), first.Fingerprint(), domain.Analysis{Summary: "card issuer down", Fingerprint: first.Fingerprint()})
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{}, provider, cache)
+	require.NoError(t, err)
+
+	assert.Empty(t, analyzer.Process(first))
+	assert.Empty(t, analyzer.Process(second), "the next occurrence waits for the running analysis")
+
+	close(release)
+	released := analyzer.Flush(time.Now(), true)
+
+	require.Len(t, released, 2)
+	assert.Equal(t, "1", released[0].ID)
+	assert.False(t, released[0].Metadata[pipeline.MetadataAnalysis].(domain.Analysis).Cached)
+	assert.Equal(t, "2", released[1].ID)
+	assert.True(t, released[1].Metadata[pipeline.MetadataAnalysis].(domain.Analysis).Cached)
+	assert.Equal(t, uint64(1), analyzer.Stats()["joined"])
+	assert.Equal(t, uint64(1), analyzer.Stats()["requested"])
+}
+
+func TestAnalyzer_ShouldNotCacheHeuristicAnalysis(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	provider := ports.NewMockAIAnalyzer(ctrl)
+	cache := ports.NewMockAnalysisCache(ctrl)
+
+	cache.EXPECT().Get(gomock.Any()).Return(domain.Analysis{}, false)
+	provider.EXPECT().Analyze(gomock.Any(), gomock.Any()).Return(domain.Analysis{Summary: "out of memory", Heuristic: true}, nil)
+	cache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	analyzer, err := pipeline.NewAnalyzer("default", domain.AnalyzeConfig{}, provider, cache)
+	require.NoError(t, err)
+
+	assert.Empty(t, analyzer.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL, Message: "OOMKilled"}))
+
+	released := analyzer.Flush(time.Now(), true)
+	require.Len(t, released, 1)
+	assert.Equal(t, "out of memory", released[0].Metadata[pipeline.MetadataAnalysis].(domain.Analysis).Summary)
+}
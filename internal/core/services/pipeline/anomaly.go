@@ -0,0 +1,309 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataAnomalyType     = "anomaly_type"
+	MetadataAnomalyKey      = "anomaly_key"
+	MetadataAnomalyCount    = "anomaly_count"
+	MetadataAnomalyBaseline = "anomaly_baseline"
+	MetadataAnomalyZScore   = "anomaly_zscore"
+
+	AnomalySpike   = "spike"
+	AnomalySilence = "silence"
+
+	defaultAnomalyWindow     = time.Minute
+	defaultAnomalyBuckets    = 6
+	defaultAnomalyAlpha      = 0.3
+	defaultAnomalyThreshold  = 3
+	defaultAnomalyMinSamples = 5
+	defaultAnomalyMaxKeys    = 10000
+
+	// minDeviation keeps flat baselines from turning every extra event into an anomaly
+	minDeviation = 1.0
+	// forgetBelow is the baseline under which a quiet key is forgotten
+	forgetBelow = 0.01
+)
+
+// AnomalyDetector counts the events per key in a sliding window, kept as a ring of buckets, and
+// compares the window with the baseline each time it slides by a bucket. The baseline learns from
+// the successive windows that don't overlap. An anomaly is reported once while it lasts. The events
+// pass through unchanged, the anomalies are reported with synthetic events when the pipeline is
+// flushed
+type AnomalyDetector struct {
+	keyBy       []string
+	minSeverity domain.LogLevel
+	window      time.Duration
+	buckets     int
+	step        time.Duration
+	alpha       float64
+	threshold   float64
+	minSamples  int
+	silence     bool
+	maxKeys     int
+	clock       domain.Clock
+	idGen       domain.IDGenerator
+
+	mu          sync.Mutex
+	bucketStart time.Time
+	cursor      int
+	steps       int
+	series      map[string]*series
+
+	received  atomic.Uint64
+	counted   atomic.Uint64
+	untracked atomic.Uint64
+	spikes    atomic.Uint64
+	silences  atomic.Uint64
+}
+
+type series struct {
+	ring     []int
+	count    int
+	mean     float64
+	variance float64
+	samples  int
+	source   string
+	// reported is the kind of the anomaly in progress
+	reported string
+}
+
+func NewAnomalyDetector(config domain.AnomalyConfig, clock domain.Clock, idGen domain.IDGenerator) *AnomalyDetector {
+	a := &AnomalyDetector{
+		keyBy:       config.KeyBy,
+		minSeverity: config.MinSeverity,
+		window:      config.Window,
+		buckets:     config.Buckets,
+		alpha:       config.Alpha,
+		threshold:   config.Threshold,
+		minSamples:  config.MinSamples,
+		silence:     config.Silence,
+		maxKeys:     config.MaxKeys,
+		clock:       clock,
+		idGen:       idGen,
+		bucketStart: clock.Now(),
+		series:      make(map[string]*series),
+	}
+
+	if len(a.keyBy) == 0 {
+		a.keyBy = []string{domain.FIELD_SOURCE}
+	}
+	if a.window <= 0 {
+		a.window = defaultAnomalyWindow
+	}
+	if a.buckets <= 0 {
+		a.buckets = defaultAnomalyBuckets
+	}
+	a.step = a.window / time.Duration(a.buckets)
+	if a.alpha <= 0 {
+		a.alpha = defaultAnomalyAlpha
+	}
+	if a.threshold <= 0 {
+		a.threshold = defaultAnomalyThreshold
+	}
+	if a.minSamples <= 0 {
+		a.minSamples = defaultAnomalyMinSamples
+	}
+	if a.maxKeys <= 0 {
+		a.maxKeys = defaultAnomalyMaxKeys
+	}
+
+	return a
+}
+
+func (a *AnomalyDetector) Name() string {
+	return domain.STAGE_ANOMALY
+}
+
+// Process counts the event in the window of its key
+func (a *AnomalyDetector) Process(event domain.LogEvent) []domain.LogEvent {
+	a.received.Add(1)
+
+	if a.minSeverity != "" && event.Severity.Rank() < a.minSeverity.Rank() {
+		return []domain.LogEvent{event}
+	}
+
+	key := a.key(event)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.series[key]
+	if !ok {
+		if len(a.series) >= a.maxKeys {
+			a.untracked.Add(1)
+			return []domain.LogEvent{event}
+		}
+
+		s = &series{ring: make([]int, a.buckets)}
+		a.series[key] = s
+	}
+
+	s.ring[a.cursor]++
+	s.count++
+	s.source = event.Source
+	a.counted.Add(1)
+
+	return []domain.LogEvent{event}
+}
+
+// Flush slides the window by each elapsed bucket and reports the anomalies. The bucket in progress
+// is not evaluated on the final flush, since its counts are partial
+func (a *AnomalyDetector) Flush(now time.Time, final bool) []domain.LogEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var anomalies []domain.LogEvent
+
+	for now.Sub(a.bucketStart) >= a.step {
+		a.bucketStart = a.bucketStart.Add(a.step)
+		a.steps++
+		anomalies = append(anomalies, a.slide(a.bucketStart)...)
+	}
+
+	return anomalies
+}
+
+// slide evaluates the window ending with the closed bucket of every key against its baseline, then
+// drops the oldest bucket. The baseline learns once a whole window, so its samples never overlap
+func (a *AnomalyDetector) slide(end time.Time) []domain.LogEvent {
+	keys := make([]string, 0, len(a.series))
+	for key := range a.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	learn := a.steps%a.buckets == 0
+	a.cursor = (a.cursor + 1) % a.buckets
+
+	var anomalies []domain.LogEvent
+	for _, key := range keys {
+		s := a.series[key]
+		count := float64(s.count)
+
+		if s.samples >= a.minSamples {
+			deviation := math.Max(math.Sqrt(s.variance), minDeviation)
+			zscore := (count - s.mean) / deviation
+
+			kind := ""
+			switch {
+			case zscore >= a.threshold:
+				kind = AnomalySpike
+			case a.silence && s.count == 0 && zscore <= -a.threshold:
+				kind = AnomalySilence
+			}
+
+			if kind != "" && kind != s.reported {
+				if kind == AnomalySpike {
+					a.spikes.Add(1)
+				} else {
+					a.silences.Add(1)
+				}
+				anomalies = a.appendAnomaly(anomalies, kind, key, s, zscore, end)
+			}
+			s.reported = kind
+		}
+
+		if learn {
+			s.learn(count, a.alpha)
+		}
+
+		s.count -= s.ring[a.cursor]
+		s.ring[a.cursor] = 0
+
+		if learn && s.samples > 1 && s.mean < forgetBelow && s.count == 0 {
+			delete(a.series, key)
+		}
+	}
+
+	return anomalies
+}
+
+func (a *AnomalyDetector) appendAnomaly(
+	anomalies []domain.LogEvent,
+	kind, key string,
+	s *series,
+	zscore float64,
+	end time.Time,
+) []domain.LogEvent {
+	seconds := int(a.window.Seconds())
+
+	severity := domain.LOG_LEVEL_ERROR
+	message := fmt.Sprintf("event rate spike for %s: %d events in %ds, baseline %.1f", key, s.count, seconds, s.mean)
+	if kind == AnomalySilence {
+		severity = domain.LOG_LEVEL_WARNING
+		message = fmt.Sprintf("%s went silent: no events in %ds, baseline %.1f", key, seconds, s.mean)
+	}
+
+	metadata := map[string]interface{}{
+		MetadataAnomalyType:     kind,
+		MetadataAnomalyKey:      key,
+		MetadataAnomalyCount:    s.count,
+		MetadataAnomalyBaseline: math.Round(s.mean*100) / 100,
+		MetadataAnomalyZScore:   math.Round(zscore*100) / 100,
+		MetadataFirstSeen:       end.Add(-a.window).Format(time.RFC3339Nano),
+		MetadataLastSeen:        end.Format(time.RFC3339Nano),
+	}
+
+	event, err := domain.NewLogEvent(s.source, message, severity, metadata, a.idGen)
+	if err != nil {
+		return anomalies
+	}
+	event.Timestamp = end
+
+	return append(anomalies, *event)
+}
+
+// learn updates the exponentially weighted mean and variance with the count of a window
+func (s *series) learn(count, alpha float64) {
+	if s.samples == 0 {
+		s.mean = count
+	} else {
+		diff := count - s.mean
+		increment := alpha * diff
+		s.mean += increment
+		s.variance = (1 - alpha) * (s.variance + diff*increment)
+	}
+
+	s.samples++
+}
+
+func (a *AnomalyDetector) Stats() map[string]uint64 {
+	a.mu.Lock()
+	keys := len(a.series)
+	a.mu.Unlock()
+
+	return map[string]uint64{
+		"received":          a.received.Load(),
+		"counted":           a.counted.Load(),
+		"untracked":         a.untracked.Load(),
+		"keys":              uint64(keys),
+		"anomalies.spike":   a.spikes.Load(),
+		"anomalies.silence": a.silences.Load(),
+	}
+}
+
+// key joins the configured fields as field=value pairs
+func (a *AnomalyDetector) key(event domain.LogEvent) string {
+	parts := make([]string, 0, len(a.keyBy))
+
+	for _, field := range a.keyBy {
+		value, ok := event.Field(field)
+		if !ok || value == "" {
+			value = unknownLimitKey
+		}
+
+		parts = append(parts, fmt.Sprintf("%s=%v", field, value))
+	}
+
+	return strings.Join(parts, ",")
+}
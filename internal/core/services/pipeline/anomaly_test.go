@@ -0,0 +1,168 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedWindows sends count events per window and closes the windows, returning the anomalies
+func feedWindows(detector *pipeline.AnomalyDetector, clock *fakeClock, event domain.LogEvent, counts ...int) []domain.LogEvent {
+	var anomalies []domain.LogEvent
+
+	for _, count := range counts {
+		for i := 0; i < count; i++ {
+			detector.Process(event)
+		}
+
+		clock.Advance(time.Minute)
+		anomalies = append(anomalies, detector.Flush(clock.Now(), false)...)
+	}
+
+	return anomalies
+}
+
+func TestAnomalyDetector_Spike(t *testing.T) {
+	clock := newClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{
+		KeyBy:      []string{"source", "metadata.pod"},
+		Window:     time.Minute,
+		MinSamples: 3,
+	}, clock, newIDGenerator(t))
+
+	event := domain.LogEvent{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{"pod": "api-1"}}
+
+	require.Empty(t, feedWindows(detector, clock, event, 10, 11, 9, 10))
+
+	anomalies := feedWindows(detector, clock, event, 100)
+
+	require.Len(t, anomalies, 1)
+	anomaly := anomalies[0]
+	assert.Equal(t, domain.LOG_LEVEL_ERROR, anomaly.Severity)
+	assert.Equal(t, domain.SOURCE_FILE, anomaly.Source)
+	assert.Equal(t, clock.Now().Add(-50*time.Second), anomaly.Timestamp, "the spike is reported when the first bucket holding it closes")
+	assert.Equal(t, "event rate spike for source=file,metadata.pod=api-1: 100 events in 60s, baseline 9.9", anomaly.Message)
+	assert.Equal(t, pipeline.AnomalySpike, anomaly.Metadata[pipeline.MetadataAnomalyType])
+	assert.Equal(t, "source=file,metadata.pod=api-1", anomaly.Metadata[pipeline.MetadataAnomalyKey])
+	assert.Equal(t, 100, anomaly.Metadata[pipeline.MetadataAnomalyCount])
+	assert.Greater(t, anomaly.Metadata[pipeline.MetadataAnomalyZScore], 3.0)
+}
+
+func TestAnomalyDetector_Buckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		buckets  int
+		expected int
+	}{
+		{name: "ShouldSlideByBucket", buckets: 6, expected: 1},
+		{name: "ShouldTumbleWithSingleBucket", buckets: 1, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{
+				Window:     time.Minute,
+				Buckets:    tt.buckets,
+				MinSamples: 3,
+			}, clock, newIDGenerator(t))
+
+			event := domain.LogEvent{Source: domain.SOURCE_FILE}
+			require.Empty(t, feedWindows(detector, clock, event, 10, 10, 10))
+
+			for i := 0; i < 100; i++ {
+				detector.Process(event)
+			}
+
+			clock.Advance(10 * time.Second)
+			assert.Len(t, detector.Flush(clock.Now(), false), tt.expected)
+		})
+	}
+}
+
+func TestAnomalyDetector_ShouldNotReportBeforeMinSamples(t *testing.T) {
+	clock := newClock(time.Now())
+	detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{MinSamples: 5}, clock, newIDGenerator(t))
+
+	assert.Empty(t, feedWindows(detector, clock, domain.LogEvent{Source: domain.SOURCE_STDIN}, 1, 1, 500))
+}
+
+func TestAnomalyDetector_Silence(t *testing.T) {
+	tests := []struct {
+		name     string
+		silence  bool
+		expected int
+	}{
+		{name: "ShouldReportSilenceWhenEnabled", silence: true, expected: 1},
+		{name: "ShouldIgnoreSilenceByDefault", silence: false, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newClock(time.Now())
+			detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{
+				MinSamples: 3,
+				Silence:    tt.silence,
+			}, clock, newIDGenerator(t))
+
+			event := domain.LogEvent{Source: domain.SOURCE_UNIX}
+			require.Empty(t, feedWindows(detector, clock, event, 20, 20, 20))
+
+			anomalies := feedWindows(detector, clock, event, 0)
+
+			require.Len(t, anomalies, tt.expected)
+			if tt.expected > 0 {
+				assert.Equal(t, domain.LOG_LEVEL_WARNING, anomalies[0].Severity)
+				assert.Equal(t, "source=unix went silent: no events in 60s, baseline 20.0", anomalies[0].Message)
+				assert.Equal(t, pipeline.AnomalySilence, anomalies[0].Metadata[pipeline.MetadataAnomalyType])
+			}
+		})
+	}
+}
+
+func TestAnomalyDetector_ShouldCloseEveryElapsedWindow(t *testing.T) {
+	clock := newClock(time.Now())
+	detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{MinSamples: 3, Silence: true}, clock, newIDGenerator(t))
+
+	event := domain.LogEvent{Source: domain.SOURCE_FILE}
+	require.Empty(t, feedWindows(detector, clock, event, 20, 20, 20))
+
+	clock.Advance(3 * time.Minute)
+	anomalies := detector.Flush(clock.Now(), false)
+
+	assert.NotEmpty(t, anomalies, "the missed windows count as silent")
+	assert.Empty(t, detector.Flush(clock.Now(), true), "the window in progress is not evaluated")
+}
+
+func TestAnomalyDetector_Process(t *testing.T) {
+	clock := newClock(time.Now())
+	detector := pipeline.NewAnomalyDetector(domain.AnomalyConfig{
+		MinSeverity: domain.LOG_LEVEL_ERROR,
+		KeyBy:       []string{"metadata.template_id"},
+		MaxKeys:     1,
+	}, clock, newIDGenerator(t))
+
+	events := []domain.LogEvent{
+		{Severity: domain.LOG_LEVEL_INFO},
+		{Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{"template_id": "a"}},
+		{Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{"template_id": "b"}},
+	}
+
+	for _, event := range events {
+		assert.Equal(t, []domain.LogEvent{event}, detector.Process(event), "the events pass through")
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"received":          3,
+		"counted":           1,
+		"untracked":         1,
+		"keys":              1,
+		"anomalies.spike":   0,
+		"anomalies.silence": 0,
+	}, detector.Stats())
+	assert.Equal(t, domain.STAGE_ANOMALY, detector.Name())
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"math/rand/v2"
+)
+
+// Build creates the pipeline and its stages from the config, the analyze stages call the analyzer and
+// share the cache
+func Build(
+	config domain.PipelineConfig,
+	clock domain.Clock,
+	idGen domain.IDGenerator,
+	analyzer ports.AIAnalyzer,
+	cache ports.AnalysisCache,
+) (*Pipeline, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	stages := make([]ports.Stage, 0, len(config.Stages))
+	for i, stageConfig := range config.Stages {
+		stage, err := buildStage(config.Name, stageConfig, clock, idGen, analyzer, cache)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s stage %d: %w", domain.ErrInvalidPipeline, config.Name, i, err)
+		}
+
+		stages = append(stages, stage)
+	}
+
+	return NewPipeline(config.Name, stages...), nil
+}
+
+func buildStage(
+	pipeline string,
+	config domain.StageConfig,
+	clock domain.Clock,
+	idGen domain.IDGenerator,
+	analyzer ports.AIAnalyzer,
+	cache ports.AnalysisCache,
+) (ports.Stage, error) {
+	switch config.Type {
+	case domain.STAGE_FILTER:
+		return NewFilter(*config.Filter)
+	case domain.STAGE_DEDUP:
+		return NewDedup(*config.Dedup, clock), nil
+	case domain.STAGE_SAMPLE:
+		seed := uint64(clock.Now().UnixNano())
+		return NewSampler(*config.Sample, clock, rand.New(rand.NewPCG(seed, seed>>1))), nil
+	case domain.STAGE_LIMIT:
+		return NewRateLimiter(*config.Limit, clock, idGen), nil
+	case domain.STAGE_REDACT:
+		return NewRedactor(*config.Redact)
+	case domain.STAGE_GROK:
+		return NewGrok(*config.Grok)
+	case domain.STAGE_TRANSFORM:
+		return NewTransform(*config.Transform)
+	case domain.STAGE_TEMPLATES:
+		return NewTemplateMiner(*config.Templates, clock), nil
+	case domain.STAGE_ANOMALY:
+		return NewAnomalyDetector(*config.Anomaly, clock, idGen), nil
+	case domain.STAGE_CORRELATE:
+		return NewCorrelator(*config.Correlate, clock), nil
+	case domain.STAGE_ANALYZE:
+		return NewAnalyzer(pipeline, *config.Analyze, analyzer, cache)
+	}
+
+	return nil, fmt.Errorf("%w: unknown type %q", domain.ErrInvalidStage, config.Type)
+}
@@ -0,0 +1,306 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataTraceID          = "trace_id"
+	MetadataSpanID           = "span_id"
+	MetadataRequestID        = "request_id"
+	MetadataCorrelated       = "correlated_events"
+	MetadataCorrelatedCount  = "correlated_count"
+	metadataTraceparent      = "traceparent"
+	defaultCorrelateWindow   = 5 * time.Minute
+	defaultCorrelateSeverity = domain.LOG_LEVEL_ERROR
+	defaultCorrelateContext  = 10
+	defaultCorrelateEvents   = 50
+	defaultCorrelateIDs      = 10000
+)
+
+// correlationFields lists the common metadata names of each ID
+var correlationFields = map[string][]string{
+	MetadataTraceID:   {"trace_id", "traceId", "traceID", "trace.id", "x-trace-id", "dd.trace_id"},
+	MetadataSpanID:    {"span_id", "spanId", "spanID", "span.id", "dd.span_id"},
+	MetadataRequestID: {"request_id", "requestId", "requestID", "req_id", "x-request-id", "correlation_id", "correlationId"},
+}
+
+var (
+	// regexTraceparent matches the W3C trace context header, version-trace-parent-flags
+	regexTraceparent = regexp.MustCompile(`\b[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}\b`)
+	regexMessageID   = regexp.MustCompile(`\b(trace_?[iI][dD]|span_?[iI][dD]|request_?[iI][dD]|req_id|correlation_?[iI][dD])["']?\s*[=:]\s*["']?([\w.-]+)`)
+)
+
+// Correlator links the events of the same trace or request across the inputs
+type Correlator struct {
+	requestFields []string
+	window        time.Duration
+	minSeverity   domain.LogLevel
+	context       int
+	maxEvents     int
+	maxIDs        int
+	clock         domain.Clock
+
+	mu    sync.Mutex
+	index map[string]*correlation
+
+	received  atomic.Uint64
+	extracted atomic.Uint64
+	enriched  atomic.Uint64
+	untracked atomic.Uint64
+}
+
+type correlation struct {
+	events   []correlatedEvent
+	lastSeen time.Time
+}
+
+type correlatedEvent struct {
+	indexed  time.Time
+	id       string
+	time     time.Time
+	source   string
+	severity domain.LogLevel
+	message  string
+}
+
+func NewCorrelator(config domain.CorrelateConfig, clock domain.Clock) *Correlator {
+	c := &Correlator{
+		requestFields: append(append([]string(nil), correlationFields[MetadataRequestID]...), config.Fields...),
+		window:        config.Window,
+		minSeverity:   config.MinSeverity,
+		context:       config.Context,
+		maxEvents:     config.MaxEvents,
+		maxIDs:        config.MaxIDs,
+		clock:         clock,
+		index:         make(map[string]*correlation),
+	}
+
+	if c.window <= 0 {
+		c.window = defaultCorrelateWindow
+	}
+	if c.minSeverity == "" {
+		c.minSeverity = defaultCorrelateSeverity
+	}
+	if c.context <= 0 {
+		c.context = defaultCorrelateContext
+	}
+	if c.maxEvents <= 0 {
+		c.maxEvents = defaultCorrelateEvents
+	}
+	if c.maxIDs <= 0 {
+		c.maxIDs = defaultCorrelateIDs
+	}
+
+	return c
+}
+
+func (c *Correlator) Name() string {
+	return domain.STAGE_CORRELATE
+}
+
+// Process sets trace_id, span_id and request_id from the metadata or the message. The severe
+// events also get the preceding events of the same IDs, then the event joins the index
+func (c *Correlator) Process(event domain.LogEvent) []domain.LogEvent {
+	c.received.Add(1)
+
+	ids := c.extract(event)
+	if len(ids) == 0 {
+		return []domain.LogEvent{event}
+	}
+	c.extracted.Add(1)
+
+	fields := make(map[string]interface{}, len(ids)+2)
+	for kind, id := range ids {
+		fields[kind] = id
+	}
+
+	keys := indexKeys(ids)
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if event.Severity.Rank() >= c.minSeverity.Rank() {
+		if related := c.related(keys, now); len(related) > 0 {
+			fields[MetadataCorrelated] = related
+			fields[MetadataCorrelatedCount] = len(related)
+			c.enriched.Add(1)
+		}
+	}
+
+	c.remember(keys, event, now)
+
+	return []domain.LogEvent{withMetadata(event, fields)}
+}
+
+// Flush forgets the IDs not seen within the window
+func (c *Correlator) Flush(now time.Time, final bool) []domain.LogEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.index {
+		if now.Sub(entry.lastSeen) > c.window {
+			delete(c.index, key)
+		}
+	}
+
+	return nil
+}
+
+func (c *Correlator) Stats() map[string]uint64 {
+	c.mu.Lock()
+	ids := len(c.index)
+	c.mu.Unlock()
+
+	return map[string]uint64{
+		"received":  c.received.Load(),
+		"extracted": c.extracted.Load(),
+		"enriched":  c.enriched.Load(),
+		"untracked": c.untracked.Load(),
+		"ids":       uint64(ids),
+	}
+}
+
+// extract returns the IDs found in the metadata, the traceparent or the message, by kind
+func (c *Correlator) extract(event domain.LogEvent) map[string]string {
+	ids := make(map[string]string, 3)
+
+	lookup := func(kind string, names []string) {
+		for _, name := range names {
+			if value, ok := event.GetMetadata(name); ok && value != nil && value != "" {
+				ids[kind] = fmt.Sprint(value)
+				return
+			}
+		}
+	}
+
+	lookup(MetadataTraceID, correlationFields[MetadataTraceID])
+	lookup(MetadataSpanID, correlationFields[MetadataSpanID])
+	lookup(MetadataRequestID, c.requestFields)
+
+	traceparent, _ := event.GetMetadata(metadataTraceparent)
+	for _, text := range []string{fmt.Sprint(traceparent), event.Message} {
+		if match := regexTraceparent.FindStringSubmatch(text); match != nil {
+			setDefault(ids, MetadataTraceID, match[1])
+			setDefault(ids, MetadataSpanID, match[2])
+		}
+	}
+
+	for _, match := range regexMessageID.FindAllStringSubmatch(event.Message, -1) {
+		name := strings.ToLower(strings.ReplaceAll(match[1], "_", ""))
+		switch {
+		case strings.HasPrefix(name, "trace"):
+			setDefault(ids, MetadataTraceID, match[2])
+		case strings.HasPrefix(name, "span"):
+			setDefault(ids, MetadataSpanID, match[2])
+		default:
+			setDefault(ids, MetadataRequestID, match[2])
+		}
+	}
+
+	// a span alone doesn't identify anything to correlate with
+	if len(ids) == 1 && ids[MetadataSpanID] != "" {
+		return nil
+	}
+
+	return ids
+}
+
+// related returns the last events indexed under the keys within the window, the oldest first
+func (c *Correlator) related(keys []string, now time.Time) []map[string]interface{} {
+	seen := make(map[string]bool)
+	var events []correlatedEvent
+
+	for _, key := range keys {
+		entry, ok := c.index[key]
+		if !ok {
+			continue
+		}
+
+		for _, e := range entry.events {
+			if now.Sub(e.indexed) > c.window || seen[e.id] {
+				continue
+			}
+			seen[e.id] = true
+			events = append(events, e)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].time.Before(events[j].time)
+	})
+
+	if len(events) > c.context {
+		events = events[len(events)-c.context:]
+	}
+
+	related := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		related = append(related, map[string]interface{}{
+			domain.FIELD_ID:        e.id,
+			domain.FIELD_TIMESTAMP: e.time.Format(time.RFC3339Nano),
+			domain.FIELD_SOURCE:    e.source,
+			domain.FIELD_SEVERITY:  string(e.severity),
+			domain.FIELD_MESSAGE:   e.message,
+		})
+	}
+
+	return related
+}
+
+func (c *Correlator) remember(keys []string, event domain.LogEvent, now time.Time) {
+	summary := correlatedEvent{
+		indexed:  now,
+		id:       event.ID,
+		time:     event.Timestamp,
+		source:   event.Source,
+		severity: event.Severity,
+		message:  event.Message,
+	}
+
+	for _, key := range keys {
+		entry, ok := c.index[key]
+		if !ok {
+			if len(c.index) >= c.maxIDs {
+				c.untracked.Add(1)
+				continue
+			}
+
+			entry = &correlation{}
+			c.index[key] = entry
+		}
+
+		entry.events = append(entry.events, summary)
+		if len(entry.events) > c.maxEvents {
+			entry.events = entry.events[len(entry.events)-c.maxEvents:]
+		}
+		entry.lastSeen = now
+	}
+}
+
+// indexKeys returns the keys of the trace and request IDs, spans are too narrow to correlate on
+func indexKeys(ids map[string]string) []string {
+	var keys []string
+
+	for _, kind := range []string{MetadataTraceID, MetadataRequestID} {
+		if id, ok := ids[kind]; ok {
+			keys = append(keys, kind+":"+id)
+		}
+	}
+
+	return keys
+}
+
+func setDefault(ids map[string]string, kind, value string) {
+	if _, ok := ids[kind]; !ok {
+		ids[kind] = value
+	}
+}
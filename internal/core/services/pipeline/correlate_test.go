@@ -0,0 +1,142 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelator_Extract(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   domain.CorrelateConfig
+		event    domain.LogEvent
+		expected map[string]interface{}
+	}{
+		{
+			name:     "ShouldPassEventsWithoutIDs",
+			event:    domain.LogEvent{Message: "server started"},
+			expected: nil,
+		},
+		{
+			name:     "ShouldReadCommonMetadataNames",
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"traceId": "abc", "x-request-id": "req-1"}},
+			expected: map[string]interface{}{"traceId": "abc", "x-request-id": "req-1", "trace_id": "abc", "request_id": "req-1"},
+		},
+		{
+			name:     "ShouldReadConfiguredFields",
+			config:   domain.CorrelateConfig{Fields: []string{"txn"}},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"txn": 42}},
+			expected: map[string]interface{}{"txn": 42, "request_id": "42"},
+		},
+		{
+			name:  "ShouldParseTraceparentInMessage",
+			event: domain.LogEvent{Message: "traceparent=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 GET /api"},
+			expected: map[string]interface{}{
+				"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":  "00f067aa0ba902b7",
+			},
+		},
+		{
+			name:     "ShouldParseKeyValuesInMessage",
+			event:    domain.LogEvent{Message: `payment failed request_id="r-9" trace_id: t-1`},
+			expected: map[string]interface{}{"trace_id": "t-1", "request_id": "r-9"},
+		},
+		{
+			name:     "ShouldPreferMetadataOverMessage",
+			event:    domain.LogEvent{Message: "request_id=other", Metadata: map[string]interface{}{"request_id": "r-1"}},
+			expected: map[string]interface{}{"request_id": "r-1"},
+		},
+		{
+			name:     "ShouldIgnoreLoneSpan",
+			event:    domain.LogEvent{Message: "span_id=s-1"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			correlator := pipeline.NewCorrelator(tt.config, newClock(time.Now()))
+
+			result := correlator.Process(tt.event)
+
+			require.Len(t, result, 1)
+			assert.Equal(t, tt.event.Message, result[0].Message)
+			if tt.expected == nil {
+				assert.Equal(t, tt.event.Metadata, result[0].Metadata)
+			} else {
+				assert.Equal(t, tt.expected, result[0].Metadata)
+			}
+		})
+	}
+}
+
+func TestCorrelator_ShouldAttachPrecedingEventsOfTheRequest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := newClock(start)
+	correlator := pipeline.NewCorrelator(domain.CorrelateConfig{Context: 2}, clock)
+
+	events := []domain.LogEvent{
+		{ID: "1", Timestamp: start, Source: domain.SOURCE_UNIX, Severity: domain.LOG_LEVEL_INFO, Message: "GET /cart request_id=r-1"},
+		{ID: "2", Timestamp: start.Add(time.Second), Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_INFO, Message: "request_id=r-2 other request"},
+		{ID: "3", Timestamp: start.Add(2 * time.Second), Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_DEBUG, Message: "cache miss", Metadata: map[string]interface{}{"request_id": "r-1"}},
+		{ID: "4", Timestamp: start.Add(3 * time.Second), Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_WARNING, Message: "slow query request_id=r-1"},
+	}
+	for _, event := range events {
+		result := correlator.Process(event)
+		require.Len(t, result, 1)
+		assert.NotContains(t, result[0].Metadata, pipeline.MetadataCorrelated)
+	}
+
+	result := correlator.Process(domain.LogEvent{ID: "5", Severity: domain.LOG_LEVEL_ERROR, Message: "checkout failed request_id=r-1"})
+
+	require.Len(t, result, 1)
+	assert.Equal(t, 2, result[0].Metadata[pipeline.MetadataCorrelatedCount])
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "3", "timestamp": start.Add(2 * time.Second).Format(time.RFC3339Nano), "source": domain.SOURCE_FILE, "severity": "DEBUG", "message": "cache miss"},
+		{"id": "4", "timestamp": start.Add(3 * time.Second).Format(time.RFC3339Nano), "source": domain.SOURCE_STDIN, "severity": "WARNING", "message": "slow query request_id=r-1"},
+	}, result[0].Metadata[pipeline.MetadataCorrelated])
+}
+
+func TestCorrelator_ShouldJoinTraceAndRequest(t *testing.T) {
+	correlator := pipeline.NewCorrelator(domain.CorrelateConfig{}, newClock(time.Now()))
+
+	correlator.Process(domain.LogEvent{ID: "1", Message: "trace_id=t-1 upstream call"})
+	correlator.Process(domain.LogEvent{ID: "2", Message: "request_id=r-1 handler"})
+	result := correlator.Process(domain.LogEvent{ID: "3", Severity: domain.LOG_LEVEL_FATAL, Message: "trace_id=t-1 request_id=r-1 crash"})
+
+	require.Len(t, result, 1)
+	assert.Equal(t, 2, result[0].Metadata[pipeline.MetadataCorrelatedCount])
+}
+
+func TestCorrelator_Window(t *testing.T) {
+	clock := newClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	correlator := pipeline.NewCorrelator(domain.CorrelateConfig{Window: time.Minute, MaxIDs: 1}, clock)
+
+	correlator.Process(domain.LogEvent{ID: "1", Message: "request_id=r-1 start"})
+	correlator.Process(domain.LogEvent{ID: "2", Message: "request_id=r-2 start"})
+
+	clock.Advance(2 * time.Minute)
+	result := correlator.Process(domain.LogEvent{ID: "3", Severity: domain.LOG_LEVEL_ERROR, Message: "request_id=r-1 failed"})
+	require.Len(t, result, 1)
+	assert.NotContains(t, result[0].Metadata, pipeline.MetadataCorrelated)
+
+	assert.Empty(t, correlator.Flush(clock.Now(), false))
+	assert.Equal(t, uint64(1), correlator.Stats()["ids"])
+
+	clock.Advance(2 * time.Minute)
+	correlator.Flush(clock.Now(), false)
+
+	assert.Equal(t, map[string]uint64{
+		"received":  3,
+		"extracted": 3,
+		"enriched":  0,
+		"untracked": 1,
+		"ids":       0,
+	}, correlator.Stats())
+	assert.Equal(t, domain.STAGE_CORRELATE, correlator.Name())
+}
@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"log-guardian/internal/core/domain"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataRepeatCount = "repeat_count"
+	MetadataFirstSeen   = "first_seen"
+	MetadataLastSeen    = "last_seen"
+
+	defaultDedupMaxKeys = 10000
+)
+
+// Dedup lets the first occurrence of an event through and suppresses its repetitions until
+// the window closes. Then a single event is released with the number of suppressed repetitions
+type Dedup struct {
+	window  time.Duration
+	fields  []string
+	maxKeys int
+	clock   domain.Clock
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+
+	received   atomic.Uint64
+	suppressed atomic.Uint64
+	untracked  atomic.Uint64
+}
+
+type dedupEntry struct {
+	firstSeen time.Time
+	lastSeen  time.Time
+	repeats   int
+	last      domain.LogEvent
+}
+
+func NewDedup(config domain.DedupConfig, clock domain.Clock) *Dedup {
+	maxKeys := config.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultDedupMaxKeys
+	}
+
+	return &Dedup{
+		window:  config.Window,
+		fields:  config.Fields,
+		maxKeys: maxKeys,
+		clock:   clock,
+		entries: make(map[string]*dedupEntry),
+	}
+}
+
+func (d *Dedup) Name() string {
+	return domain.STAGE_DEDUP
+}
+
+// Process passes the event when it opens a window and suppresses it otherwise
+func (d *Dedup) Process(event domain.LogEvent) []domain.LogEvent {
+	d.received.Add(1)
+
+	key := event.Fingerprint(d.fields...)
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[key]
+	if ok && now.Sub(entry.firstSeen) < d.window {
+		entry.repeats++
+		entry.lastSeen = now
+		entry.last = event
+		d.suppressed.Add(1)
+
+		return nil
+	}
+
+	// the window of the previous entry is over but was not flushed yet
+	var released []domain.LogEvent
+	if ok {
+		released = entry.release()
+		delete(d.entries, key)
+	}
+
+	if len(d.entries) >= d.maxKeys {
+		d.untracked.Add(1)
+		return append(released, event)
+	}
+
+	d.entries[key] = &dedupEntry{firstSeen: now, lastSeen: now}
+
+	return append(released, event)
+}
+
+// Flush releases the summary of the windows closed at now
+func (d *Dedup) Flush(now time.Time, final bool) []domain.LogEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var released []domain.LogEvent
+	for key, entry := range d.entries {
+		if !final && now.Sub(entry.firstSeen) < d.window {
+			continue
+		}
+
+		released = append(released, entry.release()...)
+		delete(d.entries, key)
+	}
+
+	sort.Slice(released, func(i, j int) bool {
+		return released[i].Timestamp.Before(released[j].Timestamp)
+	})
+
+	return released
+}
+
+func (d *Dedup) Stats() map[string]uint64 {
+	d.mu.Lock()
+	tracked := len(d.entries)
+	d.mu.Unlock()
+
+	return map[string]uint64{
+		"received":   d.received.Load(),
+		"suppressed": d.suppressed.Load(),
+		"untracked":  d.untracked.Load(),
+		"tracked":    uint64(tracked),
+	}
+}
+
+// release returns the last suppressed event carrying the repetition summary, nothing when
+// the event was not repeated
+func (e *dedupEntry) release() []domain.LogEvent {
+	if e.repeats == 0 {
+		return nil
+	}
+
+	return []domain.LogEvent{withMetadata(e.last, map[string]interface{}{
+		MetadataRepeatCount: e.repeats,
+		MetadataFirstSeen:   e.firstSeen.Format(time.RFC3339Nano),
+		MetadataLastSeen:    e.lastSeen.Format(time.RFC3339Nano),
+	})}
+}
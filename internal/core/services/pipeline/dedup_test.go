@@ -0,0 +1,153 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedup_Process(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("ShouldSuppressRepeatsWithinTheWindow", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute}, clock)
+
+		first := domain.LogEvent{ID: "1", Message: "request 42 failed from 10.0.0.1"}
+		assert.Equal(t, []domain.LogEvent{first}, dedup.Process(first))
+
+		clock.Advance(10 * time.Second)
+		assert.Empty(t, dedup.Process(domain.LogEvent{ID: "2", Message: "request 43 failed from 10.0.0.2"}))
+
+		clock.Advance(10 * time.Second)
+		assert.Empty(t, dedup.Process(domain.LogEvent{ID: "3", Message: "request 44 failed from 10.0.0.3"}))
+
+		// a different message is not a repeat
+		other := domain.LogEvent{ID: "4", Message: "disk full"}
+		assert.Equal(t, []domain.LogEvent{other}, dedup.Process(other))
+	})
+
+	t.Run("ShouldReleaseTheSummaryWhenTheWindowCloses", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute}, clock)
+
+		dedup.Process(domain.LogEvent{ID: "1", Message: "timeout after 30s"})
+		clock.Advance(5 * time.Second)
+		dedup.Process(domain.LogEvent{ID: "2", Message: "timeout after 31s", Metadata: map[string]interface{}{"pod": "a"}})
+		clock.Advance(5 * time.Second)
+		dedup.Process(domain.LogEvent{ID: "3", Message: "timeout after 32s", Metadata: map[string]interface{}{"pod": "b"}})
+
+		// the window is still open
+		assert.Empty(t, dedup.Flush(start.Add(30*time.Second), false))
+
+		released := dedup.Flush(start.Add(time.Minute), false)
+		require.Len(t, released, 1)
+
+		assert.Equal(t, "3", released[0].ID)
+		assert.Equal(t, "timeout after 32s", released[0].Message)
+		assert.Equal(t, map[string]interface{}{
+			"pod":                        "b",
+			pipeline.MetadataRepeatCount: 2,
+			pipeline.MetadataFirstSeen:   start.Format(time.RFC3339Nano),
+			pipeline.MetadataLastSeen:    start.Add(10 * time.Second).Format(time.RFC3339Nano),
+		}, released[0].Metadata)
+
+		// the window is reset, the next occurrence goes through
+		clock.Advance(time.Minute)
+		assert.Len(t, dedup.Process(domain.LogEvent{ID: "4", Message: "timeout after 33s"}), 1)
+	})
+
+	t.Run("ShouldNotReleaseWhenThereWereNoRepeats", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute}, clock)
+
+		dedup.Process(domain.LogEvent{ID: "1", Message: "single"})
+
+		assert.Empty(t, dedup.Flush(start.Add(2*time.Minute), false))
+		assert.Equal(t, uint64(0), dedup.Stats()["tracked"])
+	})
+
+	t.Run("ShouldReleaseTheExpiredWindowOnTheNextOccurrence", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute}, clock)
+
+		dedup.Process(domain.LogEvent{ID: "1", Message: "boom"})
+		dedup.Process(domain.LogEvent{ID: "2", Message: "boom"})
+
+		clock.Advance(2 * time.Minute)
+		result := dedup.Process(domain.LogEvent{ID: "3", Message: "boom"})
+
+		require.Len(t, result, 2)
+		assert.Equal(t, "2", result[0].ID)
+		assert.Equal(t, 1, result[0].Metadata[pipeline.MetadataRepeatCount])
+		assert.Equal(t, "3", result[1].ID)
+	})
+
+	t.Run("ShouldReleaseEverythingOnFinalFlush", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Hour}, clock)
+
+		dedup.Process(domain.LogEvent{ID: "1", Message: "boom"})
+		dedup.Process(domain.LogEvent{ID: "2", Message: "boom"})
+
+		assert.Len(t, dedup.Flush(start, true), 1)
+	})
+
+	t.Run("ShouldUseTheMetadataFieldsInTheFingerprint", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute, Fields: []string{"pod"}}, clock)
+
+		podA := domain.LogEvent{Message: "boom", Metadata: map[string]interface{}{"pod": "a"}}
+		podB := domain.LogEvent{Message: "boom", Metadata: map[string]interface{}{"pod": "b"}}
+
+		assert.Len(t, dedup.Process(podA), 1)
+		assert.Len(t, dedup.Process(podB), 1)
+		assert.Empty(t, dedup.Process(podA))
+	})
+
+	t.Run("ShouldPassWithoutTrackingWhenFull", func(t *testing.T) {
+		clock := newClock(start)
+		dedup := pipeline.NewDedup(domain.DedupConfig{Window: time.Minute, MaxKeys: 1}, clock)
+
+		dedup.Process(domain.LogEvent{Message: "first"})
+		assert.Len(t, dedup.Process(domain.LogEvent{Message: "second"}), 1)
+		assert.Len(t, dedup.Process(domain.LogEvent{Message: "second"}), 1)
+
+		assert.Equal(t, map[string]uint64{
+			"received":   3,
+			"suppressed": 0,
+			"untracked":  2,
+			"tracked":    1,
+		}, dedup.Stats())
+	})
+}
+
+func TestPipeline_Flush(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := newClock(start)
+
+	p, err := pipeline.Build(domain.PipelineConfig{
+		Name: "default",
+		Stages: []domain.StageConfig{
+			{Type: domain.STAGE_DEDUP, Dedup: &domain.DedupConfig{Window: time.Minute}},
+			{Type: domain.STAGE_FILTER, Filter: &domain.FilterConfig{
+				Exclude: []domain.FilterRule{{Metadata: []domain.MetadataMatch{{Key: "pod", Pattern: "noisy"}}}},
+			}},
+		},
+	}, clock, newIDGenerator(t), nil, nil)
+	require.NoError(t, err)
+
+	p.Process(domain.LogEvent{Message: "boom", Metadata: map[string]interface{}{"pod": "a"}})
+	p.Process(domain.LogEvent{Message: "boom", Metadata: map[string]interface{}{"pod": "a"}})
+	p.Process(domain.LogEvent{Message: "crash", Metadata: map[string]interface{}{"pod": "a"}})
+	p.Process(domain.LogEvent{Message: "crash", Metadata: map[string]interface{}{"pod": "noisy"}})
+
+	// the released events still go through the filter that follows the dedup
+	released := p.Flush(start.Add(time.Minute), false)
+	require.Len(t, released, 1)
+	assert.Equal(t, "boom", released[0].Message)
+}
@@ -0,0 +1,247 @@
+package pipeline
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQueueSize = 1000
+)
+
+var defaultWeights = map[domain.LogLevel]int{
+	domain.LOG_LEVEL_FATAL:   16,
+	domain.LOG_LEVEL_ERROR:   8,
+	domain.LOG_LEVEL_WARNING: 4,
+	domain.LOG_LEVEL_INFO:    2,
+	domain.LOG_LEVEL_DEBUG:   1,
+}
+
+// PriorityDispatcher keeps a bounded queue per severity and drains them with weighted round robin,
+// so the severe events are not stuck behind a flood of less important ones. The inputs read the
+// lines as INFO, so the level of these events is read from the line before they are queued
+type PriorityDispatcher struct {
+	capacity int
+	clock    domain.Clock
+	levels   []*levelQueue
+	byLevel  map[domain.LogLevel]*levelQueue
+	notify   chan struct{}
+
+	mu     sync.Mutex
+	size   int
+	cursor int
+	credit int
+}
+
+type levelQueue struct {
+	level  domain.LogLevel
+	weight int
+	limit  int
+	items  []queuedEvent
+
+	dispatched uint64
+	dropped    uint64
+	shed       uint64
+}
+
+type queuedEvent struct {
+	event    domain.LogEvent
+	queuedAt time.Time
+}
+
+func NewPriorityDispatcher(config domain.PriorityConfig, clock domain.Clock) *PriorityDispatcher {
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	levels := domain.LogLevels()
+
+	// a full level leaves as much room to the others before they shed it
+	capacity := config.Capacity
+	if capacity <= 0 {
+		capacity = queueSize * 2
+	}
+
+	d := &PriorityDispatcher{
+		capacity: capacity,
+		clock:    clock,
+		byLevel:  make(map[domain.LogLevel]*levelQueue, len(levels)),
+		notify:   make(chan struct{}, 1),
+	}
+
+	weights := config.LevelWeights()
+	for _, level := range levels {
+		weight, ok := weights[level]
+		if !ok {
+			weight = defaultWeights[level]
+		}
+
+		queue := &levelQueue{level: level, weight: weight, limit: queueSize}
+		d.levels = append(d.levels, queue)
+		d.byLevel[level] = queue
+	}
+
+	d.credit = d.levels[0].weight
+
+	return d
+}
+
+// Run moves the events from input to the queues and from the queues to output until the context is done
+func (d *PriorityDispatcher) Run(ctx context.Context, input <-chan domain.LogEvent, output chan<- domain.LogEvent) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-input:
+				d.Push(event)
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			event, ok := d.Pop(ctx)
+			if !ok {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case output <- event:
+			}
+		}
+	}()
+}
+
+// Push queues the event, shedding the oldest event of a lower severity when the dispatcher is full.
+// It returns false when the event itself is dropped
+func (d *PriorityDispatcher) Push(event domain.LogEvent) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queue := d.queueOf(event.Severity)
+	if len(queue.items) >= queue.limit {
+		queue.dropped++
+		return false
+	}
+
+	if d.size >= d.capacity && !d.shedBelow(queue.level) {
+		queue.dropped++
+		return false
+	}
+
+	queue.items = append(queue.items, queuedEvent{event: event, queuedAt: d.clock.Now()})
+	d.size++
+
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// Pop waits for the next event, it returns false when the context is done
+func (d *PriorityDispatcher) Pop(ctx context.Context) (domain.LogEvent, bool) {
+	for {
+		if event, ok := d.TryPop(); ok {
+			return event, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return domain.LogEvent{}, false
+		case <-d.notify:
+		}
+	}
+}
+
+// TryPop returns the next event by weighted round robin, false when every queue is empty
+func (d *PriorityDispatcher) TryPop() (domain.LogEvent, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size == 0 {
+		return domain.LogEvent{}, false
+	}
+
+	for {
+		queue := d.levels[d.cursor]
+		if d.credit > 0 && len(queue.items) > 0 {
+			item := queue.items[0]
+			queue.items[0] = queuedEvent{}
+			queue.items = queue.items[1:]
+			queue.dispatched++
+			d.size--
+			d.credit--
+
+			return item.event, true
+		}
+
+		d.cursor = (d.cursor + 1) % len(d.levels)
+		d.credit = d.levels[d.cursor].weight
+	}
+}
+
+// Stats returns the queue length, the age in milliseconds of the oldest queued event and the
+// counters of each level
+func (d *PriorityDispatcher) Stats() map[string]uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+	stats := make(map[string]uint64, len(d.levels)*5)
+
+	for _, queue := range d.levels {
+		prefix := strings.ToLower(string(queue.level)) + "."
+
+		var lag uint64
+		if len(queue.items) > 0 {
+			lag = uint64(now.Sub(queue.items[0].queuedAt).Milliseconds())
+		}
+
+		stats[prefix+"queued"] = uint64(len(queue.items))
+		stats[prefix+"lag_ms"] = lag
+		stats[prefix+"dispatched"] = queue.dispatched
+		stats[prefix+"dropped"] = queue.dropped
+		stats[prefix+"shed"] = queue.shed
+	}
+
+	return stats
+}
+
+// queueOf returns the queue of the level, unknown levels share the lowest priority queue
+func (d *PriorityDispatcher) queueOf(level domain.LogLevel) *levelQueue {
+	if queue, ok := d.byLevel[level]; ok {
+		return queue
+	}
+
+	return d.levels[len(d.levels)-1]
+}
+
+// shedBelow drops the oldest event of the least severe non empty queue below level
+func (d *PriorityDispatcher) shedBelow(level domain.LogLevel) bool {
+	for i := len(d.levels) - 1; i >= 0; i-- {
+		queue := d.levels[i]
+		if queue.level.Rank() >= level.Rank() {
+			return false
+		}
+
+		if len(queue.items) > 0 {
+			queue.items[0] = queuedEvent{}
+			queue.items = queue.items[1:]
+			queue.shed++
+			d.size--
+
+			return true
+		}
+	}
+
+	return false
+}
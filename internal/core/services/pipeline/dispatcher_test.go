@@ -0,0 +1,177 @@
+package pipeline_test
+
+import (
+	"context"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain(d *pipeline.PriorityDispatcher) []domain.LogLevel {
+	var levels []domain.LogLevel
+	for {
+		event, ok := d.TryPop()
+		if !ok {
+			return levels
+		}
+		levels = append(levels, event.Severity)
+	}
+}
+
+func TestPriorityDispatcher_TryPop(t *testing.T) {
+	t.Run("ShouldDrainByWeight", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{
+			Weights: map[domain.LogLevel]int{
+				domain.LOG_LEVEL_FATAL: 2,
+				domain.LOG_LEVEL_ERROR: 2,
+				domain.LOG_LEVEL_INFO:  1,
+			},
+		}, newClock(time.Now()))
+
+		for range 3 {
+			d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO})
+			d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR})
+		}
+		d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL})
+
+		assert.Equal(t, []domain.LogLevel{
+			domain.LOG_LEVEL_FATAL,
+			domain.LOG_LEVEL_ERROR,
+			domain.LOG_LEVEL_ERROR,
+			domain.LOG_LEVEL_INFO,
+			domain.LOG_LEVEL_ERROR,
+			domain.LOG_LEVEL_INFO,
+			domain.LOG_LEVEL_INFO,
+		}, drain(d))
+	})
+
+	t.Run("ShouldKeepTheOrderWithinALevel", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{}, newClock(time.Now()))
+
+		d.Push(domain.LogEvent{ID: "1", Severity: domain.LOG_LEVEL_INFO})
+		d.Push(domain.LogEvent{ID: "2", Severity: domain.LOG_LEVEL_INFO})
+
+		first, _ := d.TryPop()
+		second, _ := d.TryPop()
+		assert.Equal(t, "1", first.ID)
+		assert.Equal(t, "2", second.ID)
+	})
+
+	t.Run("ShouldQueueUnknownLevelsAsDebug", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{}, newClock(time.Now()))
+
+		d.Push(domain.LogEvent{ID: "unknown"})
+
+		event, ok := d.TryPop()
+		require.True(t, ok)
+		assert.Equal(t, "unknown", event.ID)
+		assert.Equal(t, uint64(1), d.Stats()["debug.dispatched"])
+	})
+}
+
+func TestPriorityDispatcher_Push(t *testing.T) {
+	t.Run("ShouldDropWhenTheLevelQueueIsFull", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{QueueSize: 2}, newClock(time.Now()))
+
+		assert.True(t, d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO}))
+		assert.True(t, d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO}))
+		assert.False(t, d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO}))
+		assert.True(t, d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR}))
+
+		stats := d.Stats()
+		assert.Equal(t, uint64(1), stats["info.dropped"])
+		assert.Equal(t, uint64(2), stats["info.queued"])
+	})
+
+	t.Run("ShouldShedWithTheDefaultCapacity", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{QueueSize: 2}, newClock(time.Now()))
+
+		for range 2 {
+			d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO})
+			d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_DEBUG})
+		}
+
+		assert.True(t, d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR}))
+
+		stats := d.Stats()
+		assert.Equal(t, uint64(1), stats["debug.shed"])
+		assert.Equal(t, uint64(1), stats["error.queued"])
+	})
+
+	t.Run("ShouldShedTheLowerSeveritiesWhenFull", func(t *testing.T) {
+		d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{QueueSize: 10, Capacity: 3}, newClock(time.Now()))
+
+		d.Push(domain.LogEvent{ID: "info", Severity: domain.LOG_LEVEL_INFO})
+		d.Push(domain.LogEvent{ID: "debug", Severity: domain.LOG_LEVEL_DEBUG})
+		d.Push(domain.LogEvent{ID: "warning", Severity: domain.LOG_LEVEL_WARNING})
+
+		// debug goes first, then info
+		assert.True(t, d.Push(domain.LogEvent{ID: "error", Severity: domain.LOG_LEVEL_ERROR}))
+		assert.True(t, d.Push(domain.LogEvent{ID: "fatal", Severity: domain.LOG_LEVEL_FATAL}))
+
+		// nothing below warning is left to shed
+		assert.False(t, d.Push(domain.LogEvent{ID: "other-warning", Severity: domain.LOG_LEVEL_WARNING}))
+
+		assert.Equal(t, []domain.LogLevel{
+			domain.LOG_LEVEL_FATAL,
+			domain.LOG_LEVEL_ERROR,
+			domain.LOG_LEVEL_WARNING,
+		}, drain(d))
+
+		stats := d.Stats()
+		assert.Equal(t, uint64(1), stats["debug.shed"])
+		assert.Equal(t, uint64(1), stats["info.shed"])
+		assert.Equal(t, uint64(1), stats["warning.dropped"])
+	})
+}
+
+func TestPriorityDispatcher_Stats(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := newClock(start)
+	d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{}, clock)
+
+	d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR})
+	clock.Advance(time.Second)
+	d.Push(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR})
+	clock.Advance(500 * time.Millisecond)
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(2), stats["error.queued"])
+	assert.Equal(t, uint64(1500), stats["error.lag_ms"])
+	assert.Equal(t, uint64(0), stats["info.lag_ms"])
+
+	d.TryPop()
+	stats = d.Stats()
+	assert.Equal(t, uint64(500), stats["error.lag_ms"])
+	assert.Equal(t, uint64(1), stats["error.dispatched"])
+}
+
+func TestPriorityDispatcher_Run(t *testing.T) {
+	d := pipeline.NewPriorityDispatcher(domain.PriorityConfig{}, newClock(time.Now()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := make(chan domain.LogEvent, 10)
+	output := make(chan domain.LogEvent)
+
+	d.Run(ctx, input, output)
+
+	input <- domain.LogEvent{ID: "1", Severity: domain.LOG_LEVEL_INFO}
+
+	select {
+	case event := <-output:
+		assert.Equal(t, "1", event.ID)
+	case <-time.After(time.Second):
+		t.Fatal("The event didn't arrive to the output")
+	}
+
+	cancel()
+
+	_, ok := d.Pop(ctx)
+	assert.False(t, ok)
+}
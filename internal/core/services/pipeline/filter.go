@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/expr"
+	"regexp"
+	"sync/atomic"
+)
+
+const (
+	dropReasonSeverity = "severity"
+	dropReasonInclude  = "not_included"
+)
+
+// Filter drops the events that don't satisfy the configured rules
+type Filter struct {
+	minSeverity domain.LogLevel
+	include     []filterRule
+	exclude     []filterRule
+
+	received atomic.Uint64
+	dropped  map[string]*atomic.Uint64
+}
+
+type filterRule struct {
+	name      string
+	message   *regexp.Regexp
+	source    string
+	metadata  []metadataMatch
+	condition *expr.Expression
+}
+
+type metadataMatch struct {
+	key string
+	re  *regexp.Regexp
+}
+
+// NewFilter compiles the filter rules, returning an error when a pattern is invalid
+func NewFilter(config domain.FilterConfig) (*Filter, error) {
+	f := &Filter{
+		minSeverity: config.MinSeverity,
+		dropped: map[string]*atomic.Uint64{
+			dropReasonSeverity: {},
+			dropReasonInclude:  {},
+		},
+	}
+
+	var err error
+
+	f.include, err = compileFilterRules(config.Include, "include")
+	if err != nil {
+		return nil, err
+	}
+
+	f.exclude, err = compileFilterRules(config.Exclude, "exclude")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range f.exclude {
+		f.dropped[rule.name] = &atomic.Uint64{}
+	}
+
+	return f, nil
+}
+
+func compileFilterRules(rules []domain.FilterRule, kind string) ([]filterRule, error) {
+	compiled := make([]filterRule, 0, len(rules))
+
+	for i, rule := range rules {
+		c := filterRule{
+			name:   rule.Name,
+			source: rule.Source,
+		}
+
+		if c.name == "" {
+			c.name = fmt.Sprintf("%s_%d", kind, i)
+		}
+
+		if rule.Message != "" {
+			re, err := regexp.Compile(rule.Message)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule %s: %w", domain.ErrInvalidStage, c.name, err)
+			}
+			c.message = re
+		}
+
+		for _, match := range rule.Metadata {
+			if match.Key == "" {
+				return nil, fmt.Errorf("%w: rule %s: metadata matches need a key", domain.ErrInvalidStage, c.name)
+			}
+
+			re, err := regexp.Compile(match.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule %s: metadata %s: %w", domain.ErrInvalidStage, c.name, match.Key, err)
+			}
+			c.metadata = append(c.metadata, metadataMatch{key: match.Key, re: re})
+		}
+
+		if rule.Condition != "" {
+			condition, err := expr.Compile(rule.Condition)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule %s: condition: %w", domain.ErrInvalidStage, c.name, err)
+			}
+			c.condition = condition
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+func (f *Filter) Name() string {
+	return domain.STAGE_FILTER
+}
+
+// Process returns the event when it passes the filter, nil otherwise
+func (f *Filter) Process(event domain.LogEvent) []domain.LogEvent {
+	f.received.Add(1)
+
+	if reason, drop := f.check(event); drop {
+		f.dropped[reason].Add(1)
+		return nil
+	}
+
+	return []domain.LogEvent{event}
+}
+
+// check returns the reason to drop the event
+func (f *Filter) check(event domain.LogEvent) (string, bool) {
+	if f.minSeverity != "" && event.Severity.Rank() < f.minSeverity.Rank() {
+		return dropReasonSeverity, true
+	}
+
+	if len(f.include) > 0 && !matchesAny(f.include, event) {
+		return dropReasonInclude, true
+	}
+
+	for _, rule := range f.exclude {
+		if rule.matches(event) {
+			return rule.name, true
+		}
+	}
+
+	return "", false
+}
+
+// Stats returns how many events were received and dropped, by reason
+func (f *Filter) Stats() map[string]uint64 {
+	stats := map[string]uint64{
+		"received": f.received.Load(),
+	}
+
+	var total uint64
+	for reason, counter := range f.dropped {
+		value := counter.Load()
+		stats["dropped."+reason] = value
+		total += value
+	}
+	stats["dropped"] = total
+
+	return stats
+}
+
+func matchesAny(rules []filterRule, event domain.LogEvent) bool {
+	for _, rule := range rules {
+		if rule.matches(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r filterRule) matches(event domain.LogEvent) bool {
+	if r.source != "" && r.source != event.Source {
+		return false
+	}
+
+	if r.message != nil && !r.message.MatchString(event.Message) {
+		return false
+	}
+
+	for _, match := range r.metadata {
+		value, ok := event.GetMetadata(match.key)
+		if !ok || !match.re.MatchString(fmt.Sprint(value)) {
+			return false
+		}
+	}
+
+	if r.condition != nil && !r.condition.Match(event) {
+		return false
+	}
+
+	return true
+}
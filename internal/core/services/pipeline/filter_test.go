@@ -0,0 +1,219 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Process(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   domain.FilterConfig
+		event    domain.LogEvent
+		expected bool
+	}{
+		{
+			name:     "ShouldKeepEverythingWithoutRules",
+			config:   domain.FilterConfig{},
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_DEBUG, Message: "hello"},
+			expected: true,
+		},
+		{
+			name:     "ShouldDropBelowMinSeverity",
+			config:   domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_WARNING},
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_INFO},
+			expected: false,
+		},
+		{
+			name:     "ShouldKeepAtMinSeverity",
+			config:   domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_WARNING},
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_WARNING},
+			expected: true,
+		},
+		{
+			name:     "ShouldKeepAboveMinSeverity",
+			config:   domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_WARNING},
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL},
+			expected: true,
+		},
+		{
+			name: "ShouldKeepWhenMessageMatchesInclude",
+			config: domain.FilterConfig{
+				Include: []domain.FilterRule{{Message: "timeout|refused"}},
+			},
+			event:    domain.LogEvent{Message: "connection refused"},
+			expected: true,
+		},
+		{
+			name: "ShouldDropWhenNoIncludeMatches",
+			config: domain.FilterConfig{
+				Include: []domain.FilterRule{{Message: "timeout"}, {Source: domain.SOURCE_UNIX}},
+			},
+			event:    domain.LogEvent{Source: domain.SOURCE_FILE, Message: "all good"},
+			expected: false,
+		},
+		{
+			name: "ShouldDropWhenMessageMatchesExclude",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{Message: "^GET /health"}},
+			},
+			event:    domain.LogEvent{Message: "GET /healthz 200"},
+			expected: false,
+		},
+		{
+			name: "ShouldDropBySource",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{Source: domain.SOURCE_STDIN}},
+			},
+			event:    domain.LogEvent{Source: domain.SOURCE_STDIN},
+			expected: false,
+		},
+		{
+			name: "ShouldDropWhenAllConditionsOfTheRuleMatch",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{
+					Source:   domain.SOURCE_FILE,
+					Message:  "debug",
+					Metadata: []domain.MetadataMatch{{Key: "namespace", Pattern: "^kube-"}},
+				}},
+			},
+			event: domain.LogEvent{
+				Source:   domain.SOURCE_FILE,
+				Message:  "debug info",
+				Metadata: map[string]interface{}{"namespace": "kube-system"},
+			},
+			expected: false,
+		},
+		{
+			name: "ShouldKeepWhenOnlySomeConditionsOfTheRuleMatch",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{
+					Source:   domain.SOURCE_FILE,
+					Metadata: []domain.MetadataMatch{{Key: "namespace", Pattern: "^kube-"}},
+				}},
+			},
+			event: domain.LogEvent{
+				Source:   domain.SOURCE_FILE,
+				Metadata: map[string]interface{}{"namespace": "prod"},
+			},
+			expected: true,
+		},
+		{
+			name: "ShouldKeepWhenMetadataFieldIsMissing",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{Metadata: []domain.MetadataMatch{{Key: "pod", Pattern: ".*"}}}},
+			},
+			event:    domain.LogEvent{},
+			expected: true,
+		},
+		{
+			name: "ShouldMatchNonStringMetadata",
+			config: domain.FilterConfig{
+				Include: []domain.FilterRule{{Metadata: []domain.MetadataMatch{{Key: "status", Pattern: "^5"}}}},
+			},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"status": 503}},
+			expected: true,
+		},
+		{
+			name: "ShouldKeepWhenConditionMatches",
+			config: domain.FilterConfig{
+				Include: []domain.FilterRule{{Condition: `severity >= ERROR && metadata.namespace == "prod"`}},
+			},
+			event: domain.LogEvent{
+				Severity: domain.LOG_LEVEL_FATAL,
+				Metadata: map[string]interface{}{"namespace": "prod"},
+			},
+			expected: true,
+		},
+		{
+			name: "ShouldDropWhenConditionMatchesExclude",
+			config: domain.FilterConfig{
+				Exclude: []domain.FilterRule{{Condition: `metadata.status < 400`}},
+			},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"status": 200}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := pipeline.NewFilter(tt.config)
+			require.NoError(t, err)
+
+			result := filter.Process(tt.event)
+
+			if tt.expected {
+				assert.Equal(t, []domain.LogEvent{tt.event}, result)
+			} else {
+				assert.Empty(t, result)
+			}
+		})
+	}
+}
+
+func TestNewFilter_InvalidPattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		config domain.FilterConfig
+	}{
+		{
+			name:   "invalid include message",
+			config: domain.FilterConfig{Include: []domain.FilterRule{{Message: "("}}},
+		},
+		{
+			name:   "invalid exclude metadata",
+			config: domain.FilterConfig{Exclude: []domain.FilterRule{{Metadata: []domain.MetadataMatch{{Key: "pod", Pattern: "["}}}}},
+		},
+		{
+			name:   "metadata without key",
+			config: domain.FilterConfig{Include: []domain.FilterRule{{Metadata: []domain.MetadataMatch{{Pattern: "^5"}}}}},
+		},
+		{
+			name:   "invalid condition",
+			config: domain.FilterConfig{Include: []domain.FilterRule{{Condition: "severity = ERROR"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := pipeline.NewFilter(tt.config)
+			assert.ErrorIs(t, err, domain.ErrInvalidStage)
+			assert.Nil(t, filter)
+		})
+	}
+}
+
+func TestFilter_Stats(t *testing.T) {
+	filter, err := pipeline.NewFilter(domain.FilterConfig{
+		MinSeverity: domain.LOG_LEVEL_INFO,
+		Include:     []domain.FilterRule{{Source: domain.SOURCE_FILE}, {Source: domain.SOURCE_UNIX}},
+		Exclude:     []domain.FilterRule{{Name: "health", Message: "health"}, {Message: "metrics"}},
+	})
+	require.NoError(t, err)
+
+	events := []domain.LogEvent{
+		{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_DEBUG},
+		{Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_INFO},
+		{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_INFO, Message: "GET /health"},
+		{Source: domain.SOURCE_UNIX, Severity: domain.LOG_LEVEL_INFO, Message: "GET /metrics"},
+		{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_ERROR, Message: "boom"},
+	}
+
+	for _, event := range events {
+		filter.Process(event)
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"received":             5,
+		"dropped":              4,
+		"dropped.severity":     1,
+		"dropped.not_included": 1,
+		"dropped.health":       1,
+		"dropped.exclude_1":    1,
+	}, filter.Stats())
+	assert.Equal(t, domain.STAGE_FILTER, filter.Name())
+}
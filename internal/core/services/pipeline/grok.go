@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"regexp"
+	"sync/atomic"
+)
+
+const (
+	MetadataGrokPattern = "grok_pattern"
+	MetadataGrokFailure = "grok_failure"
+
+	maxGrokDepth = 32
+)
+
+var regexGrokReference = regexp.MustCompile(`%\{(\w+)(?::([\w.\[\]@-]+))?(?::(\w+))?\}`)
+
+// Grok extracts fields from unstructured lines with named patterns
+type Grok struct {
+	patterns []grokPattern
+
+	received  atomic.Uint64
+	matched   atomic.Uint64
+	unmatched atomic.Uint64
+}
+
+type grokPattern struct {
+	source string
+	re     *regexp.Regexp
+	fields map[string]grokField
+}
+
+type grokField struct {
+	name   string
+	coerce string
+}
+
+// NewGrok compiles the patterns, returning an error for unknown references, types or invalid regexps
+func NewGrok(config domain.GrokConfig) (*Grok, error) {
+	definitions := make(map[string]string, len(grokPatterns)+len(config.Definitions))
+	for name, pattern := range grokPatterns {
+		definitions[name] = pattern
+	}
+	for _, definition := range config.Definitions {
+		definitions[definition.Name] = definition.Pattern
+	}
+
+	g := &Grok{}
+	for _, source := range config.Patterns {
+		pattern, err := compileGrok(source, definitions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: grok pattern %q: %w", domain.ErrInvalidStage, source, err)
+		}
+
+		g.patterns = append(g.patterns, pattern)
+	}
+
+	return g, nil
+}
+
+func compileGrok(source string, definitions map[string]string) (grokPattern, error) {
+	pattern := grokPattern{source: source, fields: make(map[string]grokField)}
+
+	expanded, err := expandGrok(source, definitions, pattern.fields, 0)
+	if err != nil {
+		return pattern, err
+	}
+
+	pattern.re, err = regexp.Compile(expanded)
+	if err != nil {
+		return pattern, err
+	}
+
+	return pattern, nil
+}
+
+// expandGrok replaces the references by their definition. Captures become groups named by their
+// position, since the field names may hold characters not allowed in group names
+func expandGrok(pattern string, definitions map[string]string, fields map[string]grokField, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("too deep, check for recursive definitions")
+	}
+
+	var expandErr error
+	expanded := regexGrokReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+		if expandErr != nil {
+			return ""
+		}
+
+		parts := regexGrokReference.FindStringSubmatch(reference)
+		name, field, coerce := parts[1], parts[2], parts[3]
+
+		definition, ok := definitions[name]
+		if !ok {
+			expandErr = fmt.Errorf("unknown pattern %s", name)
+			return ""
+		}
+
+		switch coerce {
+		case "", "int", "float", "bool", "string":
+		default:
+			expandErr = fmt.Errorf("unknown type %s of field %s", coerce, field)
+			return ""
+		}
+
+		inner, err := expandGrok(definition, definitions, fields, depth+1)
+		if err != nil {
+			expandErr = err
+			return ""
+		}
+
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+
+		group := fmt.Sprintf("g%d", len(fields))
+		fields[group] = grokField{name: field, coerce: coerce}
+
+		return "(?P<" + group + ">" + inner + ")"
+	})
+
+	return expanded, expandErr
+}
+
+func (g *Grok) Name() string {
+	return domain.STAGE_GROK
+}
+
+// Process adds the fields captured by the first matching pattern, the unmatched events go on
+// tagged with grok_failure
+func (g *Grok) Process(event domain.LogEvent) []domain.LogEvent {
+	g.received.Add(1)
+
+	for _, pattern := range g.patterns {
+		fields, ok := pattern.parse(event.Message)
+		if !ok {
+			continue
+		}
+
+		g.matched.Add(1)
+		fields[MetadataGrokPattern] = pattern.source
+
+		return []domain.LogEvent{withMetadata(event, fields)}
+	}
+
+	g.unmatched.Add(1)
+
+	return []domain.LogEvent{withMetadata(event, map[string]interface{}{MetadataGrokFailure: true})}
+}
+
+func (g *Grok) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"received":  g.received.Load(),
+		"matched":   g.matched.Load(),
+		"unmatched": g.unmatched.Load(),
+	}
+}
+
+func (p grokPattern) parse(text string) (map[string]interface{}, bool) {
+	match := p.re.FindStringSubmatch(text)
+	if match == nil {
+		return nil, false
+	}
+
+	fields := make(map[string]interface{}, len(p.fields))
+	for i, group := range p.re.SubexpNames() {
+		field, ok := p.fields[group]
+		if !ok || match[i] == "" {
+			continue
+		}
+
+		fields[field.name] = coerce(match[i], field.coerce)
+	}
+
+	return fields, true
+}
+
+// coerce converts the captured text, keeping the text when the conversion fails
+func coerce(value, kind string) interface{} {
+	if kind == "" {
+		return value
+	}
+
+	if converted, err := castValue(value, kind); err == nil {
+		return converted
+	}
+
+	return value
+}
@@ -0,0 +1,191 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrok_Process(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   domain.GrokConfig
+		message  string
+		expected map[string]interface{}
+	}{
+		{
+			name:    "combined apache log",
+			config:  domain.GrokConfig{Patterns: []string{"%{COMBINEDAPACHELOG}"}},
+			message: `203.0.113.9 - - [10/Oct/2025:13:55:36 -0700] "GET /api/orders?id=7 HTTP/1.1" 502 1534 "https://shop.example.com/" "curl/8.4.0"`,
+			expected: map[string]interface{}{
+				"clientip":    "203.0.113.9",
+				"ident":       "-",
+				"auth":        "-",
+				"timestamp":   "10/Oct/2025:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/api/orders?id=7",
+				"httpversion": "1.1",
+				"response":    502,
+				"bytes":       1534,
+				"referrer":    `"https://shop.example.com/"`,
+				"agent":       `"curl/8.4.0"`,
+			},
+		},
+		{
+			name:    "nginx error log",
+			config:  domain.GrokConfig{Patterns: []string{"%{NGINXERROR}"}},
+			message: `2025/10/10 13:55:36 [error] 31#31: *1 connect() failed (111: Connection refused) while connecting to upstream`,
+			expected: map[string]interface{}{
+				"timestamp":    "2025/10/10 13:55:36",
+				"level":        "error",
+				"pid":          31,
+				"tid":          31,
+				"errormessage": "*1 connect() failed (111: Connection refused) while connecting to upstream",
+			},
+		},
+		{
+			name:    "type coercion",
+			config:  domain.GrokConfig{Patterns: []string{`took %{NUMBER:duration:float}ms cached=%{WORD:cached:bool} from %{IP:client}`}},
+			message: "took 12.5ms cached=true from 10.0.0.1",
+			expected: map[string]interface{}{
+				"duration": 12.5,
+				"cached":   true,
+				"client":   "10.0.0.1",
+			},
+		},
+		{
+			name:    "failed coercion keeps the text",
+			config:  domain.GrokConfig{Patterns: []string{`status=%{NOTSPACE:status:int}`}},
+			message: "status=abc",
+			expected: map[string]interface{}{
+				"status": "abc",
+			},
+		},
+		{
+			name: "user definitions",
+			config: domain.GrokConfig{
+				Patterns:    []string{`%{ORDER:order} %{GREEDYDATA:rest}`},
+				Definitions: []domain.GrokDefinition{{Name: "ORDER", Pattern: `ORD-%{INT}`}},
+			},
+			message: "ORD-1234 shipped",
+			expected: map[string]interface{}{
+				"order": "ORD-1234",
+				"rest":  "shipped",
+			},
+		},
+		{
+			name: "first matching pattern wins",
+			config: domain.GrokConfig{Patterns: []string{
+				`^%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:msg}`,
+				`^%{LOGLEVEL:level} %{GREEDYDATA:msg}`,
+			}},
+			message: "WARN disk almost full",
+			expected: map[string]interface{}{
+				"level": "WARN",
+				"msg":   "disk almost full",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grok, err := pipeline.NewGrok(tt.config)
+			require.NoError(t, err)
+
+			result := grok.Process(domain.LogEvent{Message: tt.message})
+			require.Len(t, result, 1)
+
+			metadata := result[0].Metadata
+			assert.Contains(t, tt.config.Patterns, metadata[pipeline.MetadataGrokPattern])
+			delete(metadata, pipeline.MetadataGrokPattern)
+
+			assert.Equal(t, tt.expected, metadata)
+			assert.Equal(t, tt.message, result[0].Message)
+		})
+	}
+}
+
+func TestGrok_Unmatched(t *testing.T) {
+	grok, err := pipeline.NewGrok(domain.GrokConfig{Patterns: []string{"^%{IP:client} "}})
+	require.NoError(t, err)
+
+	event := domain.LogEvent{Message: "not an access log"}
+	result := grok.Process(event)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, true, result[0].Metadata[pipeline.MetadataGrokFailure])
+
+	grok.Process(domain.LogEvent{Message: "10.0.0.1 GET /"})
+
+	assert.Equal(t, map[string]uint64{
+		"received":  2,
+		"matched":   1,
+		"unmatched": 1,
+	}, grok.Stats())
+}
+
+func TestNewGrok_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config domain.GrokConfig
+	}{
+		{
+			name:   "unknown pattern",
+			config: domain.GrokConfig{Patterns: []string{"%{NOPE:field}"}},
+		},
+		{
+			name:   "unknown type",
+			config: domain.GrokConfig{Patterns: []string{"%{INT:field:date}"}},
+		},
+		{
+			name: "recursive definition",
+			config: domain.GrokConfig{
+				Patterns:    []string{"%{LOOP}"},
+				Definitions: []domain.GrokDefinition{{Name: "LOOP", Pattern: "a%{LOOP}"}},
+			},
+		},
+		{
+			name:   "invalid regexp",
+			config: domain.GrokConfig{Patterns: []string{"%{INT:field}("}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grok, err := pipeline.NewGrok(tt.config)
+			assert.ErrorIs(t, err, domain.ErrInvalidStage)
+			assert.Nil(t, grok)
+		})
+	}
+}
+
+func TestGrok_Library(t *testing.T) {
+	samples := map[string]string{
+		"IP":                "2001:db8::1",
+		"IPV4":              "192.168.0.1",
+		"NUMBER":            "-3.14",
+		"UUID":              "3f2b1c4e-1a2b-4c3d-8e9f-0a1b2c3d4e5f",
+		"HTTPDATE":          "10/Oct/2025:13:55:36 -0700",
+		"TIMESTAMP_ISO8601": "2025-10-10T13:55:36.123Z",
+		"SYSLOGTIMESTAMP":   "Oct  9 13:55:36",
+		"URI":               "https://user@example.com:8443/path?q=1",
+		"EMAIL":             "jane@example.com",
+		"DATESTAMP":         "10/10/2025 13:55:36",
+		"HOSTPORT":          "db.internal:5432",
+		"QS":                `"quoted \"value\""`,
+		"SYSLOGLINE":        "Oct  9 13:55:36 web-1 sshd[812]: Accepted publickey",
+	}
+
+	for name, sample := range samples {
+		t.Run(name, func(t *testing.T) {
+			grok, err := pipeline.NewGrok(domain.GrokConfig{Patterns: []string{"^%{" + name + ":value}$"}})
+			require.NoError(t, err)
+
+			result := grok.Process(domain.LogEvent{Message: sample})
+			assert.Equal(t, sample, result[0].Metadata["value"])
+		})
+	}
+}
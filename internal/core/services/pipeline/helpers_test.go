@@ -0,0 +1,43 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+// fakeClock is a manually advanced clock for the time based stages
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newIDGenerator(t *testing.T) domain.IDGenerator {
+	ctrl := gomock.NewController(t)
+
+	idGen := domain.NewMockIDGenerator(ctrl)
+	idGen.EXPECT().Generate().AnyTimes().Return("generated-id", nil)
+
+	return idGen
+}
@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"time"
+)
+
+// Pipeline runs the events through a chain of stages
+type Pipeline struct {
+	name   string
+	stages []ports.Stage
+}
+
+func NewPipeline(name string, stages ...ports.Stage) *Pipeline {
+	return &Pipeline{
+		name:   name,
+		stages: stages,
+	}
+}
+
+func (p *Pipeline) Name() string {
+	return p.name
+}
+
+// Process passes the event through every stage, feeding each stage with the output of the previous one
+func (p *Pipeline) Process(event domain.LogEvent) []domain.LogEvent {
+	return p.run(0, []domain.LogEvent{event})
+}
+
+// Flush releases the events held by the stages, running them through the stages that follow
+func (p *Pipeline) Flush(now time.Time, final bool) []domain.LogEvent {
+	var events []domain.LogEvent
+
+	for i, stage := range p.stages {
+		flusher, ok := stage.(ports.Flusher)
+		if !ok {
+			continue
+		}
+
+		if released := flusher.Flush(now, final); len(released) > 0 {
+			events = append(events, p.run(i+1, released)...)
+		}
+	}
+
+	return events
+}
+
+// run feeds the events to the stages starting at the given index
+func (p *Pipeline) run(from int, events []domain.LogEvent) []domain.LogEvent {
+	for _, stage := range p.stages[from:] {
+		var next []domain.LogEvent
+		for _, e := range events {
+			next = append(next, stage.Process(e)...)
+		}
+
+		if len(next) == 0 {
+			return nil
+		}
+		events = next
+	}
+
+	return events
+}
+
+// Stats returns the counters of the stages prefixed by the stage name
+func (p *Pipeline) Stats() map[string]uint64 {
+	stats := make(map[string]uint64)
+
+	for _, stage := range p.stages {
+		provider, ok := stage.(ports.StatsProvider)
+		if !ok {
+			continue
+		}
+
+		for key, value := range provider.Stats() {
+			stats[stage.Name()+"."+key] += value
+		}
+	}
+
+	return stats
+}
+
+// withMetadata returns a copy of the event with the fields added, the metadata map of the
+// original event is left untouched since it may be shared with other pipelines
+func withMetadata(event domain.LogEvent, fields map[string]interface{}) domain.LogEvent {
+	metadata := make(map[string]interface{}, len(event.Metadata)+len(fields))
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+
+	for key, value := range fields {
+		metadata[key] = value
+	}
+	event.Metadata = metadata
+
+	return event
+}
@@ -0,0 +1,127 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPipeline_Process(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	event := domain.LogEvent{ID: "1", Message: "hello"}
+
+	t.Run("ShouldReturnTheEventWithoutStages", func(t *testing.T) {
+		p := pipeline.NewPipeline("empty")
+
+		assert.Equal(t, "empty", p.Name())
+		assert.Equal(t, []domain.LogEvent{event}, p.Process(event))
+	})
+
+	t.Run("ShouldFeedEachStageWithThePreviousOutput", func(t *testing.T) {
+		split := ports.NewMockStage(ctrl)
+		split.EXPECT().Process(event).Return([]domain.LogEvent{{ID: "1"}, {ID: "2"}})
+
+		tag := ports.NewMockStage(ctrl)
+		tag.EXPECT().Process(gomock.Any()).Times(2).DoAndReturn(func(e domain.LogEvent) []domain.LogEvent {
+			e.AddMetadata("tagged", "true")
+			return []domain.LogEvent{e}
+		})
+
+		result := pipeline.NewPipeline("split", split, tag).Process(event)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, "1", result[0].ID)
+		assert.Equal(t, "2", result[1].ID)
+		assert.Equal(t, "true", result[1].Metadata["tagged"])
+	})
+
+	t.Run("ShouldStopWhenAStageDropsTheEvent", func(t *testing.T) {
+		drop := ports.NewMockStage(ctrl)
+		drop.EXPECT().Process(event).Return(nil)
+
+		// the next stage must not be called
+		next := ports.NewMockStage(ctrl)
+
+		assert.Empty(t, pipeline.NewPipeline("drop", drop, next).Process(event))
+	})
+}
+
+func TestPipeline_Stats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	filter, err := pipeline.NewFilter(domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_ERROR})
+	require.NoError(t, err)
+
+	// stages without counters are ignored
+	plain := ports.NewMockStage(ctrl)
+	plain.EXPECT().Process(gomock.Any()).AnyTimes().DoAndReturn(func(e domain.LogEvent) []domain.LogEvent {
+		return []domain.LogEvent{e}
+	})
+
+	p := pipeline.NewPipeline("errors", plain, filter)
+	p.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO})
+	p.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR})
+
+	stats := p.Stats()
+	assert.Equal(t, uint64(2), stats["filter.received"])
+	assert.Equal(t, uint64(1), stats["filter.dropped"])
+	assert.Equal(t, uint64(1), stats["filter.dropped.severity"])
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("ShouldBuildTheStages", func(t *testing.T) {
+		p, err := pipeline.Build(domain.PipelineConfig{
+			Name: "default",
+			Stages: []domain.StageConfig{
+				{Type: domain.STAGE_FILTER, Filter: &domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_WARNING}},
+			},
+		}, newClock(time.Now()), newIDGenerator(t), nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "default", p.Name())
+		assert.Empty(t, p.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO}))
+		assert.Len(t, p.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR}), 1)
+	})
+
+	t.Run("ShouldBuildTheSampler", func(t *testing.T) {
+		p, err := pipeline.Build(domain.PipelineConfig{
+			Name: "sampled",
+			Stages: []domain.StageConfig{
+				{Type: domain.STAGE_SAMPLE, Sample: &domain.SampleConfig{Mode: domain.SAMPLE_MODE_FIRST_N, Size: 1}},
+			},
+		}, newClock(time.Now()), newIDGenerator(t), nil, nil)
+		require.NoError(t, err)
+
+		assert.Len(t, p.Process(domain.LogEvent{Message: "hello"}), 1)
+		assert.Empty(t, p.Process(domain.LogEvent{Message: "hello"}))
+	})
+
+	t.Run("ShouldFailWithInvalidConfig", func(t *testing.T) {
+		_, err := pipeline.Build(domain.PipelineConfig{
+			Name:   "default",
+			Stages: []domain.StageConfig{{Type: "unknown"}},
+		}, newClock(time.Now()), newIDGenerator(t), nil, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidPipeline)
+		assert.ErrorIs(t, err, domain.ErrInvalidStage)
+	})
+
+	t.Run("ShouldFailWithInvalidPattern", func(t *testing.T) {
+		_, err := pipeline.Build(domain.PipelineConfig{
+			Name: "default",
+			Stages: []domain.StageConfig{
+				{Type: domain.STAGE_FILTER, Filter: &domain.FilterConfig{Include: []domain.FilterRule{{Message: "("}}}},
+			},
+		}, newClock(time.Now()), newIDGenerator(t), nil, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidPipeline)
+		assert.ErrorIs(t, err, domain.ErrInvalidStage)
+	})
+}
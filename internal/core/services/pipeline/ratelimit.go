@@ -0,0 +1,211 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataLimitKey        = "rate_limit_key"
+	MetadataSuppressedCount = "suppressed_count"
+
+	defaultSummaryInterval = time.Minute
+	defaultLimitMaxKeys    = 10000
+	unknownLimitKey        = "unknown"
+)
+
+// RateLimiter applies a token bucket per key. The events over the limit are dropped and reported
+// by a synthetic summary event, so a noisy source cannot starve the others unnoticed
+type RateLimiter struct {
+	keyBy    string
+	rate     float64
+	burst    float64
+	interval time.Duration
+	maxKeys  int
+	clock    domain.Clock
+	idGen    domain.IDGenerator
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	received   atomic.Uint64
+	suppressed atomic.Uint64
+	summaries  atomic.Uint64
+}
+
+type bucket struct {
+	tokens     float64
+	updated    time.Time
+	suppressed int
+	since      time.Time
+	source     string
+}
+
+func NewRateLimiter(config domain.LimitConfig, clock domain.Clock, idGen domain.IDGenerator) *RateLimiter {
+	interval := config.SummaryInterval
+	if interval == 0 {
+		interval = defaultSummaryInterval
+	}
+
+	maxKeys := config.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultLimitMaxKeys
+	}
+
+	return &RateLimiter{
+		keyBy:    config.KeyBy,
+		rate:     config.Rate,
+		burst:    float64(config.Burst),
+		interval: interval,
+		maxKeys:  maxKeys,
+		clock:    clock,
+		idGen:    idGen,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+func (r *RateLimiter) Name() string {
+	return domain.STAGE_LIMIT
+}
+
+// Process passes the event when its key has tokens left
+func (r *RateLimiter) Process(event domain.LogEvent) []domain.LogEvent {
+	r.received.Add(1)
+
+	key := r.key(event)
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= r.maxKeys {
+			return []domain.LogEvent{event}
+		}
+
+		b = &bucket{tokens: r.burst, updated: now}
+		r.buckets[key] = b
+	}
+
+	b.tokens = math.Min(r.burst, b.tokens+now.Sub(b.updated).Seconds()*r.rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return []domain.LogEvent{event}
+	}
+
+	if b.suppressed == 0 {
+		b.since = now
+		b.source = event.Source
+	}
+	b.suppressed++
+	r.suppressed.Add(1)
+
+	return nil
+}
+
+// Flush emits the summary of the keys suppressed for a whole interval, and forgets the full buckets
+func (r *RateLimiter) Flush(now time.Time, final bool) []domain.LogEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]string, 0, len(r.buckets))
+	for key := range r.buckets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var summaries []domain.LogEvent
+	for _, key := range keys {
+		b := r.buckets[key]
+
+		if b.suppressed > 0 && (final || now.Sub(b.since) >= r.interval) {
+			if summary, err := r.summary(key, b, now); err == nil {
+				summaries = append(summaries, summary)
+			}
+			b.suppressed = 0
+		}
+
+		refilled := b.tokens+now.Sub(b.updated).Seconds()*r.rate >= r.burst
+		if b.suppressed == 0 && refilled {
+			delete(r.buckets, key)
+		}
+	}
+
+	return summaries
+}
+
+func (r *RateLimiter) summary(key string, b *bucket, now time.Time) (domain.LogEvent, error) {
+	elapsed := now.Sub(b.since).Round(time.Second)
+	message := fmt.Sprintf("suppressed %s events from %s in %ds", formatCount(b.suppressed), key, int(elapsed.Seconds()))
+
+	metadata := map[string]interface{}{
+		MetadataLimitKey:        key,
+		MetadataSuppressedCount: b.suppressed,
+		MetadataFirstSeen:       b.since.Format(time.RFC3339Nano),
+		MetadataLastSeen:        now.Format(time.RFC3339Nano),
+	}
+
+	event, err := domain.NewLogEvent(b.source, message, domain.LOG_LEVEL_WARNING, metadata, r.idGen)
+	if err != nil {
+		return domain.LogEvent{}, err
+	}
+	event.Timestamp = now
+	r.summaries.Add(1)
+
+	return *event, nil
+}
+
+func (r *RateLimiter) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"received":   r.received.Load(),
+		"suppressed": r.suppressed.Load(),
+		"summaries":  r.summaries.Load(),
+	}
+}
+
+func (r *RateLimiter) key(event domain.LogEvent) string {
+	var value any
+
+	switch {
+	case r.keyBy == domain.LIMIT_KEY_SOURCE:
+		value = event.Source
+	case r.keyBy == domain.LIMIT_KEY_INPUT:
+		value, _ = event.GetMetadata(domain.METADATA_INPUT)
+		if value == nil {
+			value = event.Source
+		}
+	default:
+		value, _ = event.GetMetadata(strings.TrimPrefix(r.keyBy, domain.LIMIT_KEY_METADATA))
+	}
+
+	if value == nil || value == "" {
+		return unknownLimitKey
+	}
+
+	return fmt.Sprint(value)
+}
+
+// formatCount writes the number with thousands separators
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Process(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("ShouldAllowTheBurstAndRefillOverTime", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: domain.LIMIT_KEY_SOURCE, Rate: 2, Burst: 3}, clock, newIDGenerator(t))
+
+		event := domain.LogEvent{Source: domain.SOURCE_FILE}
+		for range 3 {
+			assert.Len(t, limiter.Process(event), 1)
+		}
+		assert.Empty(t, limiter.Process(event))
+
+		// two tokens per second
+		clock.Advance(time.Second)
+		assert.Len(t, limiter.Process(event), 1)
+		assert.Len(t, limiter.Process(event), 1)
+		assert.Empty(t, limiter.Process(event))
+	})
+
+	t.Run("ShouldLimitEachKeyApart", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: "metadata.pod", Rate: 1, Burst: 1}, clock, newIDGenerator(t))
+
+		noisy := domain.LogEvent{Metadata: map[string]interface{}{"pod": "noisy"}}
+		quiet := domain.LogEvent{Metadata: map[string]interface{}{"pod": "quiet"}}
+
+		assert.Len(t, limiter.Process(noisy), 1)
+		assert.Empty(t, limiter.Process(noisy))
+		assert.Len(t, limiter.Process(quiet), 1)
+	})
+
+	t.Run("ShouldKeyByInputFallingBackToTheSource", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: domain.LIMIT_KEY_INPUT, Rate: 1, Burst: 1}, clock, newIDGenerator(t))
+
+		appLog := domain.LogEvent{Source: domain.SOURCE_FILE, Metadata: map[string]interface{}{domain.METADATA_INPUT: "/var/log/app.log"}}
+		otherLog := domain.LogEvent{Source: domain.SOURCE_FILE, Metadata: map[string]interface{}{domain.METADATA_INPUT: "/var/log/other.log"}}
+		stdin := domain.LogEvent{Source: domain.SOURCE_STDIN}
+
+		assert.Len(t, limiter.Process(appLog), 1)
+		assert.Len(t, limiter.Process(otherLog), 1)
+		assert.Len(t, limiter.Process(stdin), 1)
+		assert.Empty(t, limiter.Process(stdin))
+	})
+
+	t.Run("ShouldPassWhenTooManyKeysAreTracked", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: "metadata.pod", Rate: 1, Burst: 1, MaxKeys: 1}, clock, newIDGenerator(t))
+
+		limiter.Process(domain.LogEvent{Metadata: map[string]interface{}{"pod": "a"}})
+		for range 3 {
+			assert.Len(t, limiter.Process(domain.LogEvent{Metadata: map[string]interface{}{"pod": "b"}}), 1)
+		}
+	})
+}
+
+func TestRateLimiter_Flush(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("ShouldSummarizeTheSuppressedEvents", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{
+			KeyBy:           "metadata.pod",
+			Rate:            0.001,
+			Burst:           1,
+			SummaryInterval: time.Minute,
+		}, clock, newIDGenerator(t))
+
+		event := domain.LogEvent{Source: domain.SOURCE_FILE, Metadata: map[string]interface{}{"pod": "checkout-7d9"}}
+		for range 12305 {
+			limiter.Process(event)
+		}
+
+		assert.Empty(t, limiter.Flush(start.Add(30*time.Second), false))
+
+		summaries := limiter.Flush(start.Add(time.Minute), false)
+		require.Len(t, summaries, 1)
+
+		summary := summaries[0]
+		assert.Equal(t, "generated-id", summary.ID)
+		assert.Equal(t, domain.SOURCE_FILE, summary.Source)
+		assert.Equal(t, domain.LOG_LEVEL_WARNING, summary.Severity)
+		assert.Equal(t, "suppressed 12,304 events from checkout-7d9 in 60s", summary.Message)
+		assert.Equal(t, "checkout-7d9", summary.Metadata[pipeline.MetadataLimitKey])
+		assert.Equal(t, 12304, summary.Metadata[pipeline.MetadataSuppressedCount])
+
+		// the counter starts over
+		assert.Empty(t, limiter.Flush(start.Add(2*time.Minute), false))
+
+		assert.Equal(t, map[string]uint64{
+			"received":   12305,
+			"suppressed": 12304,
+			"summaries":  1,
+		}, limiter.Stats())
+	})
+
+	t.Run("ShouldSummarizeEverythingOnFinalFlush", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: domain.LIMIT_KEY_SOURCE, Rate: 1, Burst: 1}, clock, newIDGenerator(t))
+
+		limiter.Process(domain.LogEvent{Source: domain.SOURCE_STDIN})
+		limiter.Process(domain.LogEvent{Source: domain.SOURCE_STDIN})
+
+		summaries := limiter.Flush(start.Add(time.Second), true)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, "suppressed 1 events from stdin in 1s", summaries[0].Message)
+	})
+
+	t.Run("ShouldForgetTheRefilledBuckets", func(t *testing.T) {
+		clock := newClock(start)
+		limiter := pipeline.NewRateLimiter(domain.LimitConfig{KeyBy: domain.LIMIT_KEY_SOURCE, Rate: 1, Burst: 1}, clock, newIDGenerator(t))
+
+		limiter.Process(domain.LogEvent{Source: domain.SOURCE_STDIN})
+		limiter.Flush(start.Add(time.Minute), false)
+
+		// a forgotten bucket starts full
+		clock.Advance(time.Minute)
+		assert.Len(t, limiter.Process(domain.LogEvent{Source: domain.SOURCE_STDIN}), 1)
+	})
+}
@@ -0,0 +1,249 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// detector finds a kind of sensitive value. When the pattern has a capture group only the
+// group is replaced, so "Bearer <token>" keeps the scheme. When set, spans returns the parts of
+// the match holding a valid value, nil when there is none
+type detector struct {
+	name  string
+	re    *regexp.Regexp
+	spans func(match string) [][2]int
+}
+
+// builtinDetectors run in order, the most specific first so a JWT is not taken as an API key
+var builtinDetectors = []detector{
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{name: "bearer", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{8,}=*)`)},
+	{name: "api_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bxox[abprs]-[A-Za-z0-9-]{10,}\b|\b[sr]k_live_[A-Za-z0-9]{16,}\b|\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "email", re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{name: "credit_card", re: regexp.MustCompile(`\b(?:\d[ -]?){12,}\d\b`), spans: cardNumbers},
+	{name: "ipv6", re: regexp.MustCompile(`(?:^|[^\w:])(` + domain.IPV6_PATTERN + `)`)},
+	{name: "ipv4", re: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+}
+
+// Redactor replaces the sensitive values of the message and the string metadata
+type Redactor struct {
+	mode      string
+	detectors []detector
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewRedactor selects the detectors, returning an error for unknown detectors or invalid patterns
+func NewRedactor(config domain.RedactConfig) (*Redactor, error) {
+	mode := config.Mode
+	if mode == "" {
+		mode = domain.REDACT_MODE_MASK
+	}
+
+	r := &Redactor{
+		mode:   mode,
+		counts: make(map[string]uint64),
+	}
+
+	if len(config.Detectors) == 0 {
+		r.detectors = append(r.detectors, builtinDetectors...)
+	}
+
+	for _, name := range config.Detectors {
+		found := false
+		for _, d := range builtinDetectors {
+			if d.name == name {
+				r.detectors = append(r.detectors, d)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: unknown redact detector %q", domain.ErrInvalidStage, name)
+		}
+	}
+
+	for _, custom := range config.Custom {
+		re, err := regexp.Compile(custom.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: redact pattern %s: %w", domain.ErrInvalidStage, custom.Name, err)
+		}
+
+		r.detectors = append(r.detectors, detector{name: custom.Name, re: re})
+	}
+
+	return r, nil
+}
+
+func (r *Redactor) Name() string {
+	return domain.STAGE_REDACT
+}
+
+// Process returns the event with the sensitive values replaced
+func (r *Redactor) Process(event domain.LogEvent) []domain.LogEvent {
+	event.Message = r.Redact(event.Message)
+
+	fields := make(map[string]interface{})
+	for key, value := range event.Metadata {
+		if text, ok := value.(string); ok {
+			if redacted := r.Redact(text); redacted != text {
+				fields[key] = redacted
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		event = withMetadata(event, fields)
+	}
+
+	return []domain.LogEvent{event}
+}
+
+// Redact replaces the values found by the detectors in the text
+func (r *Redactor) Redact(text string) string {
+	for _, d := range r.detectors {
+		text = r.apply(d, text)
+	}
+
+	return text
+}
+
+func (r *Redactor) apply(d detector, text string) string {
+	matches := d.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var (
+		sb       strings.Builder
+		last     int
+		redacted uint64
+	)
+
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+
+		spans := [][2]int{{0, end - start}}
+		if d.spans != nil {
+			spans = d.spans(text[start:end])
+		}
+
+		for _, span := range spans {
+			sb.WriteString(text[last : start+span[0]])
+			sb.WriteString(r.replacement(d.name, text[start+span[0]:start+span[1]]))
+			last = start + span[1]
+			redacted++
+		}
+	}
+
+	if redacted == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+
+	r.mu.Lock()
+	r.counts[d.name] += redacted
+	r.mu.Unlock()
+
+	return sb.String()
+}
+
+func (r *Redactor) replacement(name, value string) string {
+	switch r.mode {
+	case domain.REDACT_MODE_REMOVE:
+		return ""
+	case domain.REDACT_MODE_HASH:
+		sum := sha256.Sum256([]byte(value))
+		return "[" + name + ":" + hex.EncodeToString(sum[:])[:12] + "]"
+	}
+
+	return "[REDACTED:" + name + "]"
+}
+
+// Stats returns how many values each detector redacted
+func (r *Redactor) Stats() map[string]uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]uint64, len(r.counts)+1)
+
+	var total uint64
+	for name, count := range r.counts {
+		stats["redacted."+name] = count
+		total += count
+	}
+	stats["redacted"] = total
+
+	return stats
+}
+
+// cardNumbers returns the card numbers of a run of digit groups. A number starts and ends with a
+// group, the longest one passing the Luhn check is kept, so the digits following a card are not
+// taken as part of it
+func cardNumbers(run string) [][2]int {
+	// starts and ends hold the bounds of the groups split by the spaces and the dashes
+	var starts, ends []int
+	for i := 0; i < len(run); i++ {
+		if run[i] == ' ' || run[i] == '-' {
+			continue
+		}
+		if i == 0 || run[i-1] == ' ' || run[i-1] == '-' {
+			starts = append(starts, i)
+		}
+		if i == len(run)-1 || run[i+1] == ' ' || run[i+1] == '-' {
+			ends = append(ends, i+1)
+		}
+	}
+
+	var spans [][2]int
+	for first := 0; first < len(starts); first++ {
+		for last := len(ends) - 1; last >= first; last-- {
+			if luhn(run[starts[first]:ends[last]]) {
+				spans = append(spans, [2]int{starts[first], ends[last]})
+				first = last
+				break
+			}
+		}
+	}
+
+	return spans
+}
+
+// luhn validates the check digit of a card number, ignoring spaces and dashes
+func luhn(number string) bool {
+	var (
+		sum    int
+		digits int
+		double bool
+	)
+
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		digits++
+		double = !double
+	}
+
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
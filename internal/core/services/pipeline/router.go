@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/expr"
+	"sync/atomic"
+	"time"
+)
+
+const MetadataPipeline = domain.METADATA_PIPELINE
+
+// Router sends each event to the pipelines of the matching routes, tagging the outputs with the
+// name of the pipeline that produced them
+type Router struct {
+	routes    []route
+	fallback  []ports.Stage
+	pipelines []ports.Stage
+
+	received atomic.Uint64
+	unrouted atomic.Uint64
+	routed   map[string]*atomic.Uint64
+}
+
+type route struct {
+	name      string
+	condition *expr.Expression
+	pipelines []ports.Stage
+	next      bool
+}
+
+// NewRouter compiles the route conditions, returning an error for invalid conditions or unknown pipelines
+func NewRouter(config domain.RoutingConfig, pipelines ...ports.Stage) (*Router, error) {
+	byName := make(map[string]ports.Stage, len(pipelines))
+	for _, pipeline := range pipelines {
+		byName[pipeline.Name()] = pipeline
+	}
+
+	lookup := func(names []string) ([]ports.Stage, error) {
+		stages := make([]ports.Stage, 0, len(names))
+		for _, name := range names {
+			stage, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown pipeline %s", name)
+			}
+			stages = append(stages, stage)
+		}
+		return stages, nil
+	}
+
+	r := &Router{
+		pipelines: pipelines,
+		routed:    map[string]*atomic.Uint64{domain.DEFAULT_ROUTE: {}},
+	}
+
+	for i, routeConfig := range config.Routes {
+		rt := route{name: routeConfig.Name, next: routeConfig.Continue}
+		if rt.name == "" {
+			rt.name = fmt.Sprintf("route_%d", i)
+		}
+		if rt.name == domain.DEFAULT_ROUTE {
+			return nil, fmt.Errorf("%w: %s is the name of the default route", domain.ErrInvalidRoute, rt.name)
+		}
+
+		var err error
+		if rt.pipelines, err = lookup(routeConfig.Pipelines); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", domain.ErrInvalidRoute, rt.name, err)
+		}
+
+		if routeConfig.Condition != "" {
+			if rt.condition, err = expr.Compile(routeConfig.Condition); err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", domain.ErrInvalidRoute, rt.name, err)
+			}
+		}
+
+		r.routes = append(r.routes, rt)
+		r.routed[rt.name] = &atomic.Uint64{}
+	}
+
+	var err error
+	if r.fallback, err = lookup(config.Default); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", domain.ErrInvalidRoute, domain.DEFAULT_ROUTE, err)
+	}
+
+	if len(config.Routes) == 0 && len(config.Default) == 0 {
+		r.fallback = pipelines
+	}
+
+	return r, nil
+}
+
+func (r *Router) Name() string {
+	return "router"
+}
+
+// Process runs the event through each targeted pipeline once, unrouted events are dropped
+func (r *Router) Process(event domain.LogEvent) []domain.LogEvent {
+	r.received.Add(1)
+
+	targets := r.targets(event)
+	if len(targets) == 0 {
+		r.unrouted.Add(1)
+		return nil
+	}
+
+	var outputs []domain.LogEvent
+	for _, pipeline := range targets {
+		outputs = append(outputs, tagPipeline(pipeline.Process(event), pipeline.Name())...)
+	}
+
+	return outputs
+}
+
+// targets returns the pipelines of the matching routes, without duplicates
+func (r *Router) targets(event domain.LogEvent) []ports.Stage {
+	var (
+		targets []ports.Stage
+		seen    = make(map[string]bool)
+	)
+
+	add := func(pipelines []ports.Stage) {
+		for _, pipeline := range pipelines {
+			if !seen[pipeline.Name()] {
+				seen[pipeline.Name()] = true
+				targets = append(targets, pipeline)
+			}
+		}
+	}
+
+	matched := false
+	for _, rt := range r.routes {
+		if rt.condition != nil && !rt.condition.Match(event) {
+			continue
+		}
+
+		matched = true
+		r.routed[rt.name].Add(1)
+		add(rt.pipelines)
+
+		if !rt.next {
+			break
+		}
+	}
+
+	if !matched && len(r.fallback) > 0 {
+		r.routed[domain.DEFAULT_ROUTE].Add(1)
+		add(r.fallback)
+	}
+
+	return targets
+}
+
+// Flush releases the events held by the pipelines
+func (r *Router) Flush(now time.Time, final bool) []domain.LogEvent {
+	var events []domain.LogEvent
+
+	for _, pipeline := range r.pipelines {
+		if flusher, ok := pipeline.(ports.Flusher); ok {
+			events = append(events, tagPipeline(flusher.Flush(now, final), pipeline.Name())...)
+		}
+	}
+
+	return events
+}
+
+// Stats returns the route counters along with the counters of the pipelines prefixed by their name
+func (r *Router) Stats() map[string]uint64 {
+	stats := map[string]uint64{
+		"received": r.received.Load(),
+		"unrouted": r.unrouted.Load(),
+	}
+
+	for name, counter := range r.routed {
+		stats["routed."+name] = counter.Load()
+	}
+
+	for _, pipeline := range r.pipelines {
+		provider, ok := pipeline.(ports.StatsProvider)
+		if !ok {
+			continue
+		}
+
+		for key, value := range provider.Stats() {
+			stats[pipeline.Name()+"."+key] = value
+		}
+	}
+
+	return stats
+}
+
+func tagPipeline(events []domain.LogEvent, name string) []domain.LogEvent {
+	for i, event := range events {
+		events[i] = withMetadata(event, map[string]interface{}{MetadataPipeline: name})
+	}
+
+	return events
+}
@@ -0,0 +1,167 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/ports"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pipelineNames(events []domain.LogEvent) []string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, event.Metadata[pipeline.MetadataPipeline].(string))
+	}
+
+	return names
+}
+
+func TestRouter_Process(t *testing.T) {
+	pipelines := []ports.Stage{
+		pipeline.NewPipeline("payments"),
+		pipeline.NewPipeline("platform"),
+		pipeline.NewPipeline("archive"),
+	}
+
+	routing := domain.RoutingConfig{
+		Routes: []domain.RouteConfig{
+			{Name: "payments", Condition: `metadata.team == "payments" && severity >= ERROR`, Pipelines: []string{"payments"}, Continue: true},
+			{Name: "errors", Condition: `severity >= ERROR`, Pipelines: []string{"archive", "payments"}},
+			{Name: "platform", Condition: `metadata.team == "platform"`, Pipelines: []string{"platform"}},
+		},
+		Default: []string{"archive"},
+	}
+
+	tests := []struct {
+		name     string
+		event    domain.LogEvent
+		expected []string
+	}{
+		{
+			name:     "ShouldContinueToTheNextMatchingRoute",
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{"team": "payments"}},
+			expected: []string{"payments", "archive"},
+		},
+		{
+			name:     "ShouldStopAtTheFirstMatchingRoute",
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_FATAL, Metadata: map[string]interface{}{"team": "platform"}},
+			expected: []string{"archive", "payments"},
+		},
+		{
+			name:     "ShouldRouteByMetadata",
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_DEBUG, Metadata: map[string]interface{}{"team": "platform"}},
+			expected: []string{"platform"},
+		},
+		{
+			name:     "ShouldUseTheDefaultRoute",
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_INFO},
+			expected: []string{"archive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := pipeline.NewRouter(routing, pipelines...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, pipelineNames(router.Process(tt.event)))
+		})
+	}
+}
+
+func TestRouter_ShouldSendEverythingToEveryPipelineWithoutRoutes(t *testing.T) {
+	router, err := pipeline.NewRouter(domain.RoutingConfig{}, pipeline.NewPipeline("a"), pipeline.NewPipeline("b"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, pipelineNames(router.Process(domain.LogEvent{})))
+}
+
+func TestRouter_ShouldDropUnroutedEventsWithoutDefault(t *testing.T) {
+	router, err := pipeline.NewRouter(domain.RoutingConfig{
+		Routes: []domain.RouteConfig{{Condition: `source == "unix"`, Pipelines: []string{"a"}}},
+	}, pipeline.NewPipeline("a"))
+	require.NoError(t, err)
+
+	assert.Empty(t, router.Process(domain.LogEvent{Source: domain.SOURCE_FILE}))
+	assert.Equal(t, uint64(1), router.Stats()["unrouted"])
+}
+
+func TestRouter_Flush(t *testing.T) {
+	clock := newClock(time.Now())
+	dedup := pipeline.NewPipeline("dedup", pipeline.NewDedup(domain.DedupConfig{Window: time.Minute}, clock))
+
+	router, err := pipeline.NewRouter(domain.RoutingConfig{}, dedup)
+	require.NoError(t, err)
+
+	event := domain.LogEvent{Message: "retry"}
+	require.Len(t, router.Process(event), 1)
+	require.Empty(t, router.Process(event))
+
+	released := router.Flush(clock.Now(), true)
+
+	require.Len(t, released, 1)
+	assert.Equal(t, "dedup", released[0].Metadata[pipeline.MetadataPipeline])
+}
+
+func TestRouter_Stats(t *testing.T) {
+	filter, err := pipeline.NewFilter(domain.FilterConfig{MinSeverity: domain.LOG_LEVEL_ERROR})
+	require.NoError(t, err)
+
+	router, err := pipeline.NewRouter(domain.RoutingConfig{
+		Routes:  []domain.RouteConfig{{Condition: `source == "file"`, Pipelines: []string{"errors"}}},
+		Default: []string{"errors"},
+	}, pipeline.NewPipeline("errors", filter))
+	require.NoError(t, err)
+
+	router.Process(domain.LogEvent{Source: domain.SOURCE_FILE, Severity: domain.LOG_LEVEL_ERROR})
+	router.Process(domain.LogEvent{Source: domain.SOURCE_STDIN, Severity: domain.LOG_LEVEL_INFO})
+
+	assert.Equal(t, map[string]uint64{
+		"received":                           2,
+		"unrouted":                           0,
+		"routed.route_0":                     1,
+		"routed.default":                     1,
+		"errors.filter.received":             2,
+		"errors.filter.dropped":              1,
+		"errors.filter.dropped.severity":     1,
+		"errors.filter.dropped.not_included": 0,
+	}, router.Stats())
+	assert.Equal(t, "router", router.Name())
+}
+
+func TestNewRouter_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config domain.RoutingConfig
+	}{
+		{
+			name:   "invalid condition",
+			config: domain.RoutingConfig{Routes: []domain.RouteConfig{{Condition: "severity >", Pipelines: []string{"a"}}}},
+		},
+		{
+			name:   "unknown pipeline",
+			config: domain.RoutingConfig{Routes: []domain.RouteConfig{{Pipelines: []string{"b"}}}},
+		},
+		{
+			name:   "unknown default pipeline",
+			config: domain.RoutingConfig{Default: []string{"b"}},
+		},
+		{
+			name:   "route named default",
+			config: domain.RoutingConfig{Routes: []domain.RouteConfig{{Name: "default", Pipelines: []string{"a"}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := pipeline.NewRouter(tt.config, pipeline.NewPipeline("a"))
+
+			assert.ErrorIs(t, err, domain.ErrInvalidRoute)
+			assert.Nil(t, router)
+		})
+	}
+}
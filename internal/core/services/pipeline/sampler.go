@@ -0,0 +1,245 @@
+package pipeline
+
+import (
+	"log-guardian/internal/core/domain"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetadataSampleRate = "sample_rate"
+
+	defaultSampleWindow  = time.Minute
+	defaultSampleMaxKeys = 10000
+)
+
+// Sampler keeps a representative fraction of the events, tagging the kept ones with the
+// rate they were sampled at so the totals can be extrapolated
+type Sampler struct {
+	mode    string
+	rate    float64
+	size    int
+	window  time.Duration
+	fields  []string
+	maxKeys int
+	clock   domain.Clock
+	random  *rand.Rand
+
+	mu   sync.Mutex
+	keys map[string]*sampleKey
+
+	received atomic.Uint64
+	exempt   atomic.Uint64
+	kept     atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+type sampleKey struct {
+	windowStart time.Time
+	seen        int
+	previous    int
+	// closed is set once the key saw a whole window, previous is unknown before
+	closed    bool
+	reservoir []domain.LogEvent
+}
+
+func NewSampler(config domain.SampleConfig, clock domain.Clock, random *rand.Rand) *Sampler {
+	window := config.Window
+	if window == 0 {
+		window = defaultSampleWindow
+	}
+
+	maxKeys := config.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultSampleMaxKeys
+	}
+
+	return &Sampler{
+		mode:    config.Mode,
+		rate:    config.Rate,
+		size:    config.Size,
+		window:  window,
+		fields:  config.Fields,
+		maxKeys: maxKeys,
+		clock:   clock,
+		random:  random,
+		keys:    make(map[string]*sampleKey),
+	}
+}
+
+func (s *Sampler) Name() string {
+	return domain.STAGE_SAMPLE
+}
+
+// Process returns the event when it is kept, the errors are always kept untouched
+func (s *Sampler) Process(event domain.LogEvent) []domain.LogEvent {
+	s.received.Add(1)
+
+	if event.Severity.Rank() >= domain.LOG_LEVEL_ERROR.Rank() {
+		s.exempt.Add(1)
+		return []domain.LogEvent{event}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []domain.LogEvent
+	switch s.mode {
+	case domain.SAMPLE_MODE_FIXED:
+		result = s.fixed(event)
+	case domain.SAMPLE_MODE_RESERVOIR:
+		result = s.reservoir(event)
+	case domain.SAMPLE_MODE_FIRST_N:
+		result = s.firstN(event)
+	}
+
+	return result
+}
+
+func (s *Sampler) fixed(event domain.LogEvent) []domain.LogEvent {
+	if s.random.Float64() >= s.rate {
+		s.dropped.Add(1)
+		return nil
+	}
+
+	s.kept.Add(1)
+	return []domain.LogEvent{withSampleRate(event, s.rate)}
+}
+
+// reservoir holds the event using reservoir sampling, releasing the closed window of the key
+func (s *Sampler) reservoir(event domain.LogEvent) []domain.LogEvent {
+	key, released, ok := s.key(event)
+	if !ok {
+		return append(released, event)
+	}
+
+	key.seen++
+	if len(key.reservoir) < s.size {
+		key.reservoir = append(key.reservoir, event)
+		return released
+	}
+
+	if i := s.random.IntN(key.seen); i < s.size {
+		key.reservoir[i] = event
+	}
+	s.dropped.Add(1)
+
+	return released
+}
+
+// firstN keeps the first events of the window, the rate is estimated from the previous window and
+// left out until the key saw a whole window
+func (s *Sampler) firstN(event domain.LogEvent) []domain.LogEvent {
+	key, _, ok := s.key(event)
+	if !ok {
+		return []domain.LogEvent{event}
+	}
+
+	key.seen++
+	if key.seen > s.size {
+		s.dropped.Add(1)
+		return nil
+	}
+
+	s.kept.Add(1)
+	if !key.closed {
+		return []domain.LogEvent{event}
+	}
+
+	rate := 1.0
+	if key.previous > s.size {
+		rate = float64(s.size) / float64(key.previous)
+	}
+
+	return []domain.LogEvent{withSampleRate(event, rate)}
+}
+
+// key returns the state of the event key rolling its window over when it is closed, along with
+// the events released by the closed window. It returns false when too many keys are tracked
+func (s *Sampler) key(event domain.LogEvent) (*sampleKey, []domain.LogEvent, bool) {
+	now := s.clock.Now()
+	fingerprint := event.Fingerprint(s.fields...)
+
+	key, ok := s.keys[fingerprint]
+	if !ok {
+		if len(s.keys) >= s.maxKeys {
+			s.kept.Add(1)
+			return nil, nil, false
+		}
+
+		key = &sampleKey{windowStart: now}
+		s.keys[fingerprint] = key
+	}
+
+	var released []domain.LogEvent
+	if now.Sub(key.windowStart) >= s.window {
+		released = s.roll(key, now)
+	}
+
+	return key, released, true
+}
+
+// roll starts a new window for the key and returns the reservoir of the closed one
+func (s *Sampler) roll(key *sampleKey, now time.Time) []domain.LogEvent {
+	released := make([]domain.LogEvent, 0, len(key.reservoir))
+	for _, event := range key.reservoir {
+		released = append(released, withSampleRate(event, float64(len(key.reservoir))/float64(key.seen)))
+	}
+	s.kept.Add(uint64(len(released)))
+
+	key.previous = key.seen
+	key.closed = true
+	if now.Sub(key.windowStart) >= 2*s.window {
+		// the key was idle during the whole previous window, it starts over
+		key.previous = 0
+		key.closed = false
+	}
+
+	key.windowStart = now
+	key.seen = 0
+	key.reservoir = nil
+
+	return released
+}
+
+// Flush releases the reservoirs of the closed windows and forgets the idle keys
+func (s *Sampler) Flush(now time.Time, final bool) []domain.LogEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var released []domain.LogEvent
+	for fingerprint, key := range s.keys {
+		if !final && now.Sub(key.windowStart) < s.window {
+			continue
+		}
+
+		idle := key.seen == 0
+		released = append(released, s.roll(key, now)...)
+
+		if final || idle {
+			delete(s.keys, fingerprint)
+		}
+	}
+
+	sort.Slice(released, func(i, j int) bool {
+		return released[i].Timestamp.Before(released[j].Timestamp)
+	})
+
+	return released
+}
+
+func (s *Sampler) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"received": s.received.Load(),
+		"exempt":   s.exempt.Load(),
+		"kept":     s.kept.Load(),
+		"dropped":  s.dropped.Load(),
+	}
+}
+
+func withSampleRate(event domain.LogEvent, rate float64) domain.LogEvent {
+	return withMetadata(event, map[string]interface{}{MetadataSampleRate: rate})
+}
@@ -0,0 +1,177 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandom() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestSampler_Fixed(t *testing.T) {
+	sampler := pipeline.NewSampler(domain.SampleConfig{Mode: domain.SAMPLE_MODE_FIXED, Rate: 0.25}, newClock(time.Now()), newRandom())
+
+	kept := 0
+	for range 4000 {
+		for _, event := range sampler.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Message: "chatty"}) {
+			assert.Equal(t, 0.25, event.Metadata[pipeline.MetadataSampleRate])
+			kept++
+		}
+	}
+
+	assert.InDelta(t, 1000, kept, 100)
+
+	stats := sampler.Stats()
+	assert.Equal(t, uint64(4000), stats["received"])
+	assert.Equal(t, uint64(kept), stats["kept"])
+	assert.Equal(t, uint64(4000-kept), stats["dropped"])
+}
+
+func TestSampler_ErrorsAreExempt(t *testing.T) {
+	modes := []domain.SampleConfig{
+		{Mode: domain.SAMPLE_MODE_FIXED, Rate: 0.0001},
+		{Mode: domain.SAMPLE_MODE_RESERVOIR, Size: 1},
+		{Mode: domain.SAMPLE_MODE_FIRST_N, Size: 1},
+	}
+
+	for _, config := range modes {
+		t.Run(config.Mode, func(t *testing.T) {
+			sampler := pipeline.NewSampler(config, newClock(time.Now()), newRandom())
+
+			for _, level := range []domain.LogLevel{domain.LOG_LEVEL_ERROR, domain.LOG_LEVEL_FATAL} {
+				for range 10 {
+					event := domain.LogEvent{Severity: level, Message: "boom"}
+					assert.Equal(t, []domain.LogEvent{event}, sampler.Process(event))
+				}
+			}
+
+			assert.Equal(t, uint64(20), sampler.Stats()["exempt"])
+		})
+	}
+}
+
+func TestSampler_Reservoir(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("ShouldReleaseTheReservoirWhenTheWindowCloses", func(t *testing.T) {
+		clock := newClock(start)
+		sampler := pipeline.NewSampler(domain.SampleConfig{
+			Mode:   domain.SAMPLE_MODE_RESERVOIR,
+			Size:   3,
+			Window: time.Minute,
+		}, clock, newRandom())
+
+		for i := range 12 {
+			assert.Empty(t, sampler.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Message: "request done", ID: string(rune('a' + i))}))
+		}
+		sampler.Process(domain.LogEvent{Severity: domain.LOG_LEVEL_DEBUG, Message: "other"})
+
+		assert.Empty(t, sampler.Flush(start.Add(30*time.Second), false))
+
+		released := sampler.Flush(start.Add(time.Minute), false)
+		require.Len(t, released, 4)
+
+		rates := map[string]float64{}
+		for _, event := range released {
+			rates[event.Message] = event.Metadata[pipeline.MetadataSampleRate].(float64)
+		}
+		assert.Equal(t, map[string]float64{"request done": 0.25, "other": 1}, rates)
+
+		stats := sampler.Stats()
+		assert.Equal(t, uint64(4), stats["kept"])
+		assert.Equal(t, uint64(9), stats["dropped"])
+	})
+
+	t.Run("ShouldReleaseTheClosedWindowOnTheNextEvent", func(t *testing.T) {
+		clock := newClock(start)
+		sampler := pipeline.NewSampler(domain.SampleConfig{
+			Mode:   domain.SAMPLE_MODE_RESERVOIR,
+			Size:   1,
+			Window: time.Minute,
+		}, clock, newRandom())
+
+		sampler.Process(domain.LogEvent{ID: "first", Message: "tick"})
+
+		clock.Advance(time.Minute)
+		released := sampler.Process(domain.LogEvent{ID: "second", Message: "tick"})
+
+		require.Len(t, released, 1)
+		assert.Equal(t, "first", released[0].ID)
+
+		// the second one is held by the new window until the final flush
+		final := sampler.Flush(clock.Now(), true)
+		require.Len(t, final, 1)
+		assert.Equal(t, "second", final[0].ID)
+	})
+}
+
+func TestSampler_FirstN(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := newClock(start)
+	sampler := pipeline.NewSampler(domain.SampleConfig{
+		Mode:   domain.SAMPLE_MODE_FIRST_N,
+		Size:   2,
+		Window: time.Minute,
+		Fields: []string{"pod"},
+	}, clock, newRandom())
+
+	event := func(pod string) domain.LogEvent {
+		return domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Message: "GET /", Metadata: map[string]interface{}{"pod": pod}}
+	}
+
+	kept := func(n int, pod string) int {
+		count := 0
+		for range n {
+			count += len(sampler.Process(event(pod)))
+		}
+		return count
+	}
+
+	// without a previous window the rate is unknown
+	result := sampler.Process(event("a"))
+	require.Len(t, result, 1)
+	assert.NotContains(t, result[0].Metadata, pipeline.MetadataSampleRate)
+
+	assert.Equal(t, 1, kept(9, "a"))
+	assert.Equal(t, 2, kept(10, "b"))
+
+	clock.Advance(30 * time.Second)
+	assert.Empty(t, sampler.Process(event("a")))
+
+	// the new window estimates the rate from the 11 events of the previous one
+	clock.Advance(30 * time.Second)
+	result = sampler.Process(event("a"))
+	require.Len(t, result, 1)
+	assert.InDelta(t, 2.0/11.0, result[0].Metadata[pipeline.MetadataSampleRate], 0.0001)
+
+	// pod b was idle in the last window, so it starts over without a rate
+	clock.Advance(2 * time.Minute)
+	sampler.Flush(clock.Now(), false)
+	sampler.Flush(clock.Now().Add(time.Minute), false)
+
+	result = sampler.Process(event("b"))
+	require.Len(t, result, 1)
+	assert.NotContains(t, result[0].Metadata, pipeline.MetadataSampleRate)
+}
+
+func TestSampler_MaxKeys(t *testing.T) {
+	sampler := pipeline.NewSampler(domain.SampleConfig{
+		Mode:    domain.SAMPLE_MODE_FIRST_N,
+		Size:    1,
+		MaxKeys: 1,
+	}, newClock(time.Now()), newRandom())
+
+	sampler.Process(domain.LogEvent{Message: "first"})
+
+	// the keys over the limit are not sampled
+	for range 3 {
+		assert.Len(t, sampler.Process(domain.LogEvent{Message: "second"}), 1)
+	}
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/drain"
+	"sync/atomic"
+)
+
+// statsTemplates bounds the templates reported in the stats, the most frequent ones
+const statsTemplates = 10
+
+const (
+	MetadataTemplateID  = domain.METADATA_TEMPLATE_ID
+	MetadataTemplate    = "template"
+	MetadataNewTemplate = "template_new"
+	// MetadataTemplateCount is the number of messages of the template, this one included
+	MetadataTemplateCount = "template_count"
+	// MetadataTemplateWindowCount and MetadataTemplatePreviousCount are the number of messages of
+	// the template in the current window, this one included, and in the previous window
+	MetadataTemplateWindowCount   = "template_window_count"
+	MetadataTemplatePreviousCount = "template_previous_count"
+)
+
+// TemplateMiner tags each event with the template of its message, flagging the templates never
+// seen before
+type TemplateMiner struct {
+	miner *drain.Miner
+	clock domain.Clock
+
+	received     atomic.Uint64
+	newTemplates atomic.Uint64
+}
+
+func NewTemplateMiner(config domain.TemplateConfig, clock domain.Clock) *TemplateMiner {
+	return &TemplateMiner{
+		miner: drain.NewMiner(config),
+		clock: clock,
+	}
+}
+
+func (t *TemplateMiner) Name() string {
+	return domain.STAGE_TEMPLATES
+}
+
+// Process adds template_id, template and the template_count, template_window_count and
+// template_previous_count to the metadata, along with template_new on the first occurrence of a template
+func (t *TemplateMiner) Process(event domain.LogEvent) []domain.LogEvent {
+	t.received.Add(1)
+
+	match := t.miner.Add(event.Message, t.clock.Now())
+
+	fields := map[string]interface{}{
+		MetadataTemplateID:            match.ID,
+		MetadataTemplate:              match.Pattern,
+		MetadataTemplateCount:         match.Count,
+		MetadataTemplateWindowCount:   match.WindowCount,
+		MetadataTemplatePreviousCount: match.PreviousCount,
+	}
+
+	if match.New {
+		t.newTemplates.Add(1)
+		fields[MetadataNewTemplate] = true
+	}
+
+	return []domain.LogEvent{withMetadata(event, fields)}
+}
+
+// Stats reports the counts of the most frequent templates along with the totals, as
+// template.<id>.count, template.<id>.window_count and template.<id>.previous_count
+func (t *TemplateMiner) Stats() map[string]uint64 {
+	templates := t.miner.Templates(t.clock.Now())
+
+	stats := map[string]uint64{
+		"received":  t.received.Load(),
+		"new":       t.newTemplates.Load(),
+		"templates": uint64(len(templates)),
+	}
+
+	for _, template := range templates[:min(len(templates), statsTemplates)] {
+		prefix := "template." + template.ID + "."
+		stats[prefix+"count"] = template.Count
+		stats[prefix+"window_count"] = template.WindowCount
+		stats[prefix+"previous_count"] = template.PreviousCount
+	}
+
+	return stats
+}
@@ -0,0 +1,49 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateMiner_Process(t *testing.T) {
+	clock := newClock(time.Now())
+	miner := pipeline.NewTemplateMiner(domain.TemplateConfig{Window: time.Minute}, clock)
+
+	first := miner.Process(domain.LogEvent{Message: "payment 42 declined", Metadata: map[string]interface{}{"team": "payments"}})
+	second := miner.Process(domain.LogEvent{Message: "payment 43 declined"})
+
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+
+	assert.Equal(t, "payment <num> declined", first[0].Metadata[pipeline.MetadataTemplate])
+	assert.Equal(t, true, first[0].Metadata[pipeline.MetadataNewTemplate])
+	assert.Equal(t, "payments", first[0].Metadata["team"])
+
+	assert.Equal(t, first[0].Metadata[pipeline.MetadataTemplateID], second[0].Metadata[pipeline.MetadataTemplateID])
+	assert.NotContains(t, second[0].Metadata, pipeline.MetadataNewTemplate)
+	assert.Equal(t, uint64(1), first[0].Metadata[pipeline.MetadataTemplateCount])
+	assert.Equal(t, uint64(2), second[0].Metadata[pipeline.MetadataTemplateCount])
+	assert.Equal(t, uint64(2), second[0].Metadata[pipeline.MetadataTemplateWindowCount])
+	assert.Equal(t, uint64(0), second[0].Metadata[pipeline.MetadataTemplatePreviousCount])
+
+	id := first[0].Metadata[pipeline.MetadataTemplateID].(string)
+	assert.Equal(t, map[string]uint64{
+		"received":                           2,
+		"new":                                1,
+		"templates":                          1,
+		"template." + id + ".count":          2,
+		"template." + id + ".window_count":   2,
+		"template." + id + ".previous_count": 0,
+	}, miner.Stats())
+
+	clock.Advance(time.Minute)
+	stats := miner.Stats()
+	assert.Equal(t, uint64(0), stats["template."+id+".window_count"], "the counts roll over when read")
+	assert.Equal(t, uint64(2), stats["template."+id+".previous_count"])
+	assert.Equal(t, domain.STAGE_TEMPLATES, miner.Name())
+}
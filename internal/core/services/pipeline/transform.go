@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"log-guardian/internal/core/domain"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Transform reshapes the events with an ordered list of rules
+type Transform struct {
+	rules []transformRule
+
+	received atomic.Uint64
+	applied  atomic.Uint64
+	skipped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+type transformRule struct {
+	domain.TransformRule
+	when     *filterRule
+	template *template.Template
+}
+
+// NewTransform compiles the rule conditions and templates
+func NewTransform(config domain.TransformConfig) (*Transform, error) {
+	t := &Transform{rules: make([]transformRule, 0, len(config.Rules))}
+
+	for i, rule := range config.Rules {
+		compiled := transformRule{TransformRule: rule}
+
+		if rule.When != nil {
+			when, err := compileFilterRules([]domain.FilterRule{*rule.When}, fmt.Sprintf("transform_%d", i))
+			if err != nil {
+				return nil, err
+			}
+			compiled.when = &when[0]
+		}
+
+		if rule.Op == domain.TRANSFORM_OP_TEMPLATE {
+			tmpl, err := template.New(rule.Field).Option("missingkey=zero").Parse(rule.Template)
+			if err != nil {
+				return nil, fmt.Errorf("%w: transform rule %d: %w", domain.ErrInvalidStage, i, err)
+			}
+			compiled.template = tmpl
+		}
+
+		t.rules = append(t.rules, compiled)
+	}
+
+	return t, nil
+}
+
+func (t *Transform) Name() string {
+	return domain.STAGE_TRANSFORM
+}
+
+// Process applies the matching rules in order. A failing rule leaves the event as it was before the
+// rule and the next rules still run
+func (t *Transform) Process(event domain.LogEvent) []domain.LogEvent {
+	t.received.Add(1)
+
+	for _, rule := range t.rules {
+		if rule.when != nil && !rule.when.matches(event) {
+			t.skipped.Add(1)
+			continue
+		}
+
+		transformed, applied, err := rule.apply(event)
+		switch {
+		case err != nil:
+			t.failed.Add(1)
+		case !applied:
+			t.skipped.Add(1)
+		default:
+			t.applied.Add(1)
+			event = transformed
+		}
+	}
+
+	return []domain.LogEvent{event}
+}
+
+// Stats returns how many rules were applied, skipped because the condition or the source field
+// was missing, and failed
+func (t *Transform) Stats() map[string]uint64 {
+	return map[string]uint64{
+		"received": t.received.Load(),
+		"applied":  t.applied.Load(),
+		"skipped":  t.skipped.Load(),
+		"failed":   t.failed.Load(),
+	}
+}
+
+// apply returns the transformed event and whether the rule had anything to do
+func (r transformRule) apply(event domain.LogEvent) (domain.LogEvent, bool, error) {
+	switch r.Op {
+	case domain.TRANSFORM_OP_SET:
+		return event, true, event.SetField(r.Field, r.Value)
+	case domain.TRANSFORM_OP_DELETE:
+		if _, ok := event.Field(r.Field); !ok {
+			return event, false, nil
+		}
+		return event, true, event.DeleteField(r.Field)
+	case domain.TRANSFORM_OP_RENAME, domain.TRANSFORM_OP_COPY:
+		value, ok := event.Field(r.From)
+		if !ok {
+			return event, false, nil
+		}
+		if err := event.SetField(r.Field, value); err != nil {
+			return event, false, err
+		}
+		if r.Op == domain.TRANSFORM_OP_RENAME && r.From != r.Field {
+			return event, true, event.DeleteField(r.From)
+		}
+		return event, true, nil
+	case domain.TRANSFORM_OP_TEMPLATE:
+		var buffer bytes.Buffer
+		if err := r.template.Execute(&buffer, templateData(event)); err != nil {
+			return event, false, err
+		}
+		return event, true, event.SetField(r.Field, buffer.String())
+	case domain.TRANSFORM_OP_CAST:
+		value, ok := event.Field(r.Field)
+		if !ok {
+			return event, false, nil
+		}
+		cast, err := castValue(value, r.Type)
+		if err != nil {
+			return event, false, err
+		}
+		return event, true, event.SetField(r.Field, cast)
+	case domain.TRANSFORM_OP_LOWERCASE, domain.TRANSFORM_OP_UPPERCASE:
+		value, ok := event.Field(r.Field)
+		if !ok {
+			return event, false, nil
+		}
+		text := strings.ToLower(fmt.Sprint(value))
+		if r.Op == domain.TRANSFORM_OP_UPPERCASE {
+			text = strings.ToUpper(fmt.Sprint(value))
+		}
+		return event, true, event.SetField(r.Field, text)
+	}
+
+	return event, false, fmt.Errorf("%w: unknown transform op %q", domain.ErrInvalidStage, r.Op)
+}
+
+func templateData(event domain.LogEvent) map[string]interface{} {
+	return map[string]interface{}{
+		domain.FIELD_ID:        event.ID,
+		domain.FIELD_TIMESTAMP: event.Timestamp.Format(time.RFC3339Nano),
+		domain.FIELD_SOURCE:    event.Source,
+		domain.FIELD_SEVERITY:  string(event.Severity),
+		domain.FIELD_MESSAGE:   event.Message,
+		"metadata":             event.Metadata,
+	}
+}
+
+// castValue converts the value to the type, returning an error when it can't be represented
+func castValue(value interface{}, kind string) (interface{}, error) {
+	switch kind {
+	case domain.CAST_TYPE_INT:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			return int(v), nil
+		}
+		return strconv.Atoi(strings.TrimSpace(fmt.Sprint(value)))
+	case domain.CAST_TYPE_FLOAT:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		}
+		return strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+	case domain.CAST_TYPE_BOOL:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+		return strconv.ParseBool(strings.ToLower(strings.TrimSpace(fmt.Sprint(value))))
+	case domain.CAST_TYPE_STRING:
+		return fmt.Sprint(value), nil
+	}
+
+	return nil, fmt.Errorf("unknown cast type %q", kind)
+}
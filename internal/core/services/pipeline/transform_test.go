@@ -0,0 +1,149 @@
+package pipeline_test
+
+import (
+	"log-guardian/internal/core/domain"
+	"log-guardian/internal/core/services/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_Process(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []domain.TransformRule
+		event    domain.LogEvent
+		expected domain.LogEvent
+	}{
+		{
+			name:     "ShouldSetMetadata",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_SET, Field: "metadata.team", Value: "payments"}},
+			event:    domain.LogEvent{Message: "hello"},
+			expected: domain.LogEvent{Message: "hello", Metadata: map[string]interface{}{"team": "payments"}},
+		},
+		{
+			name:     "ShouldRenameToSeverity",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_RENAME, Field: "severity", From: "metadata.lvl"}},
+			event:    domain.LogEvent{Severity: domain.LOG_LEVEL_INFO, Metadata: map[string]interface{}{"lvl": "err"}},
+			expected: domain.LogEvent{Severity: domain.LOG_LEVEL_ERROR, Metadata: map[string]interface{}{}},
+		},
+		{
+			name:     "ShouldDeleteMetadata",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_DELETE, Field: "metadata.payload"}},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"payload": "big", "keep": 1}},
+			expected: domain.LogEvent{Metadata: map[string]interface{}{"keep": 1}},
+		},
+		{
+			name:     "ShouldCopyEventField",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_COPY, Field: "metadata.origin", From: "source"}},
+			event:    domain.LogEvent{Source: domain.SOURCE_FILE},
+			expected: domain.LogEvent{Source: domain.SOURCE_FILE, Metadata: map[string]interface{}{"origin": domain.SOURCE_FILE}},
+		},
+		{
+			name: "ShouldRenderTemplate",
+			rules: []domain.TransformRule{{
+				Op:       domain.TRANSFORM_OP_TEMPLATE,
+				Field:    "message",
+				Template: "[{{.metadata.service}}] {{.message}}",
+			}},
+			event:    domain.LogEvent{Message: "boom", Metadata: map[string]interface{}{"service": "api"}},
+			expected: domain.LogEvent{Message: "[api] boom", Metadata: map[string]interface{}{"service": "api"}},
+		},
+		{
+			name:     "ShouldCastToInt",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_CAST, Field: "metadata.status", Type: domain.CAST_TYPE_INT}},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"status": "503"}},
+			expected: domain.LogEvent{Metadata: map[string]interface{}{"status": 503}},
+		},
+		{
+			name:     "ShouldLowercase",
+			rules:    []domain.TransformRule{{Op: domain.TRANSFORM_OP_LOWERCASE, Field: "metadata.env"}},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"env": "PROD"}},
+			expected: domain.LogEvent{Metadata: map[string]interface{}{"env": "prod"}},
+		},
+		{
+			name: "ShouldApplyOnlyWhenConditionMatches",
+			rules: []domain.TransformRule{
+				{
+					When:  &domain.FilterRule{Metadata: []domain.MetadataMatch{{Key: "namespace", Pattern: "^payments$"}}},
+					Op:    domain.TRANSFORM_OP_SET,
+					Field: "metadata.team",
+					Value: "payments",
+				},
+			},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"namespace": "search"}},
+			expected: domain.LogEvent{Metadata: map[string]interface{}{"namespace": "search"}},
+		},
+		{
+			name: "ShouldApplyRulesInOrder",
+			rules: []domain.TransformRule{
+				{Op: domain.TRANSFORM_OP_COPY, Field: "metadata.raw", From: "message"},
+				{Op: domain.TRANSFORM_OP_UPPERCASE, Field: "message"},
+			},
+			event:    domain.LogEvent{Message: "boom"},
+			expected: domain.LogEvent{Message: "BOOM", Metadata: map[string]interface{}{"raw": "boom"}},
+		},
+		{
+			name: "ShouldKeepEventWhenRuleFails",
+			rules: []domain.TransformRule{
+				{Op: domain.TRANSFORM_OP_CAST, Field: "metadata.status", Type: domain.CAST_TYPE_INT},
+				{Op: domain.TRANSFORM_OP_SET, Field: "metadata.checked", Value: true},
+			},
+			event:    domain.LogEvent{Metadata: map[string]interface{}{"status": "n/a"}},
+			expected: domain.LogEvent{Metadata: map[string]interface{}{"status": "n/a", "checked": true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transform, err := pipeline.NewTransform(domain.TransformConfig{Rules: tt.rules})
+			require.NoError(t, err)
+
+			result := transform.Process(tt.event)
+
+			require.Len(t, result, 1)
+			assert.Equal(t, tt.expected, result[0])
+		})
+	}
+}
+
+func TestTransform_ShouldNotModifyInputMetadata(t *testing.T) {
+	transform, err := pipeline.NewTransform(domain.TransformConfig{Rules: []domain.TransformRule{
+		{Op: domain.TRANSFORM_OP_DELETE, Field: "metadata.payload"},
+	}})
+	require.NoError(t, err)
+
+	metadata := map[string]interface{}{"payload": "big"}
+	transform.Process(domain.LogEvent{Timestamp: time.Now(), Metadata: metadata})
+
+	assert.Contains(t, metadata, "payload")
+}
+
+func TestTransform_Stats(t *testing.T) {
+	transform, err := pipeline.NewTransform(domain.TransformConfig{Rules: []domain.TransformRule{
+		{When: &domain.FilterRule{Source: domain.SOURCE_FILE}, Op: domain.TRANSFORM_OP_SET, Field: "metadata.file", Value: true},
+		{Op: domain.TRANSFORM_OP_CAST, Field: "metadata.status", Type: domain.CAST_TYPE_INT},
+		{Op: domain.TRANSFORM_OP_SET, Field: "severity", Value: "loud"},
+	}})
+	require.NoError(t, err)
+
+	transform.Process(domain.LogEvent{Source: domain.SOURCE_FILE})
+	transform.Process(domain.LogEvent{Source: domain.SOURCE_STDIN, Metadata: map[string]interface{}{"status": "200"}})
+
+	assert.Equal(t, map[string]uint64{
+		"received": 2,
+		"applied":  2,
+		"skipped":  2,
+		"failed":   2,
+	}, transform.Stats())
+}
+
+func TestNewTransform_InvalidTemplate(t *testing.T) {
+	_, err := pipeline.NewTransform(domain.TransformConfig{Rules: []domain.TransformRule{
+		{Op: domain.TRANSFORM_OP_TEMPLATE, Field: "message", Template: "{{.message"},
+	}})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidStage)
+}